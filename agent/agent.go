@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"runtime"
+	"strconv"
 	"sync"
 	"time"
 
@@ -19,6 +20,8 @@ import (
 // Agent runs a set of plugins.
 type Agent struct {
 	Config *config.Config
+
+	persister *Persister
 }
 
 // NewAgent returns an Agent for the given Config.
@@ -46,6 +49,14 @@ func (a *Agent) Run(ctx context.Context) error {
 		return err
 	}
 
+	if a.Config.Agent.Statefile != "" {
+		log.Printf("D! [agent] Restoring plugin states from %q", a.Config.Agent.Statefile)
+		err = a.initPersister()
+		if err != nil {
+			return err
+		}
+	}
+
 	log.Printf("D! [agent] Connecting outputs")
 	err = a.connectOutputs(ctx)
 	if err != nil {
@@ -135,11 +146,26 @@ func (a *Agent) Run(ctx context.Context) error {
 		}
 	}(src)
 
+	if a.persister != nil && a.Config.Agent.StatefileSaveInterval.Duration > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.storeStateLoop(ctx, a.Config.Agent.StatefileSaveInterval.Duration)
+		}()
+	}
+
 	wg.Wait()
 
 	log.Printf("D! [agent] Closing outputs")
 	a.closeOutputs()
 
+	if a.persister != nil {
+		log.Printf("D! [agent] Saving plugin states to %q", a.Config.Agent.Statefile)
+		if err := a.persister.Store(); err != nil {
+			log.Printf("E! [agent] Error saving plugin states: %v", err)
+		}
+	}
+
 	log.Printf("D! [agent] Stopped Successfully")
 	return nil
 }
@@ -511,12 +537,13 @@ func (a *Agent) runOutputs(
 		}(output)
 	}
 
+	routes := a.outputRoutes()
 	for metric := range src {
-		for i, output := range a.Config.Outputs {
-			if i == len(a.Config.Outputs)-1 {
-				output.AddMetric(metric)
+		for i, route := range routes {
+			if i == len(routes)-1 {
+				route.AddMetric(metric)
 			} else {
-				output.AddMetric(metric.Copy())
+				route.AddMetric(metric.Copy())
 			}
 		}
 	}
@@ -528,6 +555,29 @@ func (a *Agent) runOutputs(
 	return nil
 }
 
+type metricRoute interface {
+	AddMetric(telegraf.Metric)
+}
+
+// outputRoutes returns the destinations of every metric: the failover groups
+// and the outputs not belonging to a group.
+func (a *Agent) outputRoutes() []metricRoute {
+	grouped := make(map[*models.RunningOutput]bool)
+	routes := make([]metricRoute, 0, len(a.Config.Outputs))
+	for _, group := range a.Config.FailoverGroups {
+		for _, output := range group.Outputs {
+			grouped[output] = true
+		}
+		routes = append(routes, group)
+	}
+	for _, output := range a.Config.Outputs {
+		if !grouped[output] {
+			routes = append(routes, output)
+		}
+	}
+	return routes
+}
+
 // flushLoop runs an output's flush function periodically until the context is
 // done.
 func (a *Agent) flushLoop(
@@ -550,14 +600,14 @@ func (a *Agent) flushLoop(
 		// Favor shutdown over other methods.
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.flushOnce(output, ticker, output.WriteFinal))
 			return
 		default:
 		}
 
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.flushOnce(output, ticker, output.WriteFinal))
 			return
 		case <-ticker.Elapsed():
 			logError(a.flushOnce(output, ticker, output.Write))
@@ -634,6 +684,72 @@ func (a *Agent) initPlugins() error {
 	return nil
 }
 
+// initPersister registers the plugins implementing telegraf.StatefulPlugin
+// and restores their state.  Plugins are identified by their type, name and
+// alias; instances sharing these are numbered in the order of the
+// configuration.
+func (a *Agent) initPersister() error {
+	a.persister = NewPersister(a.Config.Agent.Statefile)
+
+	counts := make(map[string]int)
+	register := func(kind, name, alias string, plugin interface{}) error {
+		sp, ok := plugin.(telegraf.StatefulPlugin)
+		if !ok {
+			return nil
+		}
+		id := kind + "." + name
+		if alias != "" {
+			id += "::" + alias
+		}
+		counts[id]++
+		if n := counts[id]; n > 1 {
+			id += "#" + strconv.Itoa(n)
+		}
+		return a.persister.Register(id, sp)
+	}
+
+	for _, input := range a.Config.Inputs {
+		if err := register("inputs", input.Config.Name, input.Config.Alias, input.Input); err != nil {
+			return err
+		}
+	}
+	for _, processor := range a.Config.Processors {
+		if err := register("processors", processor.Config.Name, processor.Config.Alias, processor.Processor); err != nil {
+			return err
+		}
+	}
+	for _, aggregator := range a.Config.Aggregators {
+		if err := register("aggregators", aggregator.Config.Name, aggregator.Config.Alias, aggregator.Aggregator); err != nil {
+			return err
+		}
+	}
+	for _, output := range a.Config.Outputs {
+		if err := register("outputs", output.Config.Name, output.Config.Alias, output.Output); err != nil {
+			return err
+		}
+	}
+
+	return a.persister.Load()
+}
+
+// storeStateLoop saves the plugin states on every interval until the context
+// is done.
+func (a *Agent) storeStateLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.persister.Store(); err != nil {
+				log.Printf("E! [agent] Error saving plugin states: %v", err)
+			}
+		}
+	}
+}
+
 // stopAggregators runs the Stop function on Aggregator plugins.
 func (a *Agent) stopAggregators() {
 	for _, aggregator := range a.Config.Aggregators {
@@ -166,3 +166,15 @@ func TestWindow(t *testing.T) {
 		})
 	}
 }
+
+func TestAgent_OutputRoutes(t *testing.T) {
+	c := config.NewConfig()
+	err := c.LoadConfig("../config/testdata/failover_group.toml")
+	require.NoError(t, err)
+	a, _ := NewAgent(c)
+
+	routes := a.outputRoutes()
+	require.Len(t, routes, 2)
+	require.Equal(t, c.FailoverGroups[0], routes[0])
+	require.Equal(t, c.Outputs[2], routes[1])
+}
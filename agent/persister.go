@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Persister saves the state of stateful plugins to a file and restores it on
+// the next run.
+type Persister struct {
+	Filename string
+
+	mu       sync.Mutex
+	ids      []string
+	register map[string]telegraf.StatefulPlugin
+}
+
+// NewPersister returns a Persister using the given state file.
+func NewPersister(filename string) *Persister {
+	return &Persister{
+		Filename: filename,
+		register: make(map[string]telegraf.StatefulPlugin),
+	}
+}
+
+// Register adds a plugin whose state is persisted under the id.
+func (p *Persister) Register(id string, plugin telegraf.StatefulPlugin) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.register[id]; ok {
+		return fmt.Errorf("duplicate plugin id %q", id)
+	}
+	p.ids = append(p.ids, id)
+	p.register[id] = plugin
+	return nil
+}
+
+// Load reads the state file and restores the state of the registered plugins.
+// A missing state file is not an error, the plugins keep their initial state.
+func (p *Persister) Load() error {
+	data, err := ioutil.ReadFile(p.Filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var states map[string]json.RawMessage
+	if err := json.Unmarshal(data, &states); err != nil {
+		return fmt.Errorf("parsing state file %q: %v", p.Filename, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, id := range p.ids {
+		data, ok := states[id]
+		if !ok {
+			continue
+		}
+
+		// Decode into a new value of the type returned by the plugin.
+		plugin := p.register[id]
+		typ := reflect.TypeOf(plugin.GetState())
+		if typ == nil {
+			continue
+		}
+		state := reflect.New(typ)
+		if err := json.Unmarshal(data, state.Interface()); err != nil {
+			return fmt.Errorf("decoding state of %s: %v", id, err)
+		}
+		if err := plugin.SetState(state.Elem().Interface()); err != nil {
+			return fmt.Errorf("restoring state of %s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// Store writes the state of the registered plugins.  The state file is
+// replaced atomically, so it is never left partially written.
+func (p *Persister) Store() error {
+	p.mu.Lock()
+	states := make(map[string]interface{}, len(p.ids))
+	for _, id := range p.ids {
+		states[id] = p.register[id].GetState()
+	}
+	p.mu.Unlock()
+
+	data, err := json.Marshal(states)
+	if err != nil {
+		return fmt.Errorf("encoding state: %v", err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(p.Filename), filepath.Base(p.Filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p.Filename)
+}
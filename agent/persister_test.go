@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type statefulPlugin struct {
+	state map[string]int64
+}
+
+func (p *statefulPlugin) GetState() interface{} {
+	return p.state
+}
+
+func (p *statefulPlugin) SetState(state interface{}) error {
+	p.state = state.(map[string]int64)
+	return nil
+}
+
+func TestPersister(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf-state")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "state.json")
+
+	// A missing state file keeps the initial state.
+	p := NewPersister(filename)
+	plugin := &statefulPlugin{state: map[string]int64{}}
+	require.NoError(t, p.Register("inputs.tail", plugin))
+	require.Error(t, p.Register("inputs.tail", plugin))
+	require.NoError(t, p.Load())
+	require.Equal(t, map[string]int64{}, plugin.state)
+
+	plugin.state = map[string]int64{"/var/log/syslog": 42}
+	require.NoError(t, p.Store())
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	p = NewPersister(filename)
+	restored := &statefulPlugin{state: map[string]int64{}}
+	other := &statefulPlugin{state: map[string]int64{"initial": 1}}
+	require.NoError(t, p.Register("inputs.tail", restored))
+	require.NoError(t, p.Register("inputs.tail#2", other))
+	require.NoError(t, p.Load())
+	require.Equal(t, map[string]int64{"/var/log/syslog": 42}, restored.state)
+	require.Equal(t, map[string]int64{"initial": 1}, other.state)
+
+	require.NoError(t, ioutil.WriteFile(filename, []byte("{"), 0600))
+	require.Error(t, p.Load())
+}
@@ -60,10 +60,12 @@ type Config struct {
 	InputFilters  []string
 	OutputFilters []string
 
-	Agent       *AgentConfig
-	Inputs      []*models.RunningInput
-	Outputs     []*models.RunningOutput
-	Aggregators []*models.RunningAggregator
+	Agent   *AgentConfig
+	Inputs  []*models.RunningInput
+	Outputs []*models.RunningOutput
+	// FailoverGroups contains the outputs sharing a failover_group
+	FailoverGroups []*models.FailoverGroup
+	Aggregators    []*models.RunningAggregator
 	// Processors have a slice wrapper type because they need to be sorted
 	Processors models.RunningProcessors
 }
@@ -77,6 +79,7 @@ func NewConfig() *Config {
 			FlushInterval:              internal.Duration{Duration: 10 * time.Second},
 			LogTarget:                  "file",
 			LogfileRotationMaxArchives: 5,
+			StatefileSaveInterval:      internal.Duration{Duration: time.Minute},
 		},
 
 		Tags:          make(map[string]string),
@@ -170,6 +173,16 @@ type AgentConfig struct {
 
 	Hostname     string
 	OmitHostname bool
+
+	// Statefile is the file the state of plugins implementing
+	// telegraf.StatefulPlugin is saved to on shutdown, and restored from on
+	// startup.  When empty no state is persisted.
+	Statefile string `toml:"statefile"`
+
+	// StatefileSaveInterval is the interval at which the state is saved in
+	// addition to the shutdown.  When set to 0 the state is only saved on
+	// shutdown.
+	StatefileSaveInterval internal.Duration `toml:"statefile_save_interval"`
 }
 
 // Inputs returns a list of strings of the configured inputs.
@@ -321,6 +334,13 @@ var agentConfig = `
   ## If set to true, do no set the "host" tag in the telegraf agent.
   omit_hostname = false
 
+  ## File to save the state of plugins to, such as the offsets of tailed
+  ## files, so they resume where they left off after a restart.  The state
+  ## is saved on shutdown and every statefile_save_interval.  When empty no
+  ## state is saved.
+  # statefile = ""
+  # statefile_save_interval = "1m"
+
 `
 
 var outputHeader = `
@@ -674,6 +694,12 @@ func getDefaultConfigPath() (string, error) {
 		" in $TELEGRAF_CONFIG_PATH, %s, or %s", homefile, etcfile)
 }
 
+// pluginTable is the configuration table of a plugin.
+type pluginTable struct {
+	name  string
+	table *ast.Table
+}
+
 // LoadConfig loads the given config file and applies it to c
 func (c *Config) LoadConfig(path string) error {
 	var err error
@@ -741,24 +767,32 @@ func (c *Config) LoadConfig(path string) error {
 		switch name {
 		case "agent", "global_tags", "tags":
 		case "outputs":
+			var outputs []pluginTable
 			for pluginName, pluginVal := range subTable.Fields {
 				switch pluginSubTable := pluginVal.(type) {
 				// legacy [outputs.influxdb] support
 				case *ast.Table:
-					if err = c.addOutput(pluginName, pluginSubTable); err != nil {
-						return fmt.Errorf("Error parsing %s, %s", path, err)
-					}
+					outputs = append(outputs, pluginTable{pluginName, pluginSubTable})
 				case []*ast.Table:
 					for _, t := range pluginSubTable {
-						if err = c.addOutput(pluginName, t); err != nil {
-							return fmt.Errorf("Error parsing %s, %s", path, err)
-						}
+						outputs = append(outputs, pluginTable{pluginName, t})
 					}
 				default:
 					return fmt.Errorf("Unsupported config format: %s, file %s",
 						pluginName, path)
 				}
 			}
+
+			// Outputs are added in the order they are defined in, which is
+			// the priority of the outputs of a failover group.
+			sort.SliceStable(outputs, func(i, j int) bool {
+				return outputs[i].table.Line < outputs[j].table.Line
+			})
+			for _, output := range outputs {
+				if err = c.addOutput(output.name, output.table); err != nil {
+					return fmt.Errorf("Error parsing %s, %s", path, err)
+				}
+			}
 		case "inputs", "plugins":
 			for pluginName, pluginVal := range subTable.Fields {
 				switch pluginSubTable := pluginVal.(type) {
@@ -979,9 +1013,26 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 	ro := models.NewRunningOutput(name, output, outputConfig,
 		c.Agent.MetricBatchSize, c.Agent.MetricBufferLimit)
 	c.Outputs = append(c.Outputs, ro)
+
+	if outputConfig.FailoverGroup != "" {
+		c.failoverGroup(outputConfig.FailoverGroup).Add(ro)
+	}
 	return nil
 }
 
+// failoverGroup returns the failover group with the given name, creating it
+// if needed.
+func (c *Config) failoverGroup(name string) *models.FailoverGroup {
+	for _, group := range c.FailoverGroups {
+		if group.Name == name {
+			return group
+		}
+	}
+	group := models.NewFailoverGroup(name)
+	c.FailoverGroups = append(c.FailoverGroups, group)
+	return group
+}
+
 func (c *Config) addInput(name string, table *ast.Table) error {
 	if len(c.InputFilters) > 0 && !sliceContains(name, c.InputFilters) {
 		return nil
@@ -1672,6 +1723,18 @@ func getParserConfig(name string, tbl *ast.Table) (*parsers.Config, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["grok_multiline"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.GrokMultiline, err = b.Boolean()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
 	//for csv parser
 	if node, ok := tbl.Fields["csv_column_names"]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
@@ -1810,6 +1873,86 @@ func getParserConfig(name string, tbl *ast.Table) (*parsers.Config, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["avro_schema_registry"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroSchemaRegistry = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_schema"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroSchema = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_schema_file"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroSchemaFile = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_measurement"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroMeasurement = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_tags"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.AvroTags = append(c.AvroTags, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_fields"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.AvroFields = append(c.AvroFields, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_timestamp"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroTimestamp = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_timestamp_format"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroTimestampFormat = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_field_separator"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroFieldSeparator = str.Value
+			}
+		}
+	}
+
 	c.MetricName = name
 
 	delete(tbl.Fields, "data_format")
@@ -1839,6 +1982,7 @@ func getParserConfig(name string, tbl *ast.Table) (*parsers.Config, error) {
 	delete(tbl.Fields, "grok_custom_pattern_files")
 	delete(tbl.Fields, "grok_timezone")
 	delete(tbl.Fields, "grok_unique_timestamp")
+	delete(tbl.Fields, "grok_multiline")
 	delete(tbl.Fields, "csv_column_names")
 	delete(tbl.Fields, "csv_column_types")
 	delete(tbl.Fields, "csv_comment")
@@ -1853,6 +1997,15 @@ func getParserConfig(name string, tbl *ast.Table) (*parsers.Config, error) {
 	delete(tbl.Fields, "csv_timestamp_format")
 	delete(tbl.Fields, "csv_trim_space")
 	delete(tbl.Fields, "form_urlencoded_tag_keys")
+	delete(tbl.Fields, "avro_schema_registry")
+	delete(tbl.Fields, "avro_schema")
+	delete(tbl.Fields, "avro_schema_file")
+	delete(tbl.Fields, "avro_measurement")
+	delete(tbl.Fields, "avro_tags")
+	delete(tbl.Fields, "avro_fields")
+	delete(tbl.Fields, "avro_timestamp")
+	delete(tbl.Fields, "avro_timestamp_format")
+	delete(tbl.Fields, "avro_field_separator")
 
 	return c, nil
 }
@@ -1891,6 +2044,14 @@ func buildSerializer(name string, tbl *ast.Table) (serializers.Serializer, error
 		}
 	}
 
+	if node, ok := tbl.Fields["batch_template"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.BatchTemplate = str.Value
+			}
+		}
+	}
+
 	if node, ok := tbl.Fields["templates"]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
 			if ary, ok := kv.Value.(*ast.Array); ok {
@@ -1967,6 +2128,86 @@ func buildSerializer(name string, tbl *ast.Table) (serializers.Serializer, error
 		}
 	}
 
+	if node, ok := tbl.Fields["json_timestamp_format"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONTimestampFormat = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_name_key"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONNameKey = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_timestamp_key"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONTimestampKey = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_tags_key"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONTagsKey = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_fields_key"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONFieldsKey = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_flatten_tags"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.JSONFlattenTags, err = b.Boolean()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_flatten_fields"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.JSONFlattenFields, err = b.Boolean()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_batch_format"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONBatchFormat = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_batch_key"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONBatchKey = str.Value
+			}
+		}
+	}
+
 	if node, ok := tbl.Fields["splunkmetric_hec_routing"]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
 			if b, ok := kv.Value.(*ast.Boolean); ok {
@@ -2051,6 +2292,114 @@ func buildSerializer(name string, tbl *ast.Table) (serializers.Serializer, error
 		}
 	}
 
+	if node, ok := tbl.Fields["csv_timestamp_format"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.CSVTimestampFormat = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_separator"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.CSVSeparator = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_header"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.CSVHeader, err = b.Boolean()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_column_prefix"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.CSVColumnPrefix, err = b.Boolean()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_schema_registry"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroSchemaRegistry = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_schema"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroSchema = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_schema_file"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroSchemaFile = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_subject"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroSubject = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_schema_id"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				c.AvroSchemaID = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_measurement_field"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroMeasurementField = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_timestamp"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroTimestamp = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_timestamp_format"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroTimestampFormat = str.Value
+			}
+		}
+	}
+
 	delete(tbl.Fields, "influx_max_line_bytes")
 	delete(tbl.Fields, "influx_sort_fields")
 	delete(tbl.Fields, "influx_uint_support")
@@ -2059,7 +2408,17 @@ func buildSerializer(name string, tbl *ast.Table) (serializers.Serializer, error
 	delete(tbl.Fields, "prefix")
 	delete(tbl.Fields, "template")
 	delete(tbl.Fields, "templates")
+	delete(tbl.Fields, "batch_template")
 	delete(tbl.Fields, "json_timestamp_units")
+	delete(tbl.Fields, "json_timestamp_format")
+	delete(tbl.Fields, "json_name_key")
+	delete(tbl.Fields, "json_timestamp_key")
+	delete(tbl.Fields, "json_tags_key")
+	delete(tbl.Fields, "json_fields_key")
+	delete(tbl.Fields, "json_flatten_tags")
+	delete(tbl.Fields, "json_flatten_fields")
+	delete(tbl.Fields, "json_batch_format")
+	delete(tbl.Fields, "json_batch_key")
 	delete(tbl.Fields, "splunkmetric_hec_routing")
 	delete(tbl.Fields, "splunkmetric_multimetric")
 	delete(tbl.Fields, "wavefront_source_override")
@@ -2067,6 +2426,18 @@ func buildSerializer(name string, tbl *ast.Table) (serializers.Serializer, error
 	delete(tbl.Fields, "prometheus_export_timestamp")
 	delete(tbl.Fields, "prometheus_sort_metrics")
 	delete(tbl.Fields, "prometheus_string_as_label")
+	delete(tbl.Fields, "csv_timestamp_format")
+	delete(tbl.Fields, "csv_separator")
+	delete(tbl.Fields, "csv_header")
+	delete(tbl.Fields, "csv_column_prefix")
+	delete(tbl.Fields, "avro_schema_registry")
+	delete(tbl.Fields, "avro_schema")
+	delete(tbl.Fields, "avro_schema_file")
+	delete(tbl.Fields, "avro_subject")
+	delete(tbl.Fields, "avro_schema_id")
+	delete(tbl.Fields, "avro_measurement_field")
+	delete(tbl.Fields, "avro_timestamp")
+	delete(tbl.Fields, "avro_timestamp_format")
 	return serializers.NewSerializer(c)
 }
 
@@ -2175,6 +2546,110 @@ func buildOutput(name string, tbl *ast.Table) (*models.OutputConfig, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["failover_group"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				oc.FailoverGroup = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["failover_threshold"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				oc.FailoverThreshold = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["failover_replay"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				oc.FailoverReplay, err = b.Boolean()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["retry_backoff"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.InitialBackoff = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["retry_max_backoff"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.MaxBackoff = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["retry_backoff_multiplier"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if float, ok := kv.Value.(*ast.Float); ok {
+				v, err := float.Float()
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.Multiplier = v
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["retry_jitter"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if float, ok := kv.Value.(*ast.Float); ok {
+				v, err := float.Float()
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.Jitter = v
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["circuit_breaker_threshold"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.CircuitBreakerThreshold = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["circuit_breaker_timeout"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.CircuitBreakerTimeout = dur
+			}
+		}
+	}
+
 	delete(tbl.Fields, "flush_interval")
 	delete(tbl.Fields, "flush_jitter")
 	delete(tbl.Fields, "metric_buffer_limit")
@@ -2183,6 +2658,15 @@ func buildOutput(name string, tbl *ast.Table) (*models.OutputConfig, error) {
 	delete(tbl.Fields, "name_override")
 	delete(tbl.Fields, "name_suffix")
 	delete(tbl.Fields, "name_prefix")
+	delete(tbl.Fields, "failover_group")
+	delete(tbl.Fields, "failover_threshold")
+	delete(tbl.Fields, "failover_replay")
+	delete(tbl.Fields, "retry_backoff")
+	delete(tbl.Fields, "retry_max_backoff")
+	delete(tbl.Fields, "retry_backoff_multiplier")
+	delete(tbl.Fields, "retry_jitter")
+	delete(tbl.Fields, "circuit_breaker_threshold")
+	delete(tbl.Fields, "circuit_breaker_timeout")
 
 	return oc, nil
 }
@@ -12,7 +12,9 @@ import (
 	"github.com/influxdata/telegraf/plugins/inputs/http_listener_v2"
 	"github.com/influxdata/telegraf/plugins/inputs/memcached"
 	"github.com/influxdata/telegraf/plugins/inputs/procstat"
+	_ "github.com/influxdata/telegraf/plugins/outputs/file"
 	httpOut "github.com/influxdata/telegraf/plugins/outputs/http"
+	_ "github.com/influxdata/telegraf/plugins/outputs/influxdb"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -257,3 +259,23 @@ func TestConfig_BadOrdering(t *testing.T) {
 	require.Error(t, err, "bad ordering")
 	assert.Equal(t, "Error parsing ./testdata/non_slice_slice.toml, line 4: cannot unmarshal TOML array into string (need slice)", err.Error())
 }
+
+func TestConfig_FailoverGroup(t *testing.T) {
+	c := NewConfig()
+	err := c.LoadConfig("./testdata/failover_group.toml")
+	require.NoError(t, err)
+
+	require.Len(t, c.Outputs, 3)
+	require.Len(t, c.FailoverGroups, 1)
+
+	group := c.FailoverGroups[0]
+	require.Equal(t, "influxdb", group.Name)
+	require.Equal(t, []*models.RunningOutput{c.Outputs[0], c.Outputs[1]}, group.Outputs)
+	require.Equal(t, "influxdb", c.Outputs[0].Config.Name)
+	require.Equal(t, 5, c.Outputs[0].Config.FailoverThreshold)
+	require.True(t, c.Outputs[0].Config.FailoverReplay)
+	require.Equal(t, "file", c.Outputs[1].Config.Name)
+	require.False(t, c.Outputs[1].Config.FailoverReplay)
+	require.Equal(t, "file", c.Outputs[2].Config.Name)
+	require.Empty(t, c.Outputs[2].Config.FailoverGroup)
+}
@@ -0,0 +1,125 @@
+package snmp
+
+import (
+	"fmt"
+)
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+func (t token) String() string {
+	return fmt.Sprintf("%q on line %d", t.text, t.line)
+}
+
+// lex splits the ASN.1 source of a MIB file into tokens.  Comments are
+// dropped, quoted strings and binary or hexadecimal strings are returned as a
+// single string token.
+func lex(src []byte) ([]token, error) {
+	var tokens []token
+	line := 1
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case isSpace(c):
+			i++
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			// Comments end at the end of the line or the next "--".
+			i += 2
+			for i < len(src) && src[i] != '\n' {
+				if src[i] == '-' && i+1 < len(src) && src[i+1] == '-' {
+					i += 2
+					break
+				}
+				i++
+			}
+		case c == '"':
+			start, startLine := i+1, line
+			i++
+			for i < len(src) && src[i] != '"' {
+				if src[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("unterminated string starting on line %d", startLine)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: string(src[start:i]), line: startLine})
+			i++
+		case c == '\'':
+			// Binary or hexadecimal string such as '00'H.
+			start := i
+			i++
+			for i < len(src) && src[i] != '\'' && src[i] != '\n' {
+				i++
+			}
+			if i >= len(src) || src[i] != '\'' {
+				return nil, fmt.Errorf("unterminated quoted string on line %d", line)
+			}
+			i++
+			if i < len(src) && (src[i] == 'H' || src[i] == 'h' || src[i] == 'B' || src[i] == 'b') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenString, text: string(src[start:i]), line: line})
+		case isDigit(c) || (c == '-' && i+1 < len(src) && isDigit(src[i+1])):
+			start := i
+			i++
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(src[start:i]), line: line})
+		case isLetter(c):
+			start := i
+			i++
+			for i < len(src) && (isLetter(src[i]) || isDigit(src[i]) || src[i] == '_' ||
+				(src[i] == '-' && !(i+1 < len(src) && src[i+1] == '-'))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(src[start:i]), line: line})
+		case c == ':' && i+2 < len(src) && src[i+1] == ':' && src[i+2] == '=':
+			tokens = append(tokens, token{kind: tokenSymbol, text: "::=", line: line})
+			i += 3
+		case c == '.' && i+1 < len(src) && src[i+1] == '.':
+			tokens = append(tokens, token{kind: tokenSymbol, text: "..", line: line})
+			i += 2
+		case c == '{' || c == '}' || c == '(' || c == ')' || c == '[' || c == ']' ||
+			c == ',' || c == ';' || c == '|' || c == '.' || c == '<' || c == '>' ||
+			c == '@' || c == '*' || c == '!' || c == '&' || c == '=' || c == ':':
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(c), line: line})
+			i++
+		default:
+			// Stray characters outside of strings are ignored, they
+			// occasionally appear in vendor MIBs.
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
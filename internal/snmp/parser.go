@@ -0,0 +1,535 @@
+package snmp
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// module is a MIB module parsed from a MIB file.
+type module struct {
+	name          string
+	imports       map[string]string
+	objects       []*object
+	objectsByName map[string]*object
+	types         map[string]*typeDef
+}
+
+func (m *module) addObject(obj *object) {
+	if _, ok := m.objectsByName[obj.name]; ok {
+		return
+	}
+	m.objects = append(m.objects, obj)
+	m.objectsByName[obj.name] = obj
+}
+
+// object is a value assignment with an OID value, such as an OBJECT-TYPE or
+// OBJECT IDENTIFIER definition.
+type object struct {
+	name  string
+	macro string
+	oid   []oidComponent
+
+	// Only set for TRAP-TYPE definitions, which are numbered below their
+	// enterprise.
+	enterprise string
+	trapNumber int
+
+	syntax   string
+	access   string
+	index    []string
+	augments string
+}
+
+// oidComponent is an element of an OID value: a name, a number or a name
+// with a number such as "org(3)".
+type oidComponent struct {
+	name      string
+	number    int
+	hasNumber bool
+}
+
+// typeDef is a type assignment, either a plain ASN.1 type or a
+// TEXTUAL-CONVENTION.
+type typeDef struct {
+	name   string
+	syntax string
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parseModules parses all MIB modules in the source of a MIB file.
+func parseModules(src []byte) ([]*module, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	var modules []*module
+	for !p.atEnd() {
+		m, err := p.parseModule()
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, m)
+	}
+	return modules, nil
+}
+
+func (p *parser) atEnd() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	if p.atEnd() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() (token, error) {
+	if p.atEnd() {
+		return token{}, fmt.Errorf("unexpected end of file")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *parser) expect(text string) error {
+	t, err := p.next()
+	if err != nil {
+		return fmt.Errorf("expected %q: %v", text, err)
+	}
+	if t.text != text {
+		return fmt.Errorf("expected %q, got %s", text, t)
+	}
+	return nil
+}
+
+func (p *parser) ident() (token, error) {
+	t, err := p.next()
+	if err != nil {
+		return t, err
+	}
+	if t.kind != tokenIdent {
+		return t, fmt.Errorf("expected identifier, got %s", t)
+	}
+	return t, nil
+}
+
+// skipBalanced skips a bracketed group starting at the current token,
+// including any nested groups.
+func (p *parser) skipBalanced() error {
+	depth := 0
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		if t.kind != tokenSymbol {
+			continue
+		}
+		switch t.text {
+		case "{", "(", "[":
+			depth++
+		case "}", ")", "]":
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// skipTo skips all tokens up to and including the given identifier.
+func (p *parser) skipTo(text string) error {
+	for {
+		t, err := p.next()
+		if err != nil {
+			return fmt.Errorf("expected %q: %v", text, err)
+		}
+		if t.kind == tokenIdent && t.text == text {
+			return nil
+		}
+	}
+}
+
+func (p *parser) parseModule() (*module, error) {
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	m := &module{
+		name:          name.text,
+		imports:       make(map[string]string),
+		objectsByName: make(map[string]*object),
+		types:         make(map[string]*typeDef),
+	}
+
+	if p.peek().text == "{" {
+		if err := p.skipBalanced(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("DEFINITIONS"); err != nil {
+		return nil, fmt.Errorf("module %s: %v", m.name, err)
+	}
+	// Skip tag defaults such as "IMPLICIT TAGS".
+	for p.peek().text != "::=" {
+		if _, err := p.next(); err != nil {
+			return nil, fmt.Errorf("module %s: %v", m.name, err)
+		}
+	}
+	p.pos++
+	if err := p.expect("BEGIN"); err != nil {
+		return nil, fmt.Errorf("module %s: %v", m.name, err)
+	}
+
+	for {
+		t := p.peek()
+		switch {
+		case p.atEnd():
+			return nil, fmt.Errorf("module %s: missing END", m.name)
+		case t.kind == tokenIdent && t.text == "END":
+			p.pos++
+			return m, nil
+		case t.kind == tokenIdent && t.text == "EXPORTS":
+			for p.peek().text != ";" && !p.atEnd() {
+				p.pos++
+			}
+			p.pos++
+		case t.kind == tokenIdent && t.text == "IMPORTS":
+			p.pos++
+			if err := p.parseImports(m); err != nil {
+				return nil, fmt.Errorf("module %s: %v", m.name, err)
+			}
+		default:
+			if err := p.parseAssignment(m); err != nil {
+				return nil, fmt.Errorf("module %s: %v", m.name, err)
+			}
+		}
+	}
+}
+
+func (p *parser) parseImports(m *module) error {
+	var symbols []string
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch {
+		case t.text == ";":
+			return nil
+		case t.text == ",":
+		case t.kind == tokenIdent && t.text == "FROM":
+			from, err := p.ident()
+			if err != nil {
+				return err
+			}
+			for _, s := range symbols {
+				m.imports[s] = from.text
+			}
+			symbols = symbols[:0]
+			if p.peek().text == "{" {
+				if err := p.skipBalanced(); err != nil {
+					return err
+				}
+			}
+		case t.kind == tokenIdent:
+			symbols = append(symbols, t.text)
+		default:
+			return fmt.Errorf("unexpected %s in IMPORTS", t)
+		}
+	}
+}
+
+func (p *parser) parseAssignment(m *module) error {
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+
+	if p.peek().text == "MACRO" {
+		return p.skipTo("END")
+	}
+
+	if isUpper(name.text[0]) {
+		if err := p.expect("::="); err != nil {
+			return err
+		}
+		def := &typeDef{name: name.text}
+		if p.peek().text == "TEXTUAL-CONVENTION" {
+			if err := p.skipTo("SYNTAX"); err != nil {
+				return err
+			}
+		}
+		def.syntax, err = p.parseType()
+		if err != nil {
+			return err
+		}
+		m.types[def.name] = def
+		return nil
+	}
+
+	// Value assignments consist of the clauses of a macro up to the "::=",
+	// followed by the value.
+	start := p.pos
+	for p.peek().text != "::=" {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		if t.kind == tokenIdent && t.text == "END" {
+			return fmt.Errorf("missing value for %s", name)
+		}
+		if t.text == "{" || t.text == "(" || t.text == "[" {
+			p.pos--
+			if err := p.skipBalanced(); err != nil {
+				return err
+			}
+		}
+	}
+	header := p.tokens[start:p.pos]
+	p.pos++
+
+	obj := &object{name: name.text}
+	if err := obj.parseClauses(header); err != nil {
+		return fmt.Errorf("%s: %v", name.text, err)
+	}
+
+	if p.peek().text != "{" {
+		// Either the number of a TRAP-TYPE or a value that is not an OID.
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		if obj.macro == "TRAP-TYPE" && t.kind == tokenNumber {
+			obj.trapNumber, _ = strconv.Atoi(t.text)
+			m.addObject(obj)
+		}
+		return nil
+	}
+
+	obj.oid, err = p.parseOid()
+	if err != nil {
+		return fmt.Errorf("%s: %v", name.text, err)
+	}
+	m.addObject(obj)
+	return nil
+}
+
+// parseClauses extracts the clauses of interest from the macro of an object.
+func (o *object) parseClauses(header []token) error {
+	if len(header) == 0 {
+		return nil
+	}
+	o.macro = header[0].text
+	if o.macro == "OBJECT" && len(header) > 1 && header[1].text == "IDENTIFIER" {
+		o.macro = "OBJECT IDENTIFIER"
+	}
+
+	p := &parser{tokens: header, pos: 1}
+	for !p.atEnd() {
+		t, _ := p.next()
+		if t.text == "{" || t.text == "(" || t.text == "[" {
+			p.pos--
+			if err := p.skipBalanced(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch {
+		case o.macro == "OBJECT-TYPE" && t.text == "SYNTAX":
+			syntax, err := p.parseType()
+			if err != nil {
+				return err
+			}
+			o.syntax = syntax
+		case o.macro == "OBJECT-TYPE" && (t.text == "MAX-ACCESS" || t.text == "ACCESS"):
+			access, err := p.ident()
+			if err != nil {
+				return err
+			}
+			o.access = access.text
+		case o.macro == "OBJECT-TYPE" && t.text == "INDEX":
+			names, err := p.parseNameList()
+			if err != nil {
+				return err
+			}
+			o.index = names
+		case o.macro == "OBJECT-TYPE" && t.text == "AUGMENTS":
+			names, err := p.parseNameList()
+			if err != nil {
+				return err
+			}
+			if len(names) > 0 {
+				o.augments = names[0]
+			}
+		case o.macro == "TRAP-TYPE" && t.text == "ENTERPRISE":
+			enterprise, err := p.ident()
+			if err != nil {
+				return err
+			}
+			o.enterprise = enterprise.text
+		}
+	}
+	return nil
+}
+
+// parseType parses an ASN.1 type, returning its name.  Named numbers, sizes
+// and ranges are skipped.
+func (p *parser) parseType() (string, error) {
+	if p.peek().text == "[" {
+		if err := p.skipBalanced(); err != nil {
+			return "", err
+		}
+	}
+	if t := p.peek().text; t == "IMPLICIT" || t == "EXPLICIT" {
+		p.pos++
+	}
+
+	t, err := p.next()
+	if err != nil {
+		return "", err
+	}
+
+	var name string
+	switch t.text {
+	case "SEQUENCE":
+		if p.peek().text == "OF" {
+			p.pos++
+			elem, err := p.parseType()
+			if err != nil {
+				return "", err
+			}
+			name = "SEQUENCE OF " + elem
+		} else {
+			name = "SEQUENCE"
+			if err := p.skipBalanced(); err != nil {
+				return "", err
+			}
+		}
+	case "CHOICE":
+		name = "CHOICE"
+		if err := p.skipBalanced(); err != nil {
+			return "", err
+		}
+	case "OCTET":
+		if err := p.expect("STRING"); err != nil {
+			return "", err
+		}
+		name = "OCTET STRING"
+	case "OBJECT":
+		if err := p.expect("IDENTIFIER"); err != nil {
+			return "", err
+		}
+		name = "OBJECT IDENTIFIER"
+	default:
+		if t.kind != tokenIdent {
+			return "", fmt.Errorf("expected type, got %s", t)
+		}
+		name = t.text
+		// Type references can be qualified with their module.
+		if p.peek().text == "." {
+			p.pos++
+			ref, err := p.ident()
+			if err != nil {
+				return "", err
+			}
+			name = ref.text
+		}
+		if p.peek().text == "{" {
+			if err := p.skipBalanced(); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	for p.peek().text == "(" {
+		if err := p.skipBalanced(); err != nil {
+			return "", err
+		}
+	}
+	return name, nil
+}
+
+// parseNameList parses a list of names in braces as used by the INDEX and
+// AUGMENTS clauses.
+func (p *parser) parseNameList() ([]string, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case t.text == "}":
+			return names, nil
+		case t.text == "," || t.text == "IMPLIED":
+		case t.kind == tokenIdent:
+			names = append(names, t.text)
+		default:
+			return nil, fmt.Errorf("unexpected %s in list", t)
+		}
+	}
+}
+
+func (p *parser) parseOid() ([]oidComponent, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var oid []oidComponent
+	for {
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch t.kind {
+		case tokenNumber:
+			n, err := strconv.Atoi(t.text)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid sub-identifier %s", t)
+			}
+			oid = append(oid, oidComponent{number: n, hasNumber: true})
+		case tokenIdent:
+			c := oidComponent{name: t.text}
+			if p.peek().text == "(" {
+				p.pos++
+				num, err := p.next()
+				if err != nil {
+					return nil, err
+				}
+				c.number, err = strconv.Atoi(num.text)
+				if err != nil || c.number < 0 {
+					return nil, fmt.Errorf("invalid sub-identifier %s", num)
+				}
+				c.hasNumber = true
+				if err := p.expect(")"); err != nil {
+					return nil, err
+				}
+			}
+			oid = append(oid, c)
+		default:
+			if t.text == "}" {
+				if len(oid) == 0 {
+					return nil, fmt.Errorf("empty OID value")
+				}
+				return oid, nil
+			}
+			return nil, fmt.Errorf("unexpected %s in OID value", t)
+		}
+	}
+}
+
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
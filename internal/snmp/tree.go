@@ -0,0 +1,479 @@
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// ErrNotFound is returned when an OID cannot be resolved using the loaded
+// MIBs.
+var ErrNotFound = errors.New("not found in the loaded MIBs")
+
+// maxTypeDepth limits how many textual conventions are followed when
+// resolving the syntax of an object.
+const maxTypeDepth = 16
+
+// Node is an object in the OID tree.
+type Node struct {
+	Name   string
+	Module string
+	Oid    string
+
+	// Access is the MAX-ACCESS or ACCESS clause of an OBJECT-TYPE.
+	Access string
+	// Syntax is the type of an OBJECT-TYPE as written in the MIB.
+	Syntax string
+	// Types are the named types the syntax refers to, starting with the
+	// syntax itself and followed by the types of the textual conventions it
+	// is defined with.
+	Types []string
+	// Index lists the objects indexing a table row.  For rows augmenting
+	// another row it is the index of the augmented row.
+	Index []string
+
+	subid    int
+	parent   *Node
+	children map[int]*Node
+}
+
+// Children returns the child nodes ordered by sub-identifier.
+func (n *Node) Children() []*Node {
+	children := make([]*Node, 0, len(n.children))
+	for _, child := range n.children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].subid < children[j].subid
+	})
+	return children
+}
+
+// IsTable returns true if the node is a table, its syntax being a SEQUENCE OF
+// rows.
+func (n *Node) IsTable() bool {
+	return strings.HasPrefix(n.Syntax, "SEQUENCE OF ")
+}
+
+// hasInstances returns true if the node is a scalar or a column, whose
+// instances are identified by sub-identifiers appended to its OID.
+func (n *Node) hasInstances() bool {
+	if n.Syntax == "" || n.IsTable() {
+		return false
+	}
+	return n.parent == nil || !n.parent.IsTable()
+}
+
+// Row returns the row definition of a table.
+func (n *Node) Row() *Node {
+	for _, child := range n.Children() {
+		if child.Name != "" {
+			return child
+		}
+	}
+	return nil
+}
+
+// Columns returns the accessible columns of a table.
+func (n *Node) Columns() ([]*Node, error) {
+	if !n.IsTable() {
+		return nil, fmt.Errorf("%s::%s is not a table", n.Module, n.Name)
+	}
+
+	row := n.Row()
+	if row == nil {
+		return nil, fmt.Errorf("table %s::%s has no row definition", n.Module, n.Name)
+	}
+
+	var columns []*Node
+	for _, column := range row.Children() {
+		if column.Name == "" || column.Access == "not-accessible" || column.Access == "accessible-for-notify" {
+			continue
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("could not find any columns in table %s::%s", n.Module, n.Name)
+	}
+	return columns, nil
+}
+
+// Tree is the OID tree built from MIB modules.
+type Tree struct {
+	root    *Node
+	names   map[string]*Node
+	modules map[string]*module
+	nodes   map[string]map[string]*Node
+
+	order    []*module
+	resolved map[*object][]int
+	visiting map[*object]bool
+}
+
+var (
+	treesLock sync.Mutex
+	trees     = map[string]*Tree{}
+)
+
+// LoadTree builds an OID tree from the MIB files found in the given
+// directories and their subdirectories.  Files that cannot be parsed are
+// skipped.  Trees are shared between callers using the same directories.
+func LoadTree(paths []string, log telegraf.Logger) (*Tree, error) {
+	key := strings.Join(paths, string(filepath.ListSeparator))
+
+	treesLock.Lock()
+	defer treesLock.Unlock()
+	if tree, ok := trees[key]; ok {
+		return tree, nil
+	}
+
+	tree := NewTree()
+	for _, path := range paths {
+		err := filepath.Walk(path, func(filename string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && filename == path {
+					log.Debugf("MIB path %q does not exist", path)
+					return nil
+				}
+				return err
+			}
+			if strings.HasPrefix(info.Name(), ".") && filename != path {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			src, err := ioutil.ReadFile(filename)
+			if err != nil {
+				return err
+			}
+			if err := tree.AddMib(src); err != nil {
+				log.Warnf("Skipping MIB file %q: %v", filename, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("loading MIBs from %q: %v", path, err)
+		}
+	}
+	tree.Build()
+
+	trees[key] = tree
+	return tree, nil
+}
+
+// NewTree returns an empty OID tree holding the well-known root nodes.
+func NewTree() *Tree {
+	t := &Tree{
+		root:    &Node{children: map[int]*Node{}},
+		names:   map[string]*Node{},
+		modules: map[string]*module{},
+		nodes:   map[string]map[string]*Node{},
+	}
+	for subid, name := range []string{"ccitt", "iso", "joint-iso-ccitt"} {
+		node := t.insert([]int{subid})
+		node.Name = name
+		t.names[name] = node
+	}
+	return t
+}
+
+// AddMib parses the modules of a MIB file.  Build must be called once all
+// files are added.
+func (t *Tree) AddMib(src []byte) error {
+	modules, err := parseModules(src)
+	if err != nil {
+		return err
+	}
+	for _, m := range modules {
+		// The first definition of a module wins, as with MIB search paths.
+		if _, ok := t.modules[m.name]; ok {
+			continue
+		}
+		t.modules[m.name] = m
+		t.order = append(t.order, m)
+	}
+	return nil
+}
+
+// Build resolves the OIDs of all objects of the added modules and inserts
+// them into the tree.  Objects whose OID cannot be resolved, for example
+// because a module they depend on is missing, are left out.
+func (t *Tree) Build() {
+	t.resolved = map[*object][]int{}
+	t.visiting = map[*object]bool{}
+
+	var rows []*Node
+	augments := map[*Node]string{}
+	for _, m := range t.order {
+		nodes := map[string]*Node{}
+		t.nodes[m.name] = nodes
+
+		for _, obj := range m.objects {
+			oid, err := t.resolveObject(m, obj)
+			if err != nil {
+				continue
+			}
+
+			node := t.insert(oid)
+			if node.Name != "" {
+				// Already defined by another module.
+				nodes[obj.name] = node
+				continue
+			}
+			node.Name = obj.name
+			node.Module = m.name
+			node.Access = obj.access
+			node.Syntax = obj.syntax
+			node.Types = t.resolveTypes(m, obj.syntax)
+			node.Index = obj.index
+			nodes[obj.name] = node
+
+			if _, ok := t.names[obj.name]; !ok {
+				t.names[obj.name] = node
+			}
+			if obj.augments != "" {
+				rows = append(rows, node)
+				augments[node] = obj.augments
+			}
+		}
+	}
+
+	// Rows augmenting another row share its index.
+	for _, row := range rows {
+		if augmented := t.nodes[row.Module][augments[row]]; augmented != nil {
+			row.Index = augmented.Index
+		} else if augmented := t.names[augments[row]]; augmented != nil {
+			row.Index = augmented.Index
+		}
+	}
+
+	t.resolved = nil
+	t.visiting = nil
+}
+
+func (t *Tree) insert(oid []int) *Node {
+	node := t.root
+	for _, subid := range oid {
+		child, ok := node.children[subid]
+		if !ok {
+			child = &Node{
+				Oid:      node.Oid + "." + strconv.Itoa(subid),
+				subid:    subid,
+				parent:   node,
+				children: map[int]*Node{},
+			}
+			node.children[subid] = child
+		}
+		node = child
+	}
+	return node
+}
+
+// lookupObject finds an object visible in the scope of a module: defined
+// locally, imported or, as a last resort, defined in any loaded module.
+func (t *Tree) lookupObject(m *module, name string) (*module, *object) {
+	if obj, ok := m.objectsByName[name]; ok {
+		return m, obj
+	}
+	if from, ok := t.modules[m.imports[name]]; ok {
+		if obj, ok := from.objectsByName[name]; ok {
+			return from, obj
+		}
+	}
+	for _, other := range t.order {
+		if obj, ok := other.objectsByName[name]; ok {
+			return other, obj
+		}
+	}
+	return nil, nil
+}
+
+func (t *Tree) resolveName(m *module, name string) ([]int, error) {
+	if from, obj := t.lookupObject(m, name); obj != nil {
+		return t.resolveObject(from, obj)
+	}
+	if node, ok := t.names[name]; ok && node.Module == "" {
+		return []int{node.subid}, nil
+	}
+	return nil, fmt.Errorf("unknown object %q", name)
+}
+
+func (t *Tree) resolveObject(m *module, obj *object) ([]int, error) {
+	if oid, ok := t.resolved[obj]; ok {
+		return oid, nil
+	}
+	if t.visiting[obj] {
+		return nil, fmt.Errorf("circular definition of %q", obj.name)
+	}
+	t.visiting[obj] = true
+	defer delete(t.visiting, obj)
+
+	var oid []int
+	if obj.macro == "TRAP-TYPE" {
+		// SMIv1 traps are numbered below their enterprise, see RFC 2576.
+		enterprise, err := t.resolveName(m, obj.enterprise)
+		if err != nil {
+			return nil, err
+		}
+		oid = append(append(oid, enterprise...), 0, obj.trapNumber)
+	} else {
+		for i, c := range obj.oid {
+			switch {
+			case c.hasNumber:
+				oid = append(oid, c.number)
+			case i == 0:
+				parent, err := t.resolveName(m, c.name)
+				if err != nil {
+					return nil, err
+				}
+				oid = append(oid, parent...)
+			default:
+				return nil, fmt.Errorf("missing number for %q in OID of %q", c.name, obj.name)
+			}
+		}
+	}
+
+	// Copy to avoid sharing the backing array with the parent.
+	resolved := make([]int, len(oid))
+	copy(resolved, oid)
+	t.resolved[obj] = resolved
+	return resolved, nil
+}
+
+// resolveTypes follows the chain of named types of a syntax.
+func (t *Tree) resolveTypes(m *module, syntax string) []string {
+	var types []string
+	for i := 0; i < maxTypeDepth && isTypeReference(syntax); i++ {
+		types = append(types, syntax)
+
+		from, def := t.lookupType(m, syntax)
+		if def == nil {
+			break
+		}
+		m, syntax = from, def.syntax
+	}
+	return types
+}
+
+func (t *Tree) lookupType(m *module, name string) (*module, *typeDef) {
+	if def, ok := m.types[name]; ok {
+		return m, def
+	}
+	if from, ok := t.modules[m.imports[name]]; ok {
+		if def, ok := from.types[name]; ok {
+			return from, def
+		}
+	}
+	for _, other := range t.order {
+		if def, ok := other.types[name]; ok {
+			return other, def
+		}
+	}
+	return nil, nil
+}
+
+func isTypeReference(syntax string) bool {
+	switch syntax {
+	case "", "INTEGER", "OCTET STRING", "OBJECT IDENTIFIER", "BITS", "SEQUENCE", "CHOICE", "NULL":
+		return false
+	}
+	return !strings.HasPrefix(syntax, "SEQUENCE OF ")
+}
+
+// Lookup resolves an OID given as numbers, names or both, optionally
+// qualified by its module as in "IF-MIB::ifDescr.1".  It returns the deepest
+// node defined by a MIB along with the remaining sub-identifiers, such as
+// ".1" for the example.  Remaining sub-identifiers are only allowed as the
+// instance of a scalar or column, any other OID not defined by the loaded
+// MIBs is not found.
+func (t *Tree) Lookup(oid string) (*Node, string, error) {
+	var node *Node
+	var rest string
+
+	if i := strings.Index(oid, "::"); i != -1 {
+		var name string
+		name, rest = splitFirst(oid[i+2:])
+		node = t.nodes[oid[:i]][name]
+	} else {
+		var first string
+		first, rest = splitFirst(strings.TrimPrefix(oid, "."))
+		if subid, err := strconv.Atoi(first); err == nil {
+			node = t.root.children[subid]
+		} else {
+			node = t.names[first]
+		}
+	}
+	if node == nil {
+		return nil, "", ErrNotFound
+	}
+
+	var suffix []string
+	for _, c := range strings.Split(rest, ".") {
+		if c == "" {
+			continue
+		}
+		if len(suffix) > 0 {
+			if _, err := strconv.Atoi(c); err != nil {
+				return nil, "", ErrNotFound
+			}
+			suffix = append(suffix, c)
+			continue
+		}
+
+		var child *Node
+		if subid, err := strconv.Atoi(c); err == nil {
+			if child = node.children[subid]; child == nil {
+				suffix = append(suffix, c)
+				continue
+			}
+		} else {
+			for _, n := range node.children {
+				if n.Name == c {
+					child = n
+					break
+				}
+			}
+			if child == nil {
+				return nil, "", ErrNotFound
+			}
+		}
+		node = child
+	}
+
+	// Nodes only created as intermediates of an OID are not named.
+	for node.Name == "" && node.parent != nil {
+		suffix = append([]string{strconv.Itoa(node.subid)}, suffix...)
+		node = node.parent
+	}
+	if node.Module == "" {
+		return nil, "", ErrNotFound
+	}
+
+	if len(suffix) == 0 {
+		return node, "", nil
+	}
+	if !node.hasInstances() {
+		return nil, "", ErrNotFound
+	}
+	return node, "." + strings.Join(suffix, "."), nil
+}
+
+func splitFirst(s string) (string, string) {
+	if i := strings.Index(s, "."); i != -1 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
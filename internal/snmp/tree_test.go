@@ -0,0 +1,153 @@
+package snmp
+
+import (
+	"testing"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestTree(t *testing.T) *Tree {
+	tree, err := LoadTree([]string{"testdata", "testdata/missing"}, testutil.Logger{})
+	require.NoError(t, err)
+	return tree
+}
+
+func TestLookup(t *testing.T) {
+	tree := loadTestTree(t)
+
+	tests := []struct {
+		oid    string
+		module string
+		name   string
+		num    string
+		suffix string
+	}{
+		{
+			oid:    "TELEGRAF-TEST-MIB::testHostname.0",
+			module: "TELEGRAF-TEST-MIB",
+			name:   "testHostname",
+			num:    ".1.3.6.1.4.1.99999.1.1",
+			suffix: ".0",
+		},
+		{
+			oid:    "testPortName",
+			module: "TELEGRAF-TEST-MIB",
+			name:   "testPortName",
+			num:    ".1.3.6.1.4.1.99999.1.2.1.2",
+		},
+		{
+			oid:    ".1.3.6.1.4.1.99999.1.2.1.3.7",
+			module: "TELEGRAF-TEST-MIB",
+			name:   "testPortAddress",
+			num:    ".1.3.6.1.4.1.99999.1.2.1.3",
+			suffix: ".7",
+		},
+		{
+			oid:    ".iso.org.dod.internet.mgmt.mib-2",
+			module: "SNMPv2-SMI",
+			name:   "mib-2",
+			num:    ".1.3.6.1.2.1",
+		},
+		{
+			oid:    ".1.3.6.1.4.1.99998.0.3",
+			module: "TELEGRAF-TEST-V1-MIB",
+			name:   "testV1Trap",
+			num:    ".1.3.6.1.4.1.99998.0.3",
+		},
+		{
+			oid:    "SNMPv2-SMI::zeroDotZero",
+			module: "SNMPv2-SMI",
+			name:   "zeroDotZero",
+			num:    ".0.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.oid, func(t *testing.T) {
+			node, suffix, err := tree.Lookup(tt.oid)
+			require.NoError(t, err)
+			require.Equal(t, tt.module, node.Module)
+			require.Equal(t, tt.name, node.Name)
+			require.Equal(t, tt.num, node.Oid)
+			require.Equal(t, tt.suffix, suffix)
+		})
+	}
+}
+
+func TestLookupNotFound(t *testing.T) {
+	tree := loadTestTree(t)
+
+	for _, oid := range []string{
+		".999",
+		".1.2.3",
+		"TELEGRAF-TEST-MIB::unknown",
+		"UNKNOWN-MIB::testHostname",
+		"unknown",
+		"testObjects.unknown",
+		"1.3.6.1.4.1.99999.5.6",
+		"TELEGRAF-TEST-MIB::testPortTable.7",
+		".1.3.6.1.4.1.99999.1.2.1.99.1",
+		"BROKEN-MIB::brokenObject",
+	} {
+		_, _, err := tree.Lookup(oid)
+		require.Equal(t, ErrNotFound, err, oid)
+	}
+}
+
+func TestTypes(t *testing.T) {
+	tree := loadTestTree(t)
+
+	node, _, err := tree.Lookup("TELEGRAF-TEST-MIB::testPortAddress")
+	require.NoError(t, err)
+	require.Equal(t, "TestHwAddress", node.Syntax)
+	require.Equal(t, []string{"TestHwAddress", "PhysAddress"}, node.Types)
+	require.Equal(t, "read-only", node.Access)
+
+	node, _, err = tree.Lookup("TELEGRAF-TEST-MIB::testPortPackets")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Counter32"}, node.Types)
+
+	node, _, err = tree.Lookup("TELEGRAF-TEST-V1-MIB::testV1Counter")
+	require.NoError(t, err)
+	require.Equal(t, "INTEGER", node.Syntax)
+	require.Empty(t, node.Types)
+}
+
+func TestColumns(t *testing.T) {
+	tree := loadTestTree(t)
+
+	table, _, err := tree.Lookup("TELEGRAF-TEST-MIB::testPortTable")
+	require.NoError(t, err)
+	require.True(t, table.IsTable())
+	require.Equal(t, []string{"testPortIndex", "testPortName"}, table.Row().Index)
+
+	columns, err := table.Columns()
+	require.NoError(t, err)
+	var names []string
+	for _, column := range columns {
+		names = append(names, column.Name)
+	}
+	require.Equal(t, []string{"testPortName", "testPortAddress", "testPortEnabled", "testPortPackets"}, names)
+
+	table, _, err = tree.Lookup("TELEGRAF-TEST-MIB::testPortExtTable")
+	require.NoError(t, err)
+	require.Equal(t, []string{"testPortIndex", "testPortName"}, table.Row().Index)
+
+	node, _, err := tree.Lookup("TELEGRAF-TEST-MIB::testHostname")
+	require.NoError(t, err)
+	require.False(t, node.IsTable())
+	_, err = node.Columns()
+	require.Error(t, err)
+}
+
+func TestParseComments(t *testing.T) {
+	modules, err := parseModules([]byte(`
+A-MIB DEFINITIONS ::= BEGIN
+-- a comment -- a OBJECT IDENTIFIER ::= { iso 1 } -- a comment ending the line
+b OBJECT IDENTIFIER ::= { a--inline--2 }
+END`))
+	require.NoError(t, err)
+	require.Len(t, modules, 1)
+	require.Len(t, modules[0].objects, 2)
+	require.Equal(t, []oidComponent{{name: "a"}, {number: 2, hasNumber: true}}, modules[0].objects[1].oid)
+}
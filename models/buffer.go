@@ -188,6 +188,41 @@ func (b *Buffer) Accept(batch []telegraf.Metric) {
 	b.BufferSize.Set(int64(b.length()))
 }
 
+// Drop discards the batch, acquired from Batch(), without writing it.
+func (b *Buffer) Drop(batch []telegraf.Metric) {
+	b.Lock()
+	defer b.Unlock()
+
+	for _, m := range batch {
+		b.metricDropped(m)
+	}
+
+	b.resetBatch()
+	b.BufferSize.Set(int64(b.length()))
+}
+
+// Settle completes a batch, acquired from Batch(), which was partially
+// written: the written metrics are accepted, the dropped metrics discarded and
+// the rejected metrics returned to the buffer.  Each slice must keep the order
+// of the batch.
+func (b *Buffer) Settle(written, dropped, rejected []telegraf.Metric) {
+	b.Lock()
+	defer b.Unlock()
+
+	for _, m := range written {
+		b.metricWritten(m)
+	}
+	for _, m := range dropped {
+		b.metricDropped(m)
+	}
+	if len(rejected) > 0 {
+		b.reject(rejected)
+	}
+
+	b.resetBatch()
+	b.BufferSize.Set(int64(b.length()))
+}
+
 // Reject returns the batch, acquired from Batch(), to the buffer and marks it
 // as unsent.
 func (b *Buffer) Reject(batch []telegraf.Metric) {
@@ -198,6 +233,13 @@ func (b *Buffer) Reject(batch []telegraf.Metric) {
 		return
 	}
 
+	b.reject(batch)
+
+	b.resetBatch()
+	b.BufferSize.Set(int64(b.length()))
+}
+
+func (b *Buffer) reject(batch []telegraf.Metric) {
 	older := b.dist(b.first, b.batchFirst)
 	free := b.cap - b.size
 	restore := min(len(batch), free+older)
@@ -232,9 +274,6 @@ func (b *Buffer) Reject(batch []telegraf.Metric) {
 			b.metricDropped(batch[i])
 		}
 	}
-
-	b.resetBatch()
-	b.BufferSize.Set(int64(b.length()))
 }
 
 // dist returns the distance between two indexes.  Because this data structure
@@ -359,6 +359,30 @@ func TestBuffer_RejectPartialRoom(t *testing.T) {
 		}, batch)
 }
 
+func TestBuffer_Settle(t *testing.T) {
+	b := setup(NewBuffer("test", "", 5))
+	b.Add(MetricTime(1))
+	b.Add(MetricTime(2))
+	b.Add(MetricTime(3))
+	b.Add(MetricTime(4))
+	batch := b.Batch(4)
+
+	b.Add(MetricTime(5))
+	b.Settle(batch[0:1], batch[1:2], batch[2:4])
+
+	require.Equal(t, int64(1), b.MetricsWritten.Get())
+	require.Equal(t, int64(1), b.MetricsDropped.Get())
+	require.Equal(t, 3, b.Len())
+
+	batch = b.Batch(5)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{
+			MetricTime(5),
+			MetricTime(2),
+			MetricTime(1),
+		}, batch)
+}
+
 func TestBuffer_RejectNewMetricsWrapped(t *testing.T) {
 	b := setup(NewBuffer("test", "", 5))
 	b.Add(MetricTime(1))
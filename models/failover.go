@@ -0,0 +1,86 @@
+package models
+
+import (
+	"log"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	// Default number of consecutive failed writes before a failover group
+	// switches away from an output.
+	DEFAULT_FAILOVER_THRESHOLD = 3
+)
+
+// FailoverGroup routes metrics to the first healthy output of a prioritized
+// list of outputs.  An output is unhealthy once it failed to write
+// FailoverThreshold times in a row and becomes healthy again with its next
+// successful write, switching the group back to it.
+type FailoverGroup struct {
+	Name    string
+	Outputs []*RunningOutput
+
+	sync.Mutex
+	active int
+}
+
+// NewFailoverGroup returns an empty failover group.
+func NewFailoverGroup(name string) *FailoverGroup {
+	return &FailoverGroup{
+		Name: name,
+	}
+}
+
+// Add appends an output to the group with a lower priority than the outputs
+// already in the group.
+func (g *FailoverGroup) Add(output *RunningOutput) {
+	g.Lock()
+	defer g.Unlock()
+	g.Outputs = append(g.Outputs, output)
+}
+
+// Active returns the output currently receiving the metrics of the group.
+func (g *FailoverGroup) Active() *RunningOutput {
+	g.Lock()
+	defer g.Unlock()
+	return g.Outputs[g.selectActive()]
+}
+
+// AddMetric adds the metric to the active output.  Higher priority outputs
+// with failover_replay enabled keep a copy of the metric, which is written
+// once the output recovers.
+//
+// Takes ownership of metric
+func (g *FailoverGroup) AddMetric(metric telegraf.Metric) {
+	g.Lock()
+	active := g.selectActive()
+	g.Unlock()
+
+	for _, output := range g.Outputs[:active] {
+		if output.Config.FailoverReplay {
+			output.AddMetric(metric.Copy())
+		}
+	}
+	g.Outputs[active].AddMetric(metric)
+}
+
+// selectActive returns the index of the first healthy output, logging when
+// the group switches between outputs.  When every output is unhealthy the
+// metrics are kept by the primary output.
+func (g *FailoverGroup) selectActive() int {
+	active := 0
+	for i, output := range g.Outputs {
+		if !output.failedOver() {
+			active = i
+			break
+		}
+	}
+
+	if active != g.active {
+		log.Printf("I! [agent] Failover group %q switched from %s to %s",
+			g.Name, g.Outputs[g.active].LogName(), g.Outputs[active].LogName())
+		g.active = active
+	}
+	return active
+}
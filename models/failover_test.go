@@ -0,0 +1,89 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newFailoverOutput(name string, threshold int, replay bool) (*RunningOutput, *mockOutput) {
+	m := &mockOutput{}
+	conf := &OutputConfig{
+		Name:              name,
+		Filter:            Filter{},
+		FailoverGroup:     "test",
+		FailoverThreshold: threshold,
+		FailoverReplay:    replay,
+	}
+	return NewRunningOutput(name, m, conf, 5, 10), m
+}
+
+func TestFailoverGroupSwitch(t *testing.T) {
+	primary, pm := newFailoverOutput("primary", 2, false)
+	secondary, sm := newFailoverOutput("secondary", 2, false)
+	g := NewFailoverGroup("test")
+	g.Add(primary)
+	g.Add(secondary)
+	require.Equal(t, primary, g.Active())
+
+	pm.failWrite = true
+	g.AddMetric(first5[0])
+	require.Error(t, primary.Write())
+	require.Equal(t, primary, g.Active())
+	require.Error(t, primary.Write())
+	require.Equal(t, secondary, g.Active())
+
+	for _, metric := range first5[1:] {
+		g.AddMetric(metric)
+	}
+	require.NoError(t, secondary.Write())
+	require.Len(t, sm.Metrics(), 4)
+
+	// The primary retries its own buffer and takes over again once it
+	// recovers.
+	pm.failWrite = false
+	require.NoError(t, primary.Write())
+	require.Len(t, pm.Metrics(), 1)
+	require.Equal(t, primary, g.Active())
+}
+
+func TestFailoverGroupReplay(t *testing.T) {
+	primary, pm := newFailoverOutput("primary", 1, true)
+	secondary, sm := newFailoverOutput("secondary", 1, false)
+	g := NewFailoverGroup("test")
+	g.Add(primary)
+	g.Add(secondary)
+
+	pm.failWrite = true
+	g.AddMetric(first5[0])
+	require.Error(t, primary.Write())
+	require.Equal(t, secondary, g.Active())
+
+	for _, metric := range first5[1:] {
+		g.AddMetric(metric)
+	}
+	require.NoError(t, secondary.Write())
+	require.Len(t, sm.Metrics(), 4)
+
+	pm.failWrite = false
+	require.NoError(t, primary.Write())
+	require.Len(t, pm.Metrics(), 5)
+	require.Equal(t, primary, g.Active())
+}
+
+func TestFailoverGroupAllFailed(t *testing.T) {
+	primary, pm := newFailoverOutput("primary", 1, false)
+	secondary, sm := newFailoverOutput("secondary", 1, false)
+	g := NewFailoverGroup("test")
+	g.Add(primary)
+	g.Add(secondary)
+
+	pm.failWrite = true
+	sm.failWrite = true
+	g.AddMetric(first5[0])
+	require.Error(t, primary.Write())
+	g.AddMetric(first5[1])
+	require.Error(t, secondary.Write())
+
+	require.Equal(t, primary, g.Active())
+}
@@ -0,0 +1,181 @@
+package models
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// RetryConfig controls how writes to a failing output are retried.
+type RetryConfig struct {
+	// InitialBackoff is the delay after the first failed write; zero
+	// disables the backoff and writes are retried every flush.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries.
+	MaxBackoff time.Duration
+	// Multiplier increases the delay after each consecutive failure.
+	Multiplier float64
+	// Jitter randomizes the delay by up to this fraction in either direction.
+	Jitter float64
+
+	// CircuitBreakerThreshold is the number of consecutive failures opening
+	// the circuit breaker; zero disables it.
+	CircuitBreakerThreshold int
+	// CircuitBreakerTimeout is how long the circuit stays open before a
+	// single batch is written to probe the output.
+	CircuitBreakerTimeout time.Duration
+}
+
+const (
+	defaultMaxBackoff            = 5 * time.Minute
+	defaultBackoffMultiplier     = 2.0
+	defaultCircuitBreakerTimeout = time.Minute
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// retryState tracks the consecutive failures of an output.
+type retryState struct {
+	sync.Mutex
+	config RetryConfig
+	log    telegraf.Logger
+
+	failures    int
+	nextAttempt time.Time
+	circuit     circuitState
+}
+
+func newRetryState(config RetryConfig, log telegraf.Logger) *retryState {
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = defaultMaxBackoff
+	}
+	if config.Multiplier < 1 {
+		config.Multiplier = defaultBackoffMultiplier
+	}
+	if config.CircuitBreakerTimeout == 0 {
+		config.CircuitBreakerTimeout = defaultCircuitBreakerTimeout
+	}
+	return &retryState{config: config, log: log}
+}
+
+// allow returns the number of batches that may be written now: none while
+// backing off or while the circuit is open, one to probe a half open circuit
+// and -1 for no limit.
+func (r *retryState) allow(now time.Time) int {
+	r.Lock()
+	defer r.Unlock()
+
+	if now.Before(r.nextAttempt) {
+		return 0
+	}
+
+	switch r.circuit {
+	case circuitOpen, circuitHalfOpen:
+		r.circuit = circuitHalfOpen
+		return 1
+	default:
+		return -1
+	}
+}
+
+// success resets the failures after a successful write.
+func (r *retryState) success() {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.circuit != circuitClosed {
+		r.log.Infof("Circuit breaker closed, output recovered")
+	}
+	r.failures = 0
+	r.nextAttempt = time.Time{}
+	r.circuit = circuitClosed
+}
+
+// failure delays the next write after a retryable error.
+func (r *retryState) failure(now time.Time) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.failures++
+
+	threshold := r.config.CircuitBreakerThreshold
+	if r.circuit == circuitHalfOpen || (threshold > 0 && r.failures >= threshold) {
+		if r.circuit == circuitClosed {
+			r.log.Warnf("Circuit breaker opened after %d consecutive failures, pausing writes for %s",
+				r.failures, r.config.CircuitBreakerTimeout)
+		}
+		r.circuit = circuitOpen
+		r.nextAttempt = now.Add(r.config.CircuitBreakerTimeout)
+		return
+	}
+
+	if r.config.InitialBackoff > 0 {
+		r.nextAttempt = now.Add(r.backoff())
+	}
+}
+
+// consecutiveFailures returns the number of writes failed since the last
+// success.
+func (r *retryState) consecutiveFailures() int {
+	r.Lock()
+	defer r.Unlock()
+	return r.failures
+}
+
+// backoff returns the delay before the next retry.
+func (r *retryState) backoff() time.Duration {
+	delay := float64(r.config.InitialBackoff) * math.Pow(r.config.Multiplier, float64(r.failures-1))
+	if delay > float64(r.config.MaxBackoff) {
+		delay = float64(r.config.MaxBackoff)
+	}
+	if r.config.Jitter > 0 {
+		delay *= 1 + r.config.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(delay)
+}
+
+// isPermanent reports whether the error, or an error it wraps, is a
+// telegraf.PermanentError.
+func isPermanent(err error) bool {
+	return findError(err, func(err error) bool {
+		_, ok := err.(*telegraf.PermanentError)
+		return ok
+	}) != nil
+}
+
+// partialWriteError returns the telegraf.PartialWriteError the error is or
+// wraps, if any.
+func partialWriteError(err error) *telegraf.PartialWriteError {
+	if err := findError(err, func(err error) bool {
+		_, ok := err.(*telegraf.PartialWriteError)
+		return ok
+	}); err != nil {
+		return err.(*telegraf.PartialWriteError)
+	}
+	return nil
+}
+
+// findError returns the first error in the chain of wrapped errors that
+// matches.
+func findError(err error, match func(error) bool) error {
+	for err != nil {
+		if match(err) {
+			return err
+		}
+		wrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return nil
+		}
+		err = wrapper.Unwrap()
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type wrappedError struct {
+	err error
+}
+
+func (e *wrappedError) Error() string {
+	return fmt.Sprintf("wrapped: %v", e.err)
+}
+
+func (e *wrappedError) Unwrap() error {
+	return e.err
+}
+
+func TestRetryStateDisabled(t *testing.T) {
+	r := newRetryState(RetryConfig{}, testutil.Logger{})
+	now := time.Unix(0, 0)
+
+	for i := 0; i < 10; i++ {
+		r.failure(now)
+		require.Equal(t, -1, r.allow(now))
+	}
+}
+
+func TestRetryStateBackoff(t *testing.T) {
+	r := newRetryState(RetryConfig{
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Second,
+	}, testutil.Logger{})
+	now := time.Unix(0, 0)
+
+	expected := []time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		5 * time.Second,
+		5 * time.Second,
+	}
+	for _, delay := range expected {
+		r.failure(now)
+		require.Equal(t, 0, r.allow(now.Add(delay-1)))
+		require.Equal(t, -1, r.allow(now.Add(delay)))
+	}
+
+	r.success()
+	r.failure(now)
+	require.Equal(t, -1, r.allow(now.Add(time.Second)))
+}
+
+func TestRetryStateJitter(t *testing.T) {
+	r := newRetryState(RetryConfig{
+		InitialBackoff: 10 * time.Second,
+		Jitter:         0.5,
+	}, testutil.Logger{})
+
+	r.failures = 1
+	for i := 0; i < 100; i++ {
+		delay := r.backoff()
+		require.True(t, delay >= 5*time.Second && delay <= 15*time.Second, delay)
+	}
+}
+
+func TestRetryStateCircuitBreaker(t *testing.T) {
+	r := newRetryState(RetryConfig{
+		CircuitBreakerThreshold: 3,
+		CircuitBreakerTimeout:   time.Minute,
+	}, testutil.Logger{})
+	now := time.Unix(0, 0)
+
+	r.failure(now)
+	r.failure(now)
+	require.Equal(t, -1, r.allow(now))
+
+	r.failure(now)
+	require.Equal(t, 0, r.allow(now.Add(59*time.Second)))
+
+	// Once the timeout has passed a single batch probes the output.
+	now = now.Add(time.Minute)
+	require.Equal(t, 1, r.allow(now))
+
+	// A failed probe opens the circuit again.
+	r.failure(now)
+	require.Equal(t, 0, r.allow(now.Add(59*time.Second)))
+
+	now = now.Add(time.Minute)
+	require.Equal(t, 1, r.allow(now))
+	r.success()
+	require.Equal(t, -1, r.allow(now))
+}
+
+func TestIsPermanent(t *testing.T) {
+	permanent := &telegraf.PermanentError{Err: errors.New("malformed")}
+
+	require.True(t, isPermanent(permanent))
+	require.True(t, isPermanent(&wrappedError{err: permanent}))
+	require.False(t, isPermanent(errors.New("timeout")))
+	require.False(t, isPermanent(&wrappedError{err: errors.New("timeout")}))
+	require.False(t, isPermanent(nil))
+}
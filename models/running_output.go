@@ -31,6 +31,12 @@ type OutputConfig struct {
 	NameOverride string
 	NamePrefix   string
 	NameSuffix   string
+
+	Retry RetryConfig
+
+	FailoverGroup     string
+	FailoverThreshold int
+	FailoverReplay    bool
 }
 
 // RunningOutput contains the output configuration
@@ -50,6 +56,7 @@ type RunningOutput struct {
 	BatchReady chan time.Time
 
 	buffer *Buffer
+	retry  *retryState
 	log    telegraf.Logger
 
 	aggMutex sync.Mutex
@@ -104,7 +111,8 @@ func NewRunningOutput(
 			"write_time_ns",
 			tags,
 		),
-		log: logger,
+		retry: newRetryState(config.Retry, logger),
+		log:   logger,
 	}
 
 	return ro
@@ -178,8 +186,34 @@ func (ro *RunningOutput) AddMetric(metric telegraf.Metric) {
 }
 
 // Write writes all metrics to the output, stopping when all have been sent on
-// or error.
+// or error.  Nothing is written while waiting to retry after a failure.
 func (ro *RunningOutput) Write() error {
+	ro.pushAggregate()
+
+	allowed := ro.retry.allow(time.Now())
+	if allowed == 0 {
+		ro.log.Debugf("Waiting to retry, skipping write of %d buffered metrics", ro.buffer.Len())
+		return nil
+	}
+
+	return ro.writeBuffered(allowed)
+}
+
+// WriteFinal writes all metrics to the output ignoring the retry backoff and
+// the circuit breaker.  It is the last write before the output is closed, any
+// metrics it fails to write are lost.
+func (ro *RunningOutput) WriteFinal() error {
+	ro.pushAggregate()
+
+	err := ro.writeBuffered(-1)
+	if n := ro.buffer.Len(); err != nil && n > 0 {
+		ro.log.Errorf("Final write failed, dropping %d buffered metrics", n)
+	}
+	return err
+}
+
+// pushAggregate moves the metrics of an aggregating output to the buffer.
+func (ro *RunningOutput) pushAggregate() {
 	if output, ok := ro.Output.(telegraf.AggregatingOutput); ok {
 		ro.aggMutex.Lock()
 		metrics := output.Push()
@@ -189,41 +223,115 @@ func (ro *RunningOutput) Write() error {
 	}
 
 	atomic.StoreInt64(&ro.newMetricsCount, 0)
+}
 
+// writeBuffered writes the metrics in the buffer in batches, at most
+// maxBatches of them unless it is negative.
+func (ro *RunningOutput) writeBuffered(maxBatches int) error {
 	// Only process the metrics in the buffer now.  Metrics added while we are
 	// writing will be sent on the next call.
 	nBuffer := ro.buffer.Len()
 	nBatches := nBuffer/ro.MetricBatchSize + 1
+	if maxBatches >= 0 && maxBatches < nBatches {
+		nBatches = maxBatches
+	}
 	for i := 0; i < nBatches; i++ {
 		batch := ro.buffer.Batch(ro.MetricBatchSize)
 		if len(batch) == 0 {
 			break
 		}
 
-		err := ro.write(batch)
+		err := ro.writeBatch(batch)
 		if err != nil {
-			ro.buffer.Reject(batch)
 			return err
 		}
-		ro.buffer.Accept(batch)
 	}
 	return nil
 }
 
 // WriteBatch writes a single batch of metrics to the output.
 func (ro *RunningOutput) WriteBatch() error {
+	if ro.retry.allow(time.Now()) == 0 {
+		return nil
+	}
+
 	batch := ro.buffer.Batch(ro.MetricBatchSize)
 	if len(batch) == 0 {
 		return nil
 	}
 
+	return ro.writeBatch(batch)
+}
+
+// writeBatch writes the batch and settles it in the buffer: written batches
+// are accepted, batches failing with a permanent error are dropped and other
+// failures return the batch to the buffer to be retried.  Partially written
+// batches are settled per metric.
+func (ro *RunningOutput) writeBatch(batch []telegraf.Metric) error {
 	err := ro.write(batch)
-	if err != nil {
+	if perr := partialWriteError(err); perr != nil {
+		return ro.settlePartial(batch, perr)
+	}
+
+	switch {
+	case err == nil:
+		ro.buffer.Accept(batch)
+		ro.retry.success()
+		return nil
+	case isPermanent(err):
+		ro.buffer.Drop(batch)
+		ro.retry.success()
+		ro.log.Errorf("Dropped batch of %d metrics: %v", len(batch), err)
+		return nil
+	default:
 		ro.buffer.Reject(batch)
+		ro.retry.failure(time.Now())
 		return err
 	}
-	ro.buffer.Accept(batch)
+}
+
+// settlePartial settles the batch according to the outcome reported for each
+// metric.
+func (ro *RunningOutput) settlePartial(batch []telegraf.Metric, perr *telegraf.PartialWriteError) error {
+	const (
+		written = iota
+		dropped
+		retried
+	)
+
+	status := make([]int, len(batch))
+	for _, i := range perr.MetricsReject {
+		if i >= 0 && i < len(batch) {
+			status[i] = dropped
+		}
+	}
+	for _, i := range perr.MetricsRetry {
+		if i >= 0 && i < len(batch) {
+			status[i] = retried
+		}
+	}
+
+	var accept, drop, reject []telegraf.Metric
+	for i, metric := range batch {
+		switch status[i] {
+		case written:
+			accept = append(accept, metric)
+		case dropped:
+			drop = append(drop, metric)
+		case retried:
+			reject = append(reject, metric)
+		}
+	}
+	ro.buffer.Settle(accept, drop, reject)
 
+	if len(drop) > 0 {
+		ro.log.Errorf("Dropped %d of %d metrics in batch: %v", len(drop), len(batch), perr)
+	}
+	if len(reject) > 0 {
+		ro.retry.failure(time.Now())
+		return perr
+	}
+	ro.retry.success()
 	return nil
 }
 
@@ -253,6 +361,16 @@ func (r *RunningOutput) write(metrics []telegraf.Metric) error {
 	return err
 }
 
+// failedOver reports whether a failover group should route metrics past this
+// output.
+func (r *RunningOutput) failedOver() bool {
+	threshold := r.Config.FailoverThreshold
+	if threshold <= 0 {
+		threshold = DEFAULT_FAILOVER_THRESHOLD
+	}
+	return r.retry.consecutiveFailures() >= threshold
+}
+
 func (r *RunningOutput) LogBufferStatus() {
 	nBuffer := r.buffer.Len()
 	r.log.Debugf("Buffer fullness: %d / %d metrics", nBuffer, r.MetricBufferLimit)
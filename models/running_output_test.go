@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
@@ -326,6 +327,115 @@ func TestRunningOutputWriteFail(t *testing.T) {
 	assert.Len(t, m.Metrics(), 10)
 }
 
+// Verify that a batch is dropped when the output reports a permanent error.
+func TestRunningOutputWritePermanentError(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{}
+	m.writeErr = &telegraf.PermanentError{Err: errors.New("malformed batch")}
+	ro := NewRunningOutput("test", m, conf, 5, 10)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	err := ro.Write()
+	require.NoError(t, err)
+	assert.Equal(t, 0, ro.buffer.Len())
+
+	m.writeErr = nil
+	for _, metric := range next5 {
+		ro.AddMetric(metric)
+	}
+	err = ro.Write()
+	require.NoError(t, err)
+	assert.Equal(t, reverse(next5), m.Metrics())
+}
+
+// Verify that a partially written batch is settled per metric.
+func TestRunningOutputWritePartialError(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{}
+	m.writeErr = &telegraf.PartialWriteError{
+		Err:           errors.New("partial write"),
+		MetricsReject: []int{1},
+		MetricsRetry:  []int{3, 4},
+	}
+	ro := NewRunningOutput("test", m, conf, 5, 10)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	err := ro.Write()
+	require.Error(t, err)
+	assert.Equal(t, 2, ro.buffer.Len())
+
+	m.writeErr = nil
+	err = ro.Write()
+	require.NoError(t, err)
+	assert.Equal(t, []telegraf.Metric{first5[1], first5[0]}, m.Metrics())
+}
+
+// Verify that writes are skipped while backing off from a failure.
+func TestRunningOutputWriteBackoff(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+		Retry: RetryConfig{
+			InitialBackoff: time.Hour,
+		},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test", m, conf, 5, 10)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	err := ro.Write()
+	require.Error(t, err)
+
+	m.failWrite = false
+	err = ro.Write()
+	require.NoError(t, err)
+	assert.Len(t, m.Metrics(), 0)
+	assert.Equal(t, 5, ro.buffer.Len())
+}
+
+// Verify that the final write ignores the backoff.
+func TestRunningOutputWriteFinalIgnoresBackoff(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+		Retry: RetryConfig{
+			InitialBackoff: time.Hour,
+		},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test", m, conf, 5, 10)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	err := ro.Write()
+	require.Error(t, err)
+
+	m.failWrite = false
+	err = ro.WriteFinal()
+	require.NoError(t, err)
+	assert.Len(t, m.Metrics(), 5)
+	assert.Equal(t, 0, ro.buffer.Len())
+}
+
 // Verify that the order of points is preserved during a write failure.
 func TestRunningOutputWriteFailOrder(t *testing.T) {
 	conf := &OutputConfig{
@@ -519,6 +629,9 @@ type mockOutput struct {
 
 	// if true, mock a write failure
 	failWrite bool
+
+	// if set, returned by Write
+	writeErr error
 }
 
 func (m *mockOutput) Connect() error {
@@ -543,6 +656,9 @@ func (m *mockOutput) Write(metrics []telegraf.Metric) error {
 	if m.failWrite {
 		return fmt.Errorf("Failed Write!")
 	}
+	if m.writeErr != nil {
+		return m.writeErr
+	}
 
 	if m.metrics == nil {
 		m.metrics = []telegraf.Metric{}
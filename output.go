@@ -26,3 +26,38 @@ type AggregatingOutput interface {
 	// Reset signals the the aggregator period is completed.
 	Reset()
 }
+
+// PermanentError is returned by Output.Write when a batch can never be
+// written, for example because the endpoint rejected it as malformed.  The
+// batch is dropped instead of being retried.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// PartialWriteError is returned by Output.Write when only some metrics of a
+// batch were written.  Metrics not listed in MetricsReject or MetricsRetry
+// were written successfully.
+type PartialWriteError struct {
+	Err error
+	// MetricsReject are the indexes of the metrics which can never be
+	// written; they are dropped.
+	MetricsReject []int
+	// MetricsRetry are the indexes of the metrics to be written again.
+	MetricsRetry []int
+}
+
+func (e *PartialWriteError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PartialWriteError) Unwrap() error {
+	return e.Err
+}
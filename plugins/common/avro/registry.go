@@ -0,0 +1,175 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// magicByte prefixes each message in the schema registry wire format, it is
+// followed by the 4 byte big-endian schema ID and the Avro binary payload.
+const magicByte = 0x00
+
+const headerSize = 5
+
+// Schema is a parsed Avro schema along with its registry ID.
+type Schema struct {
+	ID    int
+	Codec *goavro.Codec
+	// Definition is the schema decoded from JSON, used to walk the schema
+	// alongside values.
+	Definition interface{}
+}
+
+// NewSchema parses an Avro schema definition.
+func NewSchema(id int, definition string) (*Schema, error) {
+	codec, err := goavro.NewCodec(definition)
+	if err != nil {
+		return nil, err
+	}
+
+	var def interface{}
+	if err := json.Unmarshal([]byte(definition), &def); err != nil {
+		return nil, err
+	}
+
+	return &Schema{ID: id, Codec: codec, Definition: def}, nil
+}
+
+// SchemaRegistry is a client for a Confluent compatible schema registry.
+// Schemas fetched by ID are cached for the lifetime of the client.
+type SchemaRegistry struct {
+	url    string
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[int]*Schema
+}
+
+func NewSchemaRegistry(registryURL string, timeout time.Duration) *SchemaRegistry {
+	return &SchemaRegistry{
+		url: registryURL,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+		cache: make(map[int]*Schema),
+	}
+}
+
+type schemaResponse struct {
+	ID     int    `json:"id"`
+	Schema string `json:"schema"`
+}
+
+// SchemaByID returns the schema with the given ID.
+func (r *SchemaRegistry) SchemaByID(id int) (*Schema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if schema, ok := r.cache[id]; ok {
+		return schema, nil
+	}
+
+	var resp schemaResponse
+	if err := r.do("GET", fmt.Sprintf("/schemas/ids/%d", id), nil, &resp); err != nil {
+		return nil, err
+	}
+
+	schema, err := NewSchema(id, resp.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("parsing schema %d: %v", id, err)
+	}
+	r.cache[id] = schema
+	return schema, nil
+}
+
+// LatestSchema returns the latest version of the schema registered under
+// subject.
+func (r *SchemaRegistry) LatestSchema(subject string) (*Schema, error) {
+	var resp schemaResponse
+	path := fmt.Sprintf("/subjects/%s/versions/latest", url.PathEscape(subject))
+	if err := r.do("GET", path, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	schema, err := NewSchema(resp.ID, resp.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("parsing schema %d: %v", resp.ID, err)
+	}
+
+	r.mu.Lock()
+	r.cache[resp.ID] = schema
+	r.mu.Unlock()
+	return schema, nil
+}
+
+// Register registers the schema definition under subject and returns its ID.
+// Registering a schema that already exists returns the existing ID.
+func (r *SchemaRegistry) Register(subject string, definition string) (int, error) {
+	body, err := json.Marshal(schemaResponse{Schema: definition})
+	if err != nil {
+		return 0, err
+	}
+
+	var resp schemaResponse
+	path := fmt.Sprintf("/subjects/%s/versions", url.PathEscape(subject))
+	if err := r.do("POST", path, body, &resp); err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+func (r *SchemaRegistry) do(method, path string, body []byte, v interface{}) error {
+	req, err := http.NewRequest(method, r.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	octets, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("schema registry %s %s returned status %s: %s",
+			method, path, resp.Status, bytes.TrimSpace(octets))
+	}
+	return json.Unmarshal(octets, v)
+}
+
+// EncodeWire prefixes an Avro binary payload with the wire format header.
+func EncodeWire(schemaID int, payload []byte) []byte {
+	buf := make([]byte, headerSize, headerSize+len(payload))
+	buf[0] = magicByte
+	binary.BigEndian.PutUint32(buf[1:], uint32(schemaID))
+	return append(buf, payload...)
+}
+
+// DecodeWire splits a wire format message into its schema ID and payload.
+func DecodeWire(buf []byte) (int, []byte, error) {
+	if len(buf) < headerSize {
+		return 0, nil, fmt.Errorf("message too short for schema header: %d bytes", len(buf))
+	}
+	if buf[0] != magicByte {
+		return 0, nil, fmt.Errorf("unknown magic byte 0x%02x", buf[0])
+	}
+	return int(binary.BigEndian.Uint32(buf[1:headerSize])), buf[headerSize:], nil
+}
@@ -0,0 +1,155 @@
+package avro
+
+import (
+	"strings"
+)
+
+// Field is a field of the top level record in a schema.
+type Field struct {
+	Name string
+	Type interface{}
+}
+
+// Name returns the name of the top level record, or an empty string if the
+// schema is not a record.
+func (s *Schema) Name() string {
+	if m, ok := s.Definition.(map[string]interface{}); ok {
+		name, _ := m["name"].(string)
+		return name
+	}
+	return ""
+}
+
+// Fields returns the fields of the top level record.
+func (s *Schema) Fields() []Field {
+	m, ok := s.Definition.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	list, _ := m["fields"].([]interface{})
+
+	fields := make([]Field, 0, len(list))
+	for _, item := range list {
+		f, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := f["name"].(string)
+		fields = append(fields, Field{Name: name, Type: f["type"]})
+	}
+	return fields
+}
+
+// Resolve replaces a reference to a named type with its definition.  Other
+// types are returned unchanged.
+func (s *Schema) Resolve(t interface{}) interface{} {
+	name, ok := t.(string)
+	if !ok || isPrimitive(name) {
+		return t
+	}
+
+	var found interface{}
+	walkNamed(s.Definition, "", func(fullName, shortName string, def map[string]interface{}) {
+		if found == nil && (name == fullName || name == shortName) {
+			found = def
+		}
+	})
+	if found == nil {
+		return t
+	}
+	return found
+}
+
+// TypeName returns the name used to identify a union member, matching the
+// keys of union values decoded by goavro.
+func TypeName(t interface{}) string {
+	switch t := t.(type) {
+	case string:
+		return t
+	case map[string]interface{}:
+		if name, ok := t["name"].(string); ok {
+			if ns, ok := t["namespace"].(string); ok && ns != "" && !strings.Contains(name, ".") {
+				return ns + "." + name
+			}
+			return name
+		}
+		typ, _ := t["type"].(string)
+		if logical, ok := t["logicalType"].(string); ok {
+			return typ + "." + logical
+		}
+		return typ
+	case []interface{}:
+		return "union"
+	}
+	return ""
+}
+
+// UnionMembers returns the non-null members of a union type, or the type
+// itself if it is not a union.
+func UnionMembers(t interface{}) []interface{} {
+	union, ok := t.([]interface{})
+	if !ok {
+		return []interface{}{t}
+	}
+
+	members := make([]interface{}, 0, len(union))
+	for _, member := range union {
+		if member == "null" {
+			continue
+		}
+		members = append(members, member)
+	}
+	return members
+}
+
+// IsUnion reports whether t is a union type.
+func IsUnion(t interface{}) bool {
+	_, ok := t.([]interface{})
+	return ok
+}
+
+func isPrimitive(name string) bool {
+	switch name {
+	case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+		return true
+	}
+	return false
+}
+
+// walkNamed calls fn for each named type (record, enum and fixed) defined in
+// the schema.
+func walkNamed(t interface{}, namespace string, fn func(fullName, shortName string, def map[string]interface{})) {
+	switch t := t.(type) {
+	case []interface{}:
+		for _, member := range t {
+			walkNamed(member, namespace, fn)
+		}
+	case map[string]interface{}:
+		if ns, ok := t["namespace"].(string); ok {
+			namespace = ns
+		}
+		if name, ok := t["name"].(string); ok {
+			short := name
+			full := name
+			if i := strings.LastIndex(name, "."); i >= 0 {
+				short = name[i+1:]
+			} else if namespace != "" {
+				full = namespace + "." + name
+			}
+			fn(full, short, t)
+		}
+		if fields, ok := t["fields"].([]interface{}); ok {
+			for _, f := range fields {
+				if f, ok := f.(map[string]interface{}); ok {
+					walkNamed(f["type"], namespace, fn)
+				}
+			}
+		}
+		if items, ok := t["items"]; ok {
+			walkNamed(items, namespace, fn)
+		}
+		if values, ok := t["values"]; ok {
+			walkNamed(values, namespace, fn)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package mqtt
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// ProtocolV311 is the MQTT 3.1.1 protocol, used by default
+	ProtocolV311 = "3.1.1"
+	// ProtocolV5 is the MQTT 5 protocol
+	ProtocolV5 = "5"
+)
+
+// ValidateProtocolVersion checks the protocol_version option of the plugins.
+func ValidateProtocolVersion(version string) error {
+	switch version {
+	case "", ProtocolV311, ProtocolV5:
+		return nil
+	default:
+		return fmt.Errorf("invalid protocol_version %q, must be %q or %q", version, ProtocolV311, ProtocolV5)
+	}
+}
+
+// Dial connects to the first reachable server, given as scheme://host:port
+// with the scheme tcp, mqtt, ssl, tls or mqtts.  The MQTT 5 client does not
+// handle the network connection itself, unlike the MQTT 3.1.1 client.
+func Dial(servers []*url.URL, tlsCfg *tls.Config, timeout time.Duration) (net.Conn, error) {
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("could not get host informations")
+	}
+
+	var errs []string
+	for _, server := range servers {
+		conn, err := dial(server, tlsCfg, timeout)
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, fmt.Errorf("connecting to %s", strings.Join(errs, ", "))
+}
+
+func dial(server *url.URL, tlsCfg *tls.Config, timeout time.Duration) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+
+	switch server.Scheme {
+	case "tcp", "mqtt":
+		return dialer.Dial("tcp", server.Host)
+	case "ssl", "tls", "mqtts", "tcps":
+		if tlsCfg == nil {
+			tlsCfg = &tls.Config{}
+		}
+		return tls.DialWithDialer(dialer, "tcp", server.Host, tlsCfg)
+	default:
+		return nil, fmt.Errorf("%s: scheme %q is not supported with MQTT 5", server, server.Scheme)
+	}
+}
@@ -0,0 +1,46 @@
+package template
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// TemplateMetric exposes a read-only view of a metric to Go templates.
+type TemplateMetric struct {
+	metric telegraf.Metric
+}
+
+func NewTemplateMetric(metric telegraf.Metric) *TemplateMetric {
+	return &TemplateMetric{metric: metric}
+}
+
+func (m *TemplateMetric) Name() string {
+	return m.metric.Name()
+}
+
+func (m *TemplateMetric) Tag(key string) string {
+	tagString, _ := m.metric.GetTag(key)
+	return tagString
+}
+
+func (m *TemplateMetric) Field(key string) interface{} {
+	field, _ := m.metric.GetField(key)
+	return field
+}
+
+func (m *TemplateMetric) Time() time.Time {
+	return m.metric.Time()
+}
+
+// Tags returns a copy of the metric tags; ranging over the result visits the
+// keys in sorted order.
+func (m *TemplateMetric) Tags() map[string]string {
+	return m.metric.Tags()
+}
+
+// Fields returns a copy of the metric fields; ranging over the result visits
+// the keys in sorted order.
+func (m *TemplateMetric) Fields() map[string]interface{} {
+	return m.metric.Fields()
+}
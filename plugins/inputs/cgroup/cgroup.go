@@ -1,13 +1,16 @@
 package cgroup
 
 import (
+	"fmt"
+
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
 type CGroup struct {
-	Paths []string `toml:"paths"`
-	Files []string `toml:"files"`
+	Paths   []string `toml:"paths"`
+	Files   []string `toml:"files"`
+	Version string   `toml:"version"`
 }
 
 var sampleConfig = `
@@ -23,6 +26,13 @@ var sampleConfig = `
   ## cgroup stat fields, as file names, globs are supported.
   ## these file names are appended to each path from above.
   # files = ["memory.*usage*", "memory.limit_in_bytes"]
+
+  ## cgroup hierarchy of the paths: "v1", "v2" (unified hierarchy), or
+  ## "auto" to detect it from the cgroup.controllers file of each directory.
+  ## The files of v2 directories default to cpu.stat, memory.stat,
+  ## memory.events, memory.current, memory.swap.current, io.stat and
+  ## pids.current.
+  # version = "auto"
 `
 
 func (g *CGroup) SampleConfig() string {
@@ -33,6 +43,17 @@ func (g *CGroup) Description() string {
 	return "Read specific statistics per cgroup"
 }
 
+func (g *CGroup) Init() error {
+	switch g.Version {
+	case "":
+		g.Version = "auto"
+	case "auto", "v1", "v2":
+	default:
+		return fmt.Errorf("invalid version %q", g.Version)
+	}
+	return nil
+}
+
 func init() {
 	inputs.Add("cgroup", func() telegraf.Input { return &CGroup{} })
 }
@@ -34,10 +34,14 @@ func (g *CGroup) Gather(acc telegraf.Accumulator) error {
 }
 
 func (g *CGroup) gatherDir(dir string, acc telegraf.Accumulator) error {
+	if g.isV2(dir) {
+		return g.gatherDirV2(dir, acc)
+	}
+
 	fields := make(map[string]interface{})
 
 	list := make(chan pathInfo)
-	go g.generateFiles(dir, list)
+	go generateFiles(dir, g.Files, list)
 
 	for file := range list {
 		if file.err != nil {
@@ -104,9 +108,9 @@ func (g *CGroup) generateDirs(list chan<- pathInfo) {
 	}
 }
 
-func (g *CGroup) generateFiles(dir string, list chan<- pathInfo) {
+func generateFiles(dir string, files []string, list chan<- pathInfo) {
 	defer close(list)
-	for _, file := range g.Files {
+	for _, file := range files {
 		// getting all file paths that match the pattern 'dir + file'
 		// path.Base make sure that file variable does not contains part of path
 		items, err := filepath.Glob(path.Join(dir, path.Base(file)))
@@ -4,7 +4,9 @@ package cgroup
 
 import (
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -180,3 +182,127 @@ func TestCgroupStatistics_6(t *testing.T) {
 	}
 	acc.AssertContainsTaggedFields(t, "cgroup", fields, tags)
 }
+
+// ======================================================================
+
+func TestCgroupV2(t *testing.T) {
+	cg := &CGroup{
+		Paths: []string{"testdata/v2/*"},
+	}
+	require.NoError(t, cg.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(cg.Gather))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cgroup",
+			map[string]string{
+				"path": "testdata/v2/system.slice",
+			},
+			map[string]interface{}{
+				"cpu.stat.usage_usec":                 int64(8283646),
+				"cpu.stat.user_usec":                  int64(5428920),
+				"cpu.stat.system_usec":                int64(2854726),
+				"cpu.stat.nr_periods":                 int64(0),
+				"cpu.stat.nr_throttled":               int64(0),
+				"cpu.stat.throttled_usec":             int64(0),
+				"memory.stat.anon":                    int64(1060864),
+				"memory.stat.file":                    int64(4620288),
+				"memory.stat.kernel_stack":            int64(98304),
+				"memory.stat.sock":                    int64(0),
+				"memory.stat.shmem":                   int64(0),
+				"memory.stat.file_mapped":             int64(2162688),
+				"memory.stat.pgfault":                 int64(6897),
+				"memory.stat.workingset_refault_anon": int64(0),
+				"memory.events.low":                   int64(0),
+				"memory.events.high":                  int64(0),
+				"memory.events.max":                   int64(2),
+				"memory.events.oom":                   int64(1),
+				"memory.events.oom_kill":              int64(1),
+				"memory.current":                      int64(5922816),
+				"pids.current":                        int64(12),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"cgroup",
+			map[string]string{
+				"path":   "testdata/v2/system.slice",
+				"device": "8:0",
+			},
+			map[string]interface{}{
+				"io.stat.rbytes": int64(1459200),
+				"io.stat.wbytes": int64(314773504),
+				"io.stat.rios":   int64(192),
+				"io.stat.wios":   int64(353),
+				"io.stat.dbytes": int64(0),
+				"io.stat.dios":   int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"cgroup",
+			map[string]string{
+				"path":   "testdata/v2/system.slice",
+				"device": "253:0",
+			},
+			map[string]interface{}{
+				"io.stat.rbytes": int64(1024),
+				"io.stat.wbytes": int64(0),
+				"io.stat.rios":   int64(1),
+				"io.stat.wios":   int64(0),
+				"io.stat.dbytes": int64(0),
+				"io.stat.dios":   int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+}
+
+func TestCgroupV2Files(t *testing.T) {
+	cg := &CGroup{
+		Paths: []string{"testdata/v2/system.slice"},
+		Files: []string{"cpu.max", "memory.max", "memory.pressure"},
+	}
+	require.NoError(t, cg.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(cg.Gather))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cgroup",
+			map[string]string{
+				"path": "testdata/v2/system.slice",
+			},
+			map[string]interface{}{
+				"cpu.max.0":                   "max",
+				"cpu.max.1":                   int64(100000),
+				"memory.max":                  "max",
+				"memory.pressure.some.avg10":  float64(0.12),
+				"memory.pressure.some.avg60":  float64(0.05),
+				"memory.pressure.some.avg300": float64(0),
+				"memory.pressure.some.total":  int64(12345),
+				"memory.pressure.full.avg10":  float64(0),
+				"memory.pressure.full.avg60":  float64(0),
+				"memory.pressure.full.avg300": float64(0),
+				"memory.pressure.full.total":  int64(678),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// Forcing v1 parsing of a v2 directory uses the v1 file formats.
+	cg = &CGroup{
+		Paths:   []string{"testdata/v2/system.slice"},
+		Files:   []string{"io.stat"},
+		Version: "v1",
+	}
+	require.NoError(t, cg.Init())
+	require.Error(t, (&testutil.Accumulator{}).GatherError(cg.Gather))
+
+	require.Error(t, (&CGroup{Version: "v3"}).Init())
+}
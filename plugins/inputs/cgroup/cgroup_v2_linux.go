@@ -0,0 +1,175 @@
+// +build linux
+
+package cgroup
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// defaultV2Files are the files read from cgroup v2 directories when no files
+// are configured.
+var defaultV2Files = []string{
+	"cpu.stat",
+	"memory.stat",
+	"memory.events",
+	"memory.current",
+	"memory.swap.current",
+	"io.stat",
+	"pids.current",
+}
+
+// isV2 reports whether the directory belongs to the unified cgroup v2
+// hierarchy, whose directories all have a cgroup.controllers file.
+func (g *CGroup) isV2(dir string) bool {
+	switch g.Version {
+	case "v1":
+		return false
+	case "v2":
+		return true
+	}
+	_, err := os.Stat(path.Join(dir, "cgroup.controllers"))
+	return err == nil
+}
+
+func (g *CGroup) gatherDirV2(dir string, acc telegraf.Accumulator) error {
+	files := g.Files
+	if len(files) == 0 {
+		files = defaultV2Files
+	}
+
+	fields := make(map[string]interface{})
+	devices := make(map[string]map[string]interface{})
+
+	list := make(chan pathInfo)
+	go generateFiles(dir, files, list)
+
+	for file := range list {
+		if file.err != nil {
+			return file.err
+		}
+
+		raw, err := ioutil.ReadFile(file.path)
+		if err != nil {
+			return err
+		}
+		if len(raw) == 0 {
+			continue
+		}
+
+		name := filepath.Base(file.path)
+		if name == "io.stat" {
+			parseIOStat(raw, devices)
+			continue
+		}
+		parseV2(name, raw, fields)
+	}
+
+	if len(fields) > 0 {
+		acc.AddFields(metricName, fields, map[string]string{"path": dir})
+	}
+	for device, deviceFields := range devices {
+		acc.AddFields(metricName, deviceFields, map[string]string{"path": dir, "device": device})
+	}
+	return nil
+}
+
+// parseV2 parses the cgroup v2 interface files, which are in one of the
+// following formats:
+//
+//	VAL\n                         single value
+//	VAL0 VAL1 ...\n               space separated values
+//	KEY0 VAL0\nKEY1 VAL1\n        flat keyed
+//	KEY0 SUB0=VAL00 SUB1=VAL01\n  nested keyed
+//
+// Single values are named after the file, the others get the index, key
+// and sub key appended.
+func parseV2(name string, b []byte, fields map[string]interface{}) {
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+
+	// Files with a single line are values, unless it is a key followed by
+	// values.  "max" is the value of the limits which are not set.
+	if len(lines) == 1 {
+		values := strings.Fields(lines[0])
+		switch {
+		case len(values) == 1:
+			fields[name] = parseV2Value(values[0])
+			return
+		case !isKey(values[0]):
+			for i, v := range values {
+				fields[name+"."+strconv.Itoa(i)] = parseV2Value(v)
+			}
+			return
+		}
+	}
+
+	for _, line := range lines {
+		values := strings.Fields(line)
+		if len(values) < 2 {
+			continue
+		}
+
+		key := name + "." + values[0]
+		if len(values) == 2 && !strings.Contains(values[1], "=") {
+			fields[key] = parseV2Value(values[1])
+			continue
+		}
+		for _, kv := range values[1:] {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			fields[key+"."+parts[0]] = parseV2Value(parts[1])
+		}
+	}
+}
+
+// parseIOStat parses the io.stat file, whose lines hold the statistics of a
+// device identified by its major and minor numbers:
+//
+//	8:16 rbytes=1459200 wbytes=314773504 rios=192 wios=353 dbytes=0 dios=0
+func parseIOStat(b []byte, devices map[string]map[string]interface{}) {
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		values := strings.Fields(line)
+		if len(values) < 2 {
+			continue
+		}
+
+		fields, ok := devices[values[0]]
+		if !ok {
+			fields = make(map[string]interface{})
+			devices[values[0]] = fields
+		}
+		for _, kv := range values[1:] {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			fields["io.stat."+parts[0]] = parseV2Value(parts[1])
+		}
+	}
+}
+
+func isKey(s string) bool {
+	if s == "max" {
+		return false
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err != nil
+}
+
+func parseV2Value(s string) interface{} {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
@@ -102,6 +102,11 @@ type DockerLogs struct {
 	wg              sync.WaitGroup
 	mu              sync.Mutex
 	containerList   map[string]context.CancelFunc
+
+	// lastRecord is the timestamp of the last log line read from each
+	// container, used to resume after a restart.
+	lastRecordMu sync.Mutex
+	lastRecord   map[string]time.Time
 }
 
 func (d *DockerLogs) Description() string {
@@ -214,6 +219,8 @@ func (d *DockerLogs) Gather(acc telegraf.Accumulator) error {
 		return err
 	}
 
+	d.pruneLastRecords(containers)
+
 	for _, container := range containers {
 		if d.containerInContainerList(container.ID) {
 			continue
@@ -295,6 +302,13 @@ func (d *DockerLogs) tailContainerLogs(
 		Tail:       tail,
 	}
 
+	// Resume after the last line read from the container.
+	since := d.lastRecordTime(container.ID)
+	if !since.IsZero() {
+		logOptions.Since = fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond())
+		logOptions.Tail = "all"
+	}
+
 	logReader, err := d.client.ContainerLogs(ctx, container.ID, logOptions)
 	if err != nil {
 		return err
@@ -307,9 +321,9 @@ func (d *DockerLogs) tailContainerLogs(
 	// If the container is *not* using a TTY, streams for stdout and stderr are
 	// multiplexed.
 	if hasTTY {
-		return tailStream(acc, tags, container.ID, logReader, "tty")
+		return d.tailStream(acc, tags, container.ID, logReader, "tty", since)
 	} else {
-		return tailMultiplexed(acc, tags, container.ID, logReader)
+		return d.tailMultiplexed(acc, tags, container.ID, logReader, since)
 	}
 }
 
@@ -337,12 +351,13 @@ func parseLine(line []byte) (time.Time, string, error) {
 	return ts, string(message), nil
 }
 
-func tailStream(
+func (d *DockerLogs) tailStream(
 	acc telegraf.Accumulator,
 	baseTags map[string]string,
 	containerID string,
 	reader io.ReadCloser,
 	stream string,
+	since time.Time,
 ) error {
 	defer reader.Close()
 
@@ -361,11 +376,14 @@ func tailStream(
 			ts, message, err := parseLine(line)
 			if err != nil {
 				acc.AddError(err)
-			} else {
+			} else if ts.After(since) {
+				// The since option of the API is inclusive, so the last
+				// line read before is skipped here.
 				acc.AddFields("docker_log", map[string]interface{}{
 					"container_id": containerID,
 					"message":      message,
 				}, tags, ts)
+				d.setLastRecordTime(containerID, ts)
 			}
 		}
 
@@ -378,11 +396,12 @@ func tailStream(
 	}
 }
 
-func tailMultiplexed(
+func (d *DockerLogs) tailMultiplexed(
 	acc telegraf.Accumulator,
 	tags map[string]string,
 	containerID string,
 	src io.ReadCloser,
+	since time.Time,
 ) error {
 	outReader, outWriter := io.Pipe()
 	errReader, errWriter := io.Pipe()
@@ -391,7 +410,7 @@ func tailMultiplexed(
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		err := tailStream(acc, tags, containerID, outReader, "stdout")
+		err := d.tailStream(acc, tags, containerID, outReader, "stdout", since)
 		if err != nil {
 			acc.AddError(err)
 		}
@@ -400,7 +419,7 @@ func tailMultiplexed(
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		err := tailStream(acc, tags, containerID, errReader, "stderr")
+		err := d.tailStream(acc, tags, containerID, errReader, "stderr", since)
 		if err != nil {
 			acc.AddError(err)
 		}
@@ -425,6 +444,70 @@ func (d *DockerLogs) Stop() {
 	d.wg.Wait()
 }
 
+func (d *DockerLogs) lastRecordTime(containerID string) time.Time {
+	d.lastRecordMu.Lock()
+	defer d.lastRecordMu.Unlock()
+	return d.lastRecord[containerID]
+}
+
+func (d *DockerLogs) setLastRecordTime(containerID string, ts time.Time) {
+	d.lastRecordMu.Lock()
+	defer d.lastRecordMu.Unlock()
+	if d.lastRecord == nil {
+		d.lastRecord = make(map[string]time.Time)
+	}
+	if ts.After(d.lastRecord[containerID]) {
+		d.lastRecord[containerID] = ts
+	}
+}
+
+// pruneLastRecords forgets the containers that no longer exist.
+func (d *DockerLogs) pruneLastRecords(containers []types.Container) {
+	ids := make(map[string]bool, len(containers))
+	for _, container := range containers {
+		ids[container.ID] = true
+	}
+
+	d.lastRecordMu.Lock()
+	defer d.lastRecordMu.Unlock()
+	for id := range d.lastRecord {
+		if !ids[id] && !d.containerInContainerList(id) {
+			delete(d.lastRecord, id)
+		}
+	}
+}
+
+// GetState returns the timestamp of the last log line read from each
+// container, keyed by container ID.
+func (d *DockerLogs) GetState() interface{} {
+	d.lastRecordMu.Lock()
+	defer d.lastRecordMu.Unlock()
+
+	state := make(map[string]string, len(d.lastRecord))
+	for id, ts := range d.lastRecord {
+		state[id] = ts.UTC().Format(time.RFC3339Nano)
+	}
+	return state
+}
+
+// SetState restores the timestamps of the last log lines, the logs of these
+// containers are read from there regardless of from_beginning.
+func (d *DockerLogs) SetState(state interface{}) error {
+	records, ok := state.(map[string]string)
+	if !ok {
+		return fmt.Errorf("invalid state type %T", state)
+	}
+
+	for id, value := range records {
+		ts, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %q for container %s: %v", value, id, err)
+		}
+		d.setLastRecordTime(id, ts)
+	}
+	return nil
+}
+
 // Following few functions have been inherited from telegraf docker input plugin
 func (d *DockerLogs) createContainerFilters() error {
 	filter, err := filter.NewIncludeExcludeFilter(d.ContainerInclude, d.ContainerExclude)
@@ -186,3 +186,71 @@ func Test(t *testing.T) {
 		})
 	}
 }
+
+func TestResumeFromState(t *testing.T) {
+	var since string
+	client := &MockClient{
+		ContainerListF: func(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+			return []types.Container{
+				{
+					ID:    "deadbeef",
+					Names: []string{"/telegraf"},
+					Image: "influxdata/telegraf:1.11.0",
+				},
+			}, nil
+		},
+		ContainerInspectF: func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+			return types.ContainerJSON{
+				Config: &container.Config{
+					Tty: true,
+				},
+			}, nil
+		},
+		ContainerLogsF: func(ctx context.Context, containerID string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+			since = options.Since
+			return &Response{Reader: bytes.NewBuffer([]byte(
+				"2020-04-28T18:43:16.432691200Z hello\n" +
+					"2020-04-28T18:43:17.000000001Z world\n"))}, nil
+		},
+	}
+
+	var acc testutil.Accumulator
+	plugin := &DockerLogs{
+		Timeout:       internal.Duration{Duration: time.Second * 5},
+		newClient:     func(string, *tls.Config) (Client, error) { return client, nil },
+		containerList: make(map[string]context.CancelFunc),
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.SetState(map[string]string{
+		"deadbeef": "2020-04-28T18:43:16.4326912Z",
+		"gone":     "2020-04-28T18:43:16Z",
+	}))
+
+	require.NoError(t, plugin.Gather(&acc))
+	acc.Wait(1)
+	plugin.Stop()
+
+	require.Nil(t, acc.Errors)
+	require.Equal(t, "1588099396.432691200", since)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"docker_log",
+			map[string]string{
+				"container_name":    "telegraf",
+				"container_image":   "influxdata/telegraf",
+				"container_version": "1.11.0",
+				"stream":            "tty",
+			},
+			map[string]interface{}{
+				"container_id": "deadbeef",
+				"message":      "world",
+			},
+			MustParse(time.RFC3339Nano, "2020-04-28T18:43:17.000000001Z"),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	require.Equal(t, map[string]string{"deadbeef": "2020-04-28T18:43:17.000000001Z"}, plugin.GetState())
+	require.Error(t, plugin.SetState(map[string]string{"deadbeef": "yesterday"}))
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+	"github.com/tidwall/gjson"
+)
+
+// AuthRequest is a request executed ahead of the data requests, such as a
+// login, whose response provides a token for the data requests.
+type AuthRequest struct {
+	URL      string            `toml:"url"`
+	Method   string            `toml:"method"`
+	Body     string            `toml:"body"`
+	Headers  map[string]string `toml:"headers"`
+	Username string            `toml:"username"`
+	Password string            `toml:"password"`
+
+	TokenPath   string            `toml:"token_path"`
+	TokenHeader string            `toml:"token_header"`
+	TokenPrefix string            `toml:"token_prefix"`
+	TokenTTL    internal.Duration `toml:"token_ttl"`
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (a *AuthRequest) init() error {
+	if a.URL == "" {
+		return fmt.Errorf("auth_request requires an url")
+	}
+	if a.Method == "" {
+		a.Method = http.MethodPost
+	}
+	return nil
+}
+
+// getToken returns the token of the last auth request if it has not expired,
+// or executes the request.
+func (a *AuthRequest) getToken(client *http.Client, now time.Time) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && now.Before(a.expires) {
+		return a.token, nil
+	}
+
+	request, err := http.NewRequest(a.Method, a.URL, strings.NewReader(a.Body))
+	if err != nil {
+		return "", err
+	}
+	setHeaders(request, a.Headers)
+	if a.Username != "" || a.Password != "" {
+		request.SetBasicAuth(a.Username, a.Password)
+	}
+
+	resp, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("auth request received status code %d (%s)",
+			resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(b))
+	if a.TokenPath != "" {
+		result := gjson.GetBytes(b, a.TokenPath)
+		if !result.Exists() {
+			return "", fmt.Errorf("token_path %q not found in auth response", a.TokenPath)
+		}
+		token = result.String()
+	}
+	if token == "" {
+		return "", fmt.Errorf("empty token in auth response")
+	}
+
+	a.token = token
+	a.expires = now.Add(a.TokenTTL.Duration)
+	return token, nil
+}
+
+// expire discards the token, so it is requested again.
+func (a *AuthRequest) expire() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.token = ""
+}
+
+// setToken sets the token header of the data request.
+func (a *AuthRequest) setToken(request *http.Request, token string) {
+	if a.TokenHeader != "" {
+		request.Header.Set(a.TokenHeader, a.TokenPrefix+token)
+	}
+}
@@ -1,12 +1,15 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -14,8 +17,12 @@ import (
 	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
 )
 
+const defaultPaginationMaxPages = 100
+
 type HTTP struct {
 	URLs            []string `toml:"urls"`
 	Method          string   `toml:"method"`
@@ -32,11 +39,34 @@ type HTTP struct {
 	// Absolute path to file with Bearer token
 	BearerToken string `toml:"bearer_token"`
 
+	// OAuth2 Client Credentials
+	ClientID     string   `toml:"client_id"`
+	ClientSecret string   `toml:"client_secret"`
+	TokenURL     string   `toml:"token_url"`
+	Scopes       []string `toml:"scopes"`
+
+	AuthRequest *AuthRequest `toml:"auth_request"`
+
 	SuccessStatusCodes []int `toml:"success_status_codes"`
 
 	Timeout internal.Duration `toml:"timeout"`
 
-	client *http.Client
+	Templates     bool              `toml:"templates"`
+	InitialWindow internal.Duration `toml:"initial_window"`
+
+	Pagination            string `toml:"pagination"`
+	PaginationCursorPath  string `toml:"pagination_cursor_path"`
+	PaginationCursorParam string `toml:"pagination_cursor_param"`
+	PaginationMaxPages    int    `toml:"pagination_max_pages"`
+
+	client       *http.Client
+	urlTemplates []*template.Template
+	bodyTemplate *template.Template
+
+	// windowEnds holds the end of the time window of the last successful
+	// gather of each URL.
+	mu         sync.Mutex
+	windowEnds map[string]time.Time
 
 	// The parser will automatically be set by Telegraf core code because
 	// this plugin implements the ParserInput interface (i.e. the SetParser method)
@@ -63,9 +93,37 @@ var sampleConfig = `
   # username = "username"
   # password = "pa$$word"
 
+  ## OAuth2 Client Credentials Grant
+  # client_id = "clientid"
+  # client_secret = "secret"
+  # token_url = "https://indentityprovider/oauth2/v1/token"
+  # scopes = ["urn:opc:idm:__myscopes__"]
+
   ## HTTP entity-body to send with POST/PUT requests.
   # body = ""
 
+  ## Render the urls and body as Go templates on every gather with:
+  ##   {{.Start}} - end of the window of the last successful gather of the url
+  ##   {{.End}}   - time of the gather
+  ##   {{.Token}} - token of the auth_request
+  ## Times are formatted with the unix, unix_ms and rfc3339 functions, for
+  ## example "http://localhost/metrics?from={{unix .Start}}&to={{unix .End}}".
+  # templates = false
+  ## Length of the window of the first gather:
+  # initial_window = "1m"
+
+  ## Pagination of the responses:
+  ##   none   - a single request per url
+  ##   link   - follow the "next" relation of the Link header
+  ##   cursor - set the pagination_cursor_param query parameter of the next
+  ##            request to the value at pagination_cursor_path (GJSON syntax)
+  ##            of the JSON response, until it is empty
+  # pagination = "none"
+  # pagination_cursor_path = "next_cursor"
+  # pagination_cursor_param = "cursor"
+  ## Maximum number of pages read per url and gather.
+  # pagination_max_pages = 100
+
   ## HTTP Content-Encoding for write request body, can be set to "gzip" to
   ## compress body or "identity" to apply no encoding.
   # content_encoding = "identity"
@@ -88,6 +146,24 @@ var sampleConfig = `
   ## more about them here:
   ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
   # data_format = "influx"
+
+  ## Request executed ahead of the data requests, such as a login, whose
+  ## response provides a token for the data requests.
+  # [inputs.http.auth_request]
+  #   url = "https://localhost/api/login"
+  #   method = "POST"
+  #   body = '{"username": "telegraf", "password": "secret"}'
+  #   headers = {"Content-Type" = "application/json"}
+  #   # username = ""
+  #   # password = ""
+  #   ## Path of the token in the JSON response, in GJSON syntax; when empty
+  #   ## the whole response is the token.
+  #   token_path = "access_token"
+  #   ## Header of the data requests set to the prefix followed by the token.
+  #   token_header = "Authorization"
+  #   token_prefix = "Bearer "
+  #   ## Time the token is reused; when 0 it is requested on every gather.
+  #   # token_ttl = "0s"
 `
 
 // SampleConfig returns the default configuration of the Input
@@ -114,6 +190,44 @@ func (h *HTTP) Init() error {
 		Timeout: h.Timeout.Duration,
 	}
 
+	if h.ClientID != "" && h.ClientSecret != "" && h.TokenURL != "" {
+		oauthConfig := clientcredentials.Config{
+			ClientID:     h.ClientID,
+			ClientSecret: h.ClientSecret,
+			TokenURL:     h.TokenURL,
+			Scopes:       h.Scopes,
+		}
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, h.client)
+		h.client = oauthConfig.Client(ctx)
+		h.client.Timeout = h.Timeout.Duration
+	}
+
+	if h.AuthRequest != nil {
+		if err := h.AuthRequest.init(); err != nil {
+			return err
+		}
+	}
+
+	if h.Templates {
+		if err := h.initTemplates(); err != nil {
+			return err
+		}
+	}
+	h.windowEnds = make(map[string]time.Time)
+
+	switch h.Pagination {
+	case "", "none", "link":
+	case "cursor":
+		if h.PaginationCursorPath == "" || h.PaginationCursorParam == "" {
+			return fmt.Errorf("cursor pagination requires pagination_cursor_path and pagination_cursor_param")
+		}
+	default:
+		return fmt.Errorf("invalid pagination %q", h.Pagination)
+	}
+	if h.PaginationMaxPages <= 0 {
+		h.PaginationMaxPages = defaultPaginationMaxPages
+	}
+
 	// Set default as [200]
 	if len(h.SuccessStatusCodes) == 0 {
 		h.SuccessStatusCodes = []int{200}
@@ -121,18 +235,51 @@ func (h *HTTP) Init() error {
 	return nil
 }
 
+// initTemplates parses the urls and body as templates.
+func (h *HTTP) initTemplates() error {
+	var err error
+	h.urlTemplates = make([]*template.Template, len(h.URLs))
+	for i, u := range h.URLs {
+		h.urlTemplates[i], err = template.New("url").Funcs(templateFuncs).Parse(u)
+		if err != nil {
+			return fmt.Errorf("invalid url template %q: %v", u, err)
+		}
+	}
+	h.bodyTemplate, err = template.New("body").Funcs(templateFuncs).Parse(h.Body)
+	if err != nil {
+		return fmt.Errorf("invalid body template: %v", err)
+	}
+	return nil
+}
+
 // Gather takes in an accumulator and adds the metrics that the Input
 // gathers. This is called every "interval"
 func (h *HTTP) Gather(acc telegraf.Accumulator) error {
+	now := time.Now()
+
+	var token string
+	if h.AuthRequest != nil {
+		var err error
+		token, err = h.AuthRequest.getToken(h.client, now)
+		if err != nil {
+			return err
+		}
+	}
+
 	var wg sync.WaitGroup
-	for _, u := range h.URLs {
+	for i, u := range h.URLs {
+		var tmpl *template.Template
+		if h.Templates {
+			tmpl = h.urlTemplates[i]
+		}
+
 		wg.Add(1)
-		go func(url string) {
+		go func(url string, tmpl *template.Template) {
 			defer wg.Done()
-			if err := h.gatherURL(acc, url); err != nil {
+			if err := h.gatherURL(acc, url, tmpl, token, now); err != nil {
 				acc.AddError(fmt.Errorf("[url=%s]: %s", url, err))
 			}
-		}(u)
+		}(u, tmpl)
 	}
 
 	wg.Wait()
@@ -145,48 +292,139 @@ func (h *HTTP) SetParser(parser parsers.Parser) {
 	h.parser = parser
 }
 
+// templateData is the data the urls and body templates are rendered with.
+type templateData struct {
+	Start time.Time
+	End   time.Time
+	Token string
+}
+
+var templateFuncs = template.FuncMap{
+	"unix": func(t time.Time) int64 {
+		return t.Unix()
+	},
+	"unix_ms": func(t time.Time) int64 {
+		return t.UnixNano() / int64(time.Millisecond)
+	},
+	"rfc3339": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+}
+
+func render(tmpl *template.Template, data *templateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
 // Gathers data from a particular URL
 // Parameters:
 //     acc    : The telegraf Accumulator to use
-//     url    : endpoint to send request to
+//     url    : endpoint to send request to, added as tag
+//     tmpl   : template of the url, nil unless templates are enabled
+//     token  : token of the auth request
+//     now    : time of the gather
 //
 // Returns:
 //     error: Any error that may have occurred
 func (h *HTTP) gatherURL(
 	acc telegraf.Accumulator,
 	url string,
+	tmpl *template.Template,
+	token string,
+	now time.Time,
 ) error {
-	body, err := makeRequestBodyReader(h.ContentEncoding, h.Body)
+	next, body := url, h.Body
+	if tmpl != nil {
+		h.mu.Lock()
+		start, ok := h.windowEnds[url]
+		h.mu.Unlock()
+		if !ok {
+			start = now.Add(-h.InitialWindow.Duration)
+		}
+		data := &templateData{Start: start, End: now, Token: token}
+
+		var err error
+		next, err = render(tmpl, data)
+		if err != nil {
+			return err
+		}
+		body, err = render(h.bodyTemplate, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	var metrics []telegraf.Metric
+	for page := 0; next != ""; page++ {
+		if page == h.PaginationMaxPages {
+			return fmt.Errorf("reached pagination_max_pages of %d", h.PaginationMaxPages)
+		}
+
+		header, b, err := h.request(next, body, token)
+		if err != nil {
+			return err
+		}
+
+		pageMetrics, err := h.parser.Parse(b)
+		if err != nil {
+			return err
+		}
+		metrics = append(metrics, pageMetrics...)
+
+		next, err = h.nextPage(next, header, b)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, metric := range metrics {
+		if !metric.HasTag("url") {
+			metric.AddTag("url", url)
+		}
+		acc.AddFields(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
+	}
+
+	h.mu.Lock()
+	h.windowEnds[url] = now
+	h.mu.Unlock()
+	return nil
+}
+
+// request executes a data request and returns the headers and body of the
+// response.
+func (h *HTTP) request(url, body, token string) (http.Header, []byte, error) {
+	reader, err := makeRequestBodyReader(h.ContentEncoding, body)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	defer body.Close()
+	defer reader.Close()
 
-	request, err := http.NewRequest(h.Method, url, body)
+	request, err := http.NewRequest(h.Method, url, reader)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	if h.BearerToken != "" {
 		token, err := ioutil.ReadFile(h.BearerToken)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		bearer := "Bearer " + strings.Trim(string(token), "\n")
 		request.Header.Set("Authorization", bearer)
 	}
 
+	if h.AuthRequest != nil {
+		h.AuthRequest.setToken(request, token)
+	}
+
 	if h.ContentEncoding == "gzip" {
 		request.Header.Set("Content-Encoding", "gzip")
 	}
 
-	for k, v := range h.Headers {
-		if strings.ToLower(k) == "host" {
-			request.Host = v
-		} else {
-			request.Header.Add(k, v)
-		}
-	}
+	setHeaders(request, h.Headers)
 
 	if h.Username != "" || h.Password != "" {
 		request.SetBasicAuth(h.Username, h.Password)
@@ -194,7 +432,7 @@ func (h *HTTP) gatherURL(
 
 	resp, err := h.client.Do(request)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
@@ -207,7 +445,11 @@ func (h *HTTP) gatherURL(
 	}
 
 	if !responseHasSuccessCode {
-		return fmt.Errorf("received status code %d (%s), expected any value out of %v",
+		if resp.StatusCode == http.StatusUnauthorized && h.AuthRequest != nil {
+			// Request a new token on the next gather.
+			h.AuthRequest.expire()
+		}
+		return nil, nil, fmt.Errorf("received status code %d (%s), expected any value out of %v",
 			resp.StatusCode,
 			http.StatusText(resp.StatusCode),
 			h.SuccessStatusCodes)
@@ -215,22 +457,19 @@ func (h *HTTP) gatherURL(
 
 	b, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return err
-	}
-
-	metrics, err := h.parser.Parse(b)
-	if err != nil {
-		return err
+		return nil, nil, err
 	}
+	return resp.Header, b, nil
+}
 
-	for _, metric := range metrics {
-		if !metric.HasTag("url") {
-			metric.AddTag("url", url)
+func setHeaders(request *http.Request, headers map[string]string) {
+	for k, v := range headers {
+		if strings.ToLower(k) == "host" {
+			request.Host = v
+		} else {
+			request.Header.Add(k, v)
 		}
-		acc.AddFields(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
 	}
-
-	return nil
 }
 
 func makeRequestBodyReader(contentEncoding, body string) (io.ReadCloser, error) {
@@ -248,8 +487,9 @@ func makeRequestBodyReader(contentEncoding, body string) (io.ReadCloser, error)
 func init() {
 	inputs.Add("http", func() telegraf.Input {
 		return &HTTP{
-			Timeout: internal.Duration{Duration: time.Second * 5},
-			Method:  "GET",
+			Timeout:       internal.Duration{Duration: time.Second * 5},
+			Method:        "GET",
+			InitialWindow: internal.Duration{Duration: time.Minute},
 		}
 	})
 }
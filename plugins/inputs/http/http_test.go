@@ -6,8 +6,12 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf/internal"
 	plugin "github.com/influxdata/telegraf/plugins/inputs/http"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/testutil"
@@ -252,3 +256,221 @@ func TestBodyAndContentEncoding(t *testing.T) {
 		})
 	}
 }
+
+func newInfluxParser(t *testing.T) parsers.Parser {
+	parser, err := parsers.NewParser(&parsers.Config{DataFormat: "influx"})
+	require.NoError(t, err)
+	return parser
+}
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "abc", "token_type": "bearer", "expires_in": 3600}`))
+		case "/endpoint":
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte("cpu value=42\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	h := &plugin.HTTP{
+		URLs:         []string{ts.URL + "/endpoint"},
+		ClientID:     "telegraf",
+		ClientSecret: "secret",
+		TokenURL:     ts.URL + "/token",
+	}
+	h.SetParser(newInfluxParser(t))
+	require.NoError(t, h.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 1)
+}
+
+func TestAuthRequest(t *testing.T) {
+	var logins int
+	valid := "token1"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			body, _ := ioutil.ReadAll(r.Body)
+			if r.Method != "POST" || string(body) != `{"user": "telegraf"}` {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			logins++
+			_, _ = w.Write([]byte(fmt.Sprintf(`{"data": {"token": "token%d"}}`, logins)))
+		case "/endpoint":
+			if r.Header.Get("X-Auth-Token") != "Token "+valid || r.URL.Query().Get("session") != valid {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte("cpu value=42\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	h := &plugin.HTTP{
+		URLs:      []string{ts.URL + "/endpoint?session={{.Token}}"},
+		Templates: true,
+		AuthRequest: &plugin.AuthRequest{
+			URL:         ts.URL + "/login",
+			Body:        `{"user": "telegraf"}`,
+			TokenPath:   "data.token",
+			TokenHeader: "X-Auth-Token",
+			TokenPrefix: "Token ",
+			TokenTTL:    internal.Duration{Duration: time.Hour},
+		},
+	}
+	h.SetParser(newInfluxParser(t))
+	require.NoError(t, h.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 2)
+	require.Equal(t, 1, logins)
+	require.Equal(t, ts.URL+"/endpoint?session={{.Token}}", acc.Metrics[0].Tags["url"])
+
+	// An unauthorized response discards the token.
+	valid = "token2"
+	require.Error(t, (&testutil.Accumulator{}).GatherError(h.Gather))
+	require.NoError(t, (&testutil.Accumulator{}).GatherError(h.Gather))
+	require.Equal(t, 2, logins)
+}
+
+func TestTimeWindowTemplates(t *testing.T) {
+	var queries []url.Values
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		queries = append(queries, r.URL.Query())
+		bodies = append(bodies, string(body))
+		_, _ = w.Write([]byte("cpu value=42\n"))
+	}))
+	defer ts.Close()
+
+	h := &plugin.HTTP{
+		URLs:          []string{ts.URL + "/endpoint?from={{unix_ms .Start}}&to={{unix_ms .End}}"},
+		Method:        "POST",
+		Body:          `{"from": "{{rfc3339 .Start}}", "to": "{{rfc3339 .End}}"}`,
+		Templates:     true,
+		InitialWindow: internal.Duration{Duration: time.Hour},
+	}
+	h.SetParser(newInfluxParser(t))
+	require.NoError(t, h.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, queries, 2)
+
+	from, err := strconv.ParseInt(queries[0].Get("from"), 10, 64)
+	require.NoError(t, err)
+	to, err := strconv.ParseInt(queries[0].Get("to"), 10, 64)
+	require.NoError(t, err)
+	require.Equal(t, time.Hour.Nanoseconds()/1e6, to-from)
+
+	// The next window starts at the end of the previous one.
+	require.Equal(t, queries[0].Get("to"), queries[1].Get("from"))
+
+	end := time.Unix(0, to*1e6).UTC().Format(time.RFC3339)
+	require.Contains(t, bodies[0], `"to": "`+end+`"`)
+
+	h = &plugin.HTTP{URLs: []string{ts.URL + "/{{.Start"}, Templates: true}
+	require.Error(t, h.Init())
+}
+
+func TestTemplatesDisabled(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		_, _ = w.Write([]byte("cpu value=42\n"))
+	}))
+	defer ts.Close()
+
+	h := &plugin.HTTP{
+		URLs:   []string{ts.URL + "/endpoint"},
+		Method: "POST",
+		Body:   `{"query": "{{ range }}"}`,
+	}
+	h.SetParser(newInfluxParser(t))
+	require.NoError(t, h.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Equal(t, []string{`{"query": "{{ range }}"}`}, bodies)
+}
+
+func TestLinkPagination(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 3 {
+			w.Header().Set("Link", fmt.Sprintf(`</endpoint?page=%d>; rel="next", </endpoint?page=3>; rel="last"`, page+1))
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf("cpu,page=%d value=42\n", page)))
+	}))
+	defer ts.Close()
+
+	h := &plugin.HTTP{
+		URLs:       []string{ts.URL + "/endpoint?page=1"},
+		Pagination: "link",
+	}
+	h.SetParser(newInfluxParser(t))
+	require.NoError(t, h.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 3)
+	for i, m := range acc.Metrics {
+		require.Equal(t, strconv.Itoa(i+1), m.Tags["page"])
+		require.Equal(t, ts.URL+"/endpoint?page=1", m.Tags["url"])
+	}
+
+	h.PaginationMaxPages = 2
+	acc.ClearMetrics()
+	require.Error(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 0)
+}
+
+func TestCursorPagination(t *testing.T) {
+	next := map[string]string{"": "b", "b": "c", "c": ""}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("after")
+		if r.URL.Query().Get("limit") != "10" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"value": 42, "paging": {"next": %q}}`, next[cursor])))
+	}))
+	defer ts.Close()
+
+	h := &plugin.HTTP{
+		URLs:                  []string{ts.URL + "/endpoint?limit=10"},
+		Pagination:            "cursor",
+		PaginationCursorPath:  "paging.next",
+		PaginationCursorParam: "after",
+	}
+	parser, err := parsers.NewParser(&parsers.Config{DataFormat: "json", MetricName: "api"})
+	require.NoError(t, err)
+	h.SetParser(parser)
+	require.NoError(t, h.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 3)
+
+	h = &plugin.HTTP{Pagination: "cursor"}
+	require.Error(t, h.Init())
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// nextPage returns the URL of the page following the response, or an empty
+// string if this is the last page.
+func (h *HTTP) nextPage(current string, header http.Header, body []byte) (string, error) {
+	switch h.Pagination {
+	case "link":
+		next := linkNext(header)
+		if next == "" {
+			return "", nil
+		}
+		base, err := url.Parse(current)
+		if err != nil {
+			return "", err
+		}
+		ref, err := url.Parse(next)
+		if err != nil {
+			return "", fmt.Errorf("invalid next link %q: %v", next, err)
+		}
+		return base.ResolveReference(ref).String(), nil
+	case "cursor":
+		cursor := gjson.GetBytes(body, h.PaginationCursorPath).String()
+		if cursor == "" {
+			return "", nil
+		}
+		u, err := url.Parse(current)
+		if err != nil {
+			return "", err
+		}
+		q := u.Query()
+		q.Set(h.PaginationCursorParam, cursor)
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	default:
+		return "", nil
+	}
+}
+
+// linkNext returns the target of the "next" relation of the Link headers,
+// as described in RFC 8288.
+func linkNext(header http.Header) string {
+	for _, value := range header["Link"] {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+				if len(kv) != 2 || strings.ToLower(kv[0]) != "rel" {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(kv[1], `"`)) {
+					if strings.ToLower(rel) == "next" {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
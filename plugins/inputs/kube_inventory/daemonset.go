@@ -2,9 +2,8 @@ package kube_inventory
 
 import (
 	"context"
-	"time"
 
-	"github.com/ericchiang/k8s/apis/apps/v1"
+	v1 "k8s.io/api/apps/v1"
 
 	"github.com/influxdata/telegraf"
 )
@@ -16,7 +15,7 @@ func collectDaemonSets(ctx context.Context, acc telegraf.Accumulator, ki *Kubern
 		return
 	}
 	for _, d := range list.Items {
-		if err = ki.gatherDaemonSet(*d, acc); err != nil {
+		if err = ki.gatherDaemonSet(d, acc); err != nil {
 			acc.AddError(err)
 			return
 		}
@@ -25,22 +24,22 @@ func collectDaemonSets(ctx context.Context, acc telegraf.Accumulator, ki *Kubern
 
 func (ki *KubernetesInventory) gatherDaemonSet(d v1.DaemonSet, acc telegraf.Accumulator) error {
 	fields := map[string]interface{}{
-		"generation":               d.Metadata.GetGeneration(),
-		"current_number_scheduled": d.Status.GetCurrentNumberScheduled(),
-		"desired_number_scheduled": d.Status.GetDesiredNumberScheduled(),
-		"number_available":         d.Status.GetNumberAvailable(),
-		"number_misscheduled":      d.Status.GetNumberMisscheduled(),
-		"number_ready":             d.Status.GetNumberReady(),
-		"number_unavailable":       d.Status.GetNumberUnavailable(),
-		"updated_number_scheduled": d.Status.GetUpdatedNumberScheduled(),
+		"generation":               d.Generation,
+		"current_number_scheduled": d.Status.CurrentNumberScheduled,
+		"desired_number_scheduled": d.Status.DesiredNumberScheduled,
+		"number_available":         d.Status.NumberAvailable,
+		"number_misscheduled":      d.Status.NumberMisscheduled,
+		"number_ready":             d.Status.NumberReady,
+		"number_unavailable":       d.Status.NumberUnavailable,
+		"updated_number_scheduled": d.Status.UpdatedNumberScheduled,
 	}
 	tags := map[string]string{
-		"daemonset_name": d.Metadata.GetName(),
-		"namespace":      d.Metadata.GetNamespace(),
+		"daemonset_name": d.Name,
+		"namespace":      d.Namespace,
 	}
 
-	if d.Metadata.CreationTimestamp.GetSeconds() != 0 {
-		fields["created"] = time.Unix(d.Metadata.CreationTimestamp.GetSeconds(), int64(d.Metadata.CreationTimestamp.GetNanos())).UnixNano()
+	if !d.CreationTimestamp.IsZero() {
+		fields["created"] = d.CreationTimestamp.UnixNano()
 	}
 
 	acc.AddFields(daemonSetMeasurement, fields, tags)
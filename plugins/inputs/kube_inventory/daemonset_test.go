@@ -4,8 +4,8 @@ import (
 	"testing"
 	"time"
 
-	"github.com/ericchiang/k8s/apis/apps/v1"
-	metav1 "github.com/ericchiang/k8s/apis/meta/v1"
+	v1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -34,26 +34,26 @@ func TestDaemonSet(t *testing.T) {
 			handler: &mockHandler{
 				responseMap: map[string]interface{}{
 					"/daemonsets/": &v1.DaemonSetList{
-						Items: []*v1.DaemonSet{
+						Items: []v1.DaemonSet{
 							{
-								Status: &v1.DaemonSetStatus{
-									CurrentNumberScheduled: toInt32Ptr(3),
-									DesiredNumberScheduled: toInt32Ptr(5),
-									NumberAvailable:        toInt32Ptr(2),
-									NumberMisscheduled:     toInt32Ptr(2),
-									NumberReady:            toInt32Ptr(1),
-									NumberUnavailable:      toInt32Ptr(1),
-									UpdatedNumberScheduled: toInt32Ptr(2),
+								Status: v1.DaemonSetStatus{
+									CurrentNumberScheduled: 3,
+									DesiredNumberScheduled: 5,
+									NumberAvailable:        2,
+									NumberMisscheduled:     2,
+									NumberReady:            1,
+									NumberUnavailable:      1,
+									UpdatedNumberScheduled: 2,
 								},
-								Metadata: &metav1.ObjectMeta{
-									Generation: toInt64Ptr(11221),
-									Namespace:  toStrPtr("ns1"),
-									Name:       toStrPtr("daemon1"),
+								ObjectMeta: metav1.ObjectMeta{
+									Generation: 11221,
+									Namespace:  "ns1",
+									Name:       "daemon1",
 									Labels: map[string]string{
 										"lab1": "v1",
 										"lab2": "v2",
 									},
-									CreationTimestamp: &metav1.Time{Seconds: toInt64Ptr(now.Unix())},
+									CreationTimestamp: metav1.Time{Time: now},
 								},
 							},
 						},
@@ -91,7 +91,7 @@ func TestDaemonSet(t *testing.T) {
 		}
 		acc := new(testutil.Accumulator)
 		for _, dset := range ((v.handler.responseMap["/daemonsets/"]).(*v1.DaemonSetList)).Items {
-			err := ks.gatherDaemonSet(*dset, acc)
+			err := ks.gatherDaemonSet(dset, acc)
 			if err != nil {
 				t.Errorf("Failed to gather daemonset - %s", err.Error())
 			}
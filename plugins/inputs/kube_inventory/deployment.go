@@ -2,10 +2,9 @@ package kube_inventory
 
 import (
 	"context"
-	"time"
 
-	v1 "github.com/ericchiang/k8s/apis/apps/v1"
 	"github.com/influxdata/telegraf"
+	v1 "k8s.io/api/apps/v1"
 )
 
 func collectDeployments(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesInventory) {
@@ -15,7 +14,7 @@ func collectDeployments(ctx context.Context, acc telegraf.Accumulator, ki *Kuber
 		return
 	}
 	for _, d := range list.Items {
-		if err = ki.gatherDeployment(*d, acc); err != nil {
+		if err = ki.gatherDeployment(d, acc); err != nil {
 			acc.AddError(err)
 			return
 		}
@@ -24,13 +23,13 @@ func collectDeployments(ctx context.Context, acc telegraf.Accumulator, ki *Kuber
 
 func (ki *KubernetesInventory) gatherDeployment(d v1.Deployment, acc telegraf.Accumulator) error {
 	fields := map[string]interface{}{
-		"replicas_available":   d.Status.GetAvailableReplicas(),
-		"replicas_unavailable": d.Status.GetUnavailableReplicas(),
-		"created":              time.Unix(d.Metadata.CreationTimestamp.GetSeconds(), int64(d.Metadata.CreationTimestamp.GetNanos())).UnixNano(),
+		"replicas_available":   d.Status.AvailableReplicas,
+		"replicas_unavailable": d.Status.UnavailableReplicas,
+		"created":              d.CreationTimestamp.UnixNano(),
 	}
 	tags := map[string]string{
-		"deployment_name": d.Metadata.GetName(),
-		"namespace":       d.Metadata.GetNamespace(),
+		"deployment_name": d.Name,
+		"namespace":       d.Namespace,
 	}
 
 	acc.AddFields(deploymentMeasurement, fields, tags)
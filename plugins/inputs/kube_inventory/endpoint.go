@@ -3,9 +3,8 @@ package kube_inventory
 import (
 	"context"
 	"strings"
-	"time"
 
-	"github.com/ericchiang/k8s/apis/core/v1"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/influxdata/telegraf"
 )
@@ -17,7 +16,7 @@ func collectEndpoints(ctx context.Context, acc telegraf.Accumulator, ki *Kuberne
 		return
 	}
 	for _, i := range list.Items {
-		if err = ki.gatherEndpoint(*i, acc); err != nil {
+		if err = ki.gatherEndpoint(i, acc); err != nil {
 			acc.AddError(err)
 			return
 		}
@@ -25,53 +24,53 @@ func collectEndpoints(ctx context.Context, acc telegraf.Accumulator, ki *Kuberne
 }
 
 func (ki *KubernetesInventory) gatherEndpoint(e v1.Endpoints, acc telegraf.Accumulator) error {
-	if e.Metadata.CreationTimestamp.GetSeconds() == 0 && e.Metadata.CreationTimestamp.GetNanos() == 0 {
+	if e.CreationTimestamp.IsZero() {
 		return nil
 	}
 
 	fields := map[string]interface{}{
-		"created":    time.Unix(e.Metadata.CreationTimestamp.GetSeconds(), int64(e.Metadata.CreationTimestamp.GetNanos())).UnixNano(),
-		"generation": e.Metadata.GetGeneration(),
+		"created":    e.CreationTimestamp.UnixNano(),
+		"generation": e.Generation,
 	}
 
 	tags := map[string]string{
-		"endpoint_name": e.Metadata.GetName(),
-		"namespace":     e.Metadata.GetNamespace(),
+		"endpoint_name": e.Name,
+		"namespace":     e.Namespace,
 	}
 
-	for _, endpoint := range e.GetSubsets() {
-		for _, readyAddr := range endpoint.GetAddresses() {
+	for _, endpoint := range e.Subsets {
+		for _, readyAddr := range endpoint.Addresses {
 			fields["ready"] = true
 
-			tags["hostname"] = readyAddr.GetHostname()
-			tags["node_name"] = readyAddr.GetNodeName()
+			tags["hostname"] = readyAddr.Hostname
+			tags["node_name"] = stringValue(readyAddr.NodeName)
 			if readyAddr.TargetRef != nil {
-				tags[strings.ToLower(readyAddr.GetTargetRef().GetKind())] = readyAddr.GetTargetRef().GetName()
+				tags[strings.ToLower(readyAddr.TargetRef.Kind)] = readyAddr.TargetRef.Name
 			}
 
-			for _, port := range endpoint.GetPorts() {
-				fields["port"] = port.GetPort()
+			for _, port := range endpoint.Ports {
+				fields["port"] = port.Port
 
-				tags["port_name"] = port.GetName()
-				tags["port_protocol"] = port.GetProtocol()
+				tags["port_name"] = port.Name
+				tags["port_protocol"] = string(port.Protocol)
 
 				acc.AddFields(endpointMeasurement, fields, tags)
 			}
 		}
-		for _, notReadyAddr := range endpoint.GetNotReadyAddresses() {
+		for _, notReadyAddr := range endpoint.NotReadyAddresses {
 			fields["ready"] = false
 
-			tags["hostname"] = notReadyAddr.GetHostname()
-			tags["node_name"] = notReadyAddr.GetNodeName()
+			tags["hostname"] = notReadyAddr.Hostname
+			tags["node_name"] = stringValue(notReadyAddr.NodeName)
 			if notReadyAddr.TargetRef != nil {
-				tags[strings.ToLower(notReadyAddr.GetTargetRef().GetKind())] = notReadyAddr.GetTargetRef().GetName()
+				tags[strings.ToLower(notReadyAddr.TargetRef.Kind)] = notReadyAddr.TargetRef.Name
 			}
 
-			for _, port := range endpoint.GetPorts() {
-				fields["port"] = port.GetPort()
+			for _, port := range endpoint.Ports {
+				fields["port"] = port.Port
 
-				tags["port_name"] = port.GetName()
-				tags["port_protocol"] = port.GetProtocol()
+				tags["port_name"] = port.Name
+				tags["port_protocol"] = string(port.Protocol)
 
 				acc.AddFields(endpointMeasurement, fields, tags)
 			}
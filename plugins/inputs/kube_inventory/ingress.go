@@ -2,9 +2,8 @@ package kube_inventory
 
 import (
 	"context"
-	"time"
 
-	v1beta1EXT "github.com/ericchiang/k8s/apis/extensions/v1beta1"
+	v1beta1EXT "k8s.io/api/extensions/v1beta1"
 
 	"github.com/influxdata/telegraf"
 )
@@ -16,7 +15,7 @@ func collectIngress(ctx context.Context, acc telegraf.Accumulator, ki *Kubernete
 		return
 	}
 	for _, i := range list.Items {
-		if err = ki.gatherIngress(*i, acc); err != nil {
+		if err = ki.gatherIngress(i, acc); err != nil {
 			acc.AddError(err)
 			return
 		}
@@ -24,32 +23,35 @@ func collectIngress(ctx context.Context, acc telegraf.Accumulator, ki *Kubernete
 }
 
 func (ki *KubernetesInventory) gatherIngress(i v1beta1EXT.Ingress, acc telegraf.Accumulator) error {
-	if i.Metadata.CreationTimestamp.GetSeconds() == 0 && i.Metadata.CreationTimestamp.GetNanos() == 0 {
+	if i.CreationTimestamp.IsZero() {
 		return nil
 	}
 
 	fields := map[string]interface{}{
-		"created":    time.Unix(i.Metadata.CreationTimestamp.GetSeconds(), int64(i.Metadata.CreationTimestamp.GetNanos())).UnixNano(),
-		"generation": i.Metadata.GetGeneration(),
+		"created":    i.CreationTimestamp.UnixNano(),
+		"generation": i.Generation,
 	}
 
 	tags := map[string]string{
-		"ingress_name": i.Metadata.GetName(),
-		"namespace":    i.Metadata.GetNamespace(),
+		"ingress_name": i.Name,
+		"namespace":    i.Namespace,
 	}
 
-	for _, ingress := range i.GetStatus().GetLoadBalancer().GetIngress() {
-		tags["hostname"] = ingress.GetHostname()
-		tags["ip"] = ingress.GetIp()
+	for _, ingress := range i.Status.LoadBalancer.Ingress {
+		tags["hostname"] = ingress.Hostname
+		tags["ip"] = ingress.IP
 
-		for _, rule := range i.GetSpec().GetRules() {
-			for _, path := range rule.GetIngressRuleValue().GetHttp().GetPaths() {
-				fields["backend_service_port"] = path.GetBackend().GetServicePort().GetIntVal()
-				fields["tls"] = i.GetSpec().GetTls() != nil
+		for _, rule := range i.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				fields["backend_service_port"] = path.Backend.ServicePort.IntVal
+				fields["tls"] = i.Spec.TLS != nil
 
-				tags["backend_service_name"] = path.GetBackend().GetServiceName()
-				tags["path"] = path.GetPath()
-				tags["host"] = rule.GetHost()
+				tags["backend_service_name"] = path.Backend.ServiceName
+				tags["path"] = path.Path
+				tags["host"] = rule.Host
 
 				acc.AddFields(ingressMeasurement, fields, tags)
 			}
@@ -3,7 +3,7 @@ package kube_inventory
 import (
 	"context"
 
-	"github.com/ericchiang/k8s/apis/core/v1"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/influxdata/telegraf"
 )
@@ -15,7 +15,7 @@ func collectNodes(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesI
 		return
 	}
 	for _, n := range list.Items {
-		if err = ki.gatherNode(*n, acc); err != nil {
+		if err = ki.gatherNode(n, acc); err != nil {
 			acc.AddError(err)
 			return
 		}
@@ -25,28 +25,28 @@ func collectNodes(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesI
 func (ki *KubernetesInventory) gatherNode(n v1.Node, acc telegraf.Accumulator) error {
 	fields := map[string]interface{}{}
 	tags := map[string]string{
-		"node_name": *n.Metadata.Name,
+		"node_name": n.Name,
 	}
 
 	for resourceName, val := range n.Status.Capacity {
 		switch resourceName {
 		case "cpu":
-			fields["capacity_cpu_cores"] = atoi(val.GetString_())
+			fields["capacity_cpu_cores"] = atoi(val.String())
 		case "memory":
-			fields["capacity_memory_bytes"] = convertQuantity(val.GetString_(), 1)
+			fields["capacity_memory_bytes"] = convertQuantity(val.String(), 1)
 		case "pods":
-			fields["capacity_pods"] = atoi(val.GetString_())
+			fields["capacity_pods"] = atoi(val.String())
 		}
 	}
 
 	for resourceName, val := range n.Status.Allocatable {
 		switch resourceName {
 		case "cpu":
-			fields["allocatable_cpu_cores"] = atoi(val.GetString_())
+			fields["allocatable_cpu_cores"] = atoi(val.String())
 		case "memory":
-			fields["allocatable_memory_bytes"] = convertQuantity(val.GetString_(), 1)
+			fields["allocatable_memory_bytes"] = convertQuantity(val.String(), 1)
 		case "pods":
-			fields["allocatable_pods"] = atoi(val.GetString_())
+			fields["allocatable_pods"] = atoi(val.String())
 		}
 	}
 
@@ -4,7 +4,7 @@ import (
 	"context"
 	"strings"
 
-	"github.com/ericchiang/k8s/apis/core/v1"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/influxdata/telegraf"
 )
@@ -16,7 +16,7 @@ func collectPersistentVolumes(ctx context.Context, acc telegraf.Accumulator, ki
 		return
 	}
 	for _, pv := range list.Items {
-		if err = ki.gatherPersistentVolume(*pv, acc); err != nil {
+		if err = ki.gatherPersistentVolume(pv, acc); err != nil {
 			acc.AddError(err)
 			return
 		}
@@ -25,7 +25,7 @@ func collectPersistentVolumes(ctx context.Context, acc telegraf.Accumulator, ki
 
 func (ki *KubernetesInventory) gatherPersistentVolume(pv v1.PersistentVolume, acc telegraf.Accumulator) error {
 	phaseType := 5
-	switch strings.ToLower(pv.Status.GetPhase()) {
+	switch strings.ToLower(string(pv.Status.Phase)) {
 	case "bound":
 		phaseType = 0
 	case "failed":
@@ -41,9 +41,9 @@ func (ki *KubernetesInventory) gatherPersistentVolume(pv v1.PersistentVolume, ac
 		"phase_type": phaseType,
 	}
 	tags := map[string]string{
-		"pv_name":      pv.Metadata.GetName(),
-		"phase":        pv.Status.GetPhase(),
-		"storageclass": pv.Spec.GetStorageClassName(),
+		"pv_name":      pv.Name,
+		"phase":        string(pv.Status.Phase),
+		"storageclass": pv.Spec.StorageClassName,
 	}
 
 	acc.AddFields(persistentVolumeMeasurement, fields, tags)
@@ -4,8 +4,8 @@ import (
 	"testing"
 	"time"
 
-	"github.com/ericchiang/k8s/apis/core/v1"
-	metav1 "github.com/ericchiang/k8s/apis/meta/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -35,21 +35,21 @@ func TestPersistentVolume(t *testing.T) {
 			handler: &mockHandler{
 				responseMap: map[string]interface{}{
 					"/persistentvolumes/": &v1.PersistentVolumeList{
-						Items: []*v1.PersistentVolume{
+						Items: []v1.PersistentVolume{
 							{
-								Status: &v1.PersistentVolumeStatus{
-									Phase: toStrPtr("pending"),
+								Status: v1.PersistentVolumeStatus{
+									Phase: "pending",
 								},
-								Spec: &v1.PersistentVolumeSpec{
-									StorageClassName: toStrPtr("ebs-1"),
+								Spec: v1.PersistentVolumeSpec{
+									StorageClassName: "ebs-1",
 								},
-								Metadata: &metav1.ObjectMeta{
-									Name: toStrPtr("pv1"),
+								ObjectMeta: metav1.ObjectMeta{
+									Name: "pv1",
 									Labels: map[string]string{
 										"lab1": "v1",
 										"lab2": "v2",
 									},
-									CreationTimestamp: &metav1.Time{Seconds: toInt64Ptr(now.Unix())},
+									CreationTimestamp: metav1.Time{Time: now},
 								},
 							},
 						},
@@ -80,7 +80,7 @@ func TestPersistentVolume(t *testing.T) {
 		}
 		acc := new(testutil.Accumulator)
 		for _, pv := range ((v.handler.responseMap["/persistentvolumes/"]).(*v1.PersistentVolumeList)).Items {
-			err := ks.gatherPersistentVolume(*pv, acc)
+			err := ks.gatherPersistentVolume(pv, acc)
 			if err != nil {
 				t.Errorf("Failed to gather pv - %s", err.Error())
 			}
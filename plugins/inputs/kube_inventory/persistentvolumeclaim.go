@@ -4,7 +4,7 @@ import (
 	"context"
 	"strings"
 
-	"github.com/ericchiang/k8s/apis/core/v1"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/influxdata/telegraf"
 )
@@ -16,7 +16,7 @@ func collectPersistentVolumeClaims(ctx context.Context, acc telegraf.Accumulator
 		return
 	}
 	for _, pvc := range list.Items {
-		if err = ki.gatherPersistentVolumeClaim(*pvc, acc); err != nil {
+		if err = ki.gatherPersistentVolumeClaim(pvc, acc); err != nil {
 			acc.AddError(err)
 			return
 		}
@@ -25,7 +25,7 @@ func collectPersistentVolumeClaims(ctx context.Context, acc telegraf.Accumulator
 
 func (ki *KubernetesInventory) gatherPersistentVolumeClaim(pvc v1.PersistentVolumeClaim, acc telegraf.Accumulator) error {
 	phaseType := 3
-	switch strings.ToLower(pvc.Status.GetPhase()) {
+	switch strings.ToLower(string(pvc.Status.Phase)) {
 	case "bound":
 		phaseType = 0
 	case "lost":
@@ -37,10 +37,10 @@ func (ki *KubernetesInventory) gatherPersistentVolumeClaim(pvc v1.PersistentVolu
 		"phase_type": phaseType,
 	}
 	tags := map[string]string{
-		"pvc_name":     pvc.Metadata.GetName(),
-		"namespace":    pvc.Metadata.GetNamespace(),
-		"phase":        pvc.Status.GetPhase(),
-		"storageclass": pvc.Spec.GetStorageClassName(),
+		"pvc_name":     pvc.Name,
+		"namespace":    pvc.Namespace,
+		"phase":        string(pvc.Status.Phase),
+		"storageclass": stringValue(pvc.Spec.StorageClassName),
 	}
 
 	acc.AddFields(persistentVolumeClaimMeasurement, fields, tags)
@@ -4,8 +4,8 @@ import (
 	"testing"
 	"time"
 
-	"github.com/ericchiang/k8s/apis/core/v1"
-	metav1 "github.com/ericchiang/k8s/apis/meta/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -35,23 +35,23 @@ func TestPersistentVolumeClaim(t *testing.T) {
 			handler: &mockHandler{
 				responseMap: map[string]interface{}{
 					"/persistentvolumeclaims/": &v1.PersistentVolumeClaimList{
-						Items: []*v1.PersistentVolumeClaim{
+						Items: []v1.PersistentVolumeClaim{
 							{
-								Status: &v1.PersistentVolumeClaimStatus{
-									Phase: toStrPtr("bound"),
+								Status: v1.PersistentVolumeClaimStatus{
+									Phase: "bound",
 								},
-								Spec: &v1.PersistentVolumeClaimSpec{
-									VolumeName:       toStrPtr("pvc-dc870fd6-1e08-11e8-b226-02aa4bc06eb8"),
+								Spec: v1.PersistentVolumeClaimSpec{
+									VolumeName:       "pvc-dc870fd6-1e08-11e8-b226-02aa4bc06eb8",
 									StorageClassName: toStrPtr("ebs-1"),
 								},
-								Metadata: &metav1.ObjectMeta{
-									Namespace: toStrPtr("ns1"),
-									Name:      toStrPtr("pc1"),
+								ObjectMeta: metav1.ObjectMeta{
+									Namespace: "ns1",
+									Name:      "pc1",
 									Labels: map[string]string{
 										"lab1": "v1",
 										"lab2": "v2",
 									},
-									CreationTimestamp: &metav1.Time{Seconds: toInt64Ptr(now.Unix())},
+									CreationTimestamp: metav1.Time{Time: now},
 								},
 							},
 						},
@@ -83,7 +83,7 @@ func TestPersistentVolumeClaim(t *testing.T) {
 		}
 		acc := new(testutil.Accumulator)
 		for _, pvc := range ((v.handler.responseMap["/persistentvolumeclaims/"]).(*v1.PersistentVolumeClaimList)).Items {
-			err := ks.gatherPersistentVolumeClaim(*pvc, acc)
+			err := ks.gatherPersistentVolumeClaim(pvc, acc)
 			if err != nil {
 				t.Errorf("Failed to gather pvc - %s", err.Error())
 			}
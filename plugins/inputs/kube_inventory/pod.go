@@ -3,7 +3,7 @@ package kube_inventory
 import (
 	"context"
 
-	"github.com/ericchiang/k8s/apis/core/v1"
+	v1 "k8s.io/api/core/v1"
 
 	"github.com/influxdata/telegraf"
 )
@@ -15,7 +15,7 @@ func collectPods(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesIn
 		return
 	}
 	for _, p := range list.Items {
-		if err = ki.gatherPod(*p, acc); err != nil {
+		if err = ki.gatherPod(p, acc); err != nil {
 			acc.AddError(err)
 			return
 		}
@@ -23,13 +23,13 @@ func collectPods(ctx context.Context, acc telegraf.Accumulator, ki *KubernetesIn
 }
 
 func (ki *KubernetesInventory) gatherPod(p v1.Pod, acc telegraf.Accumulator) error {
-	if p.Metadata.CreationTimestamp.GetSeconds() == 0 && p.Metadata.CreationTimestamp.GetNanos() == 0 {
+	if p.CreationTimestamp.IsZero() {
 		return nil
 	}
 
 	for i, cs := range p.Status.ContainerStatuses {
 		c := p.Spec.Containers[i]
-		gatherPodContainer(*p.Spec.NodeName, p, *cs, *c, acc)
+		gatherPodContainer(p.Spec.NodeName, p, cs, c, acc)
 	}
 
 	return nil
@@ -50,16 +50,21 @@ func gatherPodContainer(nodeName string, p v1.Pod, cs v1.ContainerStatus, c v1.C
 		state = "waiting"
 	}
 
+	var terminatedReason string
+	if cs.State.Terminated != nil {
+		terminatedReason = cs.State.Terminated.Reason
+	}
+
 	fields := map[string]interface{}{
-		"restarts_total":    cs.GetRestartCount(),
+		"restarts_total":    cs.RestartCount,
 		"state_code":        stateCode,
-		"terminated_reason": cs.State.Terminated.GetReason(),
+		"terminated_reason": terminatedReason,
 	}
 	tags := map[string]string{
-		"container_name": *c.Name,
-		"namespace":      *p.Metadata.Namespace,
-		"node_name":      *p.Spec.NodeName,
-		"pod_name":       *p.Metadata.Name,
+		"container_name": c.Name,
+		"namespace":      p.Namespace,
+		"node_name":      p.Spec.NodeName,
+		"pod_name":       p.Name,
 		"state":          state,
 	}
 
@@ -69,17 +74,17 @@ func gatherPodContainer(nodeName string, p v1.Pod, cs v1.ContainerStatus, c v1.C
 	for resourceName, val := range req {
 		switch resourceName {
 		case "cpu":
-			fields["resource_requests_millicpu_units"] = convertQuantity(val.GetString_(), 1000)
+			fields["resource_requests_millicpu_units"] = convertQuantity(val.String(), 1000)
 		case "memory":
-			fields["resource_requests_memory_bytes"] = convertQuantity(val.GetString_(), 1)
+			fields["resource_requests_memory_bytes"] = convertQuantity(val.String(), 1)
 		}
 	}
 	for resourceName, val := range lim {
 		switch resourceName {
 		case "cpu":
-			fields["resource_limits_millicpu_units"] = convertQuantity(val.GetString_(), 1000)
+			fields["resource_limits_millicpu_units"] = convertQuantity(val.String(), 1000)
 		case "memory":
-			fields["resource_limits_memory_bytes"] = convertQuantity(val.GetString_(), 1)
+			fields["resource_limits_memory_bytes"] = convertQuantity(val.String(), 1)
 		}
 	}
 
@@ -4,10 +4,10 @@ import (
 	"testing"
 	"time"
 
-	"github.com/ericchiang/k8s/apis/core/v1"
-	metav1 "github.com/ericchiang/k8s/apis/meta/v1"
-	"github.com/ericchiang/k8s/apis/resource"
 	"github.com/influxdata/telegraf/testutil"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestPod(t *testing.T) {
@@ -38,100 +38,100 @@ func TestPod(t *testing.T) {
 			handler: &mockHandler{
 				responseMap: map[string]interface{}{
 					"/pods/": &v1.PodList{
-						Items: []*v1.Pod{
+						Items: []v1.Pod{
 							{
-								Spec: &v1.PodSpec{
-									NodeName: toStrPtr("node1"),
-									Containers: []*v1.Container{
+								Spec: v1.PodSpec{
+									NodeName: "node1",
+									Containers: []v1.Container{
 										{
-											Name:  toStrPtr("forwarder"),
-											Image: toStrPtr("image1"),
-											Ports: []*v1.ContainerPort{
+											Name:  "forwarder",
+											Image: "image1",
+											Ports: []v1.ContainerPort{
 												{
-													ContainerPort: toInt32Ptr(8080),
-													Protocol:      toStrPtr("TCP"),
+													ContainerPort: 8080,
+													Protocol:      "TCP",
 												},
 											},
-											Resources: &v1.ResourceRequirements{
-												Limits: map[string]*resource.Quantity{
-													"cpu": {String_: toStrPtr("100m")},
+											Resources: v1.ResourceRequirements{
+												Limits: v1.ResourceList{
+													"cpu": resource.MustParse("100m"),
 												},
-												Requests: map[string]*resource.Quantity{
-													"cpu": {String_: toStrPtr("100m")},
+												Requests: v1.ResourceList{
+													"cpu": resource.MustParse("100m"),
 												},
 											},
 										},
 									},
-									Volumes: []*v1.Volume{
+									Volumes: []v1.Volume{
 										{
-											Name: toStrPtr("vol1"),
-											VolumeSource: &v1.VolumeSource{
+											Name: "vol1",
+											VolumeSource: v1.VolumeSource{
 												PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
-													ClaimName: toStrPtr("pc1"),
-													ReadOnly:  toBoolPtr(true),
+													ClaimName: "pc1",
+													ReadOnly:  true,
 												},
 											},
 										},
 										{
-											Name: toStrPtr("vol2"),
+											Name: "vol2",
 										},
 									},
 								},
-								Status: &v1.PodStatus{
-									Phase:     toStrPtr("Running"),
-									HostIP:    toStrPtr("180.12.10.18"),
-									PodIP:     toStrPtr("10.244.2.15"),
-									StartTime: &metav1.Time{Seconds: toInt64Ptr(started.Unix())},
-									Conditions: []*v1.PodCondition{
+								Status: v1.PodStatus{
+									Phase:     "Running",
+									HostIP:    "180.12.10.18",
+									PodIP:     "10.244.2.15",
+									StartTime: &metav1.Time{Time: started},
+									Conditions: []v1.PodCondition{
 										{
-											Type:               toStrPtr("Initialized"),
-											Status:             toStrPtr("True"),
-											LastTransitionTime: &metav1.Time{Seconds: toInt64Ptr(cond1.Unix())},
+											Type:               "Initialized",
+											Status:             "True",
+											LastTransitionTime: metav1.Time{Time: cond1},
 										},
 										{
-											Type:               toStrPtr("Ready"),
-											Status:             toStrPtr("True"),
-											LastTransitionTime: &metav1.Time{Seconds: toInt64Ptr(cond2.Unix())},
+											Type:               "Ready",
+											Status:             "True",
+											LastTransitionTime: metav1.Time{Time: cond2},
 										},
 										{
-											Type:               toStrPtr("Scheduled"),
-											Status:             toStrPtr("True"),
-											LastTransitionTime: &metav1.Time{Seconds: toInt64Ptr(cond1.Unix())},
+											Type:               "Scheduled",
+											Status:             "True",
+											LastTransitionTime: metav1.Time{Time: cond1},
 										},
 									},
-									ContainerStatuses: []*v1.ContainerStatus{
+									ContainerStatuses: []v1.ContainerStatus{
 										{
-											Name: toStrPtr("forwarder"),
-											State: &v1.ContainerState{
+											Name: "forwarder",
+											State: v1.ContainerState{
 												Running: &v1.ContainerStateRunning{
-													StartedAt: &metav1.Time{Seconds: toInt64Ptr(cond2.Unix())},
+													StartedAt: metav1.Time{Time: cond2},
 												},
 											},
-											Ready:        toBoolPtr(true),
-											RestartCount: toInt32Ptr(3),
-											Image:        toStrPtr("image1"),
-											ImageID:      toStrPtr("image_id1"),
-											ContainerID:  toStrPtr("docker://54abe32d0094479d3d"),
+											Ready:        true,
+											RestartCount: 3,
+											Image:        "image1",
+											ImageID:      "image_id1",
+											ContainerID:  "docker://54abe32d0094479d3d",
 										},
 									},
 								},
-								Metadata: &metav1.ObjectMeta{
-									OwnerReferences: []*metav1.OwnerReference{
+								ObjectMeta: metav1.ObjectMeta{
+									OwnerReferences: []metav1.OwnerReference{
 										{
-											ApiVersion: toStrPtr("apps/v1"),
-											Kind:       toStrPtr("DaemonSet"),
-											Name:       toStrPtr("forwarder"),
+											APIVersion: "apps/v1",
+											Kind:       "DaemonSet",
+											Name:       "forwarder",
 											Controller: toBoolPtr(true),
 										},
 									},
-									Generation: toInt64Ptr(11232),
-									Namespace:  toStrPtr("ns1"),
-									Name:       toStrPtr("pod1"),
+									Generation: 11232,
+									Namespace:  "ns1",
+									Name:       "pod1",
 									Labels: map[string]string{
 										"lab1": "v1",
 										"lab2": "v2",
 									},
-									CreationTimestamp: &metav1.Time{Seconds: toInt64Ptr(created.Unix())},
+									CreationTimestamp: metav1.Time{Time: created},
 								},
 							},
 						},
@@ -167,7 +167,7 @@ func TestPod(t *testing.T) {
 		}
 		acc := new(testutil.Accumulator)
 		for _, pod := range ((v.handler.responseMap["/pods/"]).(*v1.PodList)).Items {
-			err := ks.gatherPod(*pod, acc)
+			err := ks.gatherPod(pod, acc)
 			if err != nil {
 				t.Errorf("Failed to gather pod - %s", err.Error())
 			}
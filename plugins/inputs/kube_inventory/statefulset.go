@@ -2,9 +2,8 @@ package kube_inventory
 
 import (
 	"context"
-	"time"
 
-	"github.com/ericchiang/k8s/apis/apps/v1"
+	v1 "k8s.io/api/apps/v1"
 
 	"github.com/influxdata/telegraf"
 )
@@ -16,7 +15,7 @@ func collectStatefulSets(ctx context.Context, acc telegraf.Accumulator, ki *Kube
 		return
 	}
 	for _, s := range list.Items {
-		if err = ki.gatherStatefulSet(*s, acc); err != nil {
+		if err = ki.gatherStatefulSet(s, acc); err != nil {
 			acc.AddError(err)
 			return
 		}
@@ -26,18 +25,20 @@ func collectStatefulSets(ctx context.Context, acc telegraf.Accumulator, ki *Kube
 func (ki *KubernetesInventory) gatherStatefulSet(s v1.StatefulSet, acc telegraf.Accumulator) error {
 	status := s.Status
 	fields := map[string]interface{}{
-		"created":             time.Unix(s.Metadata.CreationTimestamp.GetSeconds(), int64(s.Metadata.CreationTimestamp.GetNanos())).UnixNano(),
-		"generation":          *s.Metadata.Generation,
-		"replicas":            *status.Replicas,
-		"replicas_current":    *status.CurrentReplicas,
-		"replicas_ready":      *status.ReadyReplicas,
-		"replicas_updated":    *status.UpdatedReplicas,
-		"spec_replicas":       *s.Spec.Replicas,
-		"observed_generation": *s.Status.ObservedGeneration,
+		"created":             s.CreationTimestamp.UnixNano(),
+		"generation":          s.Generation,
+		"replicas":            status.Replicas,
+		"replicas_current":    status.CurrentReplicas,
+		"replicas_ready":      status.ReadyReplicas,
+		"replicas_updated":    status.UpdatedReplicas,
+		"observed_generation": status.ObservedGeneration,
+	}
+	if s.Spec.Replicas != nil {
+		fields["spec_replicas"] = *s.Spec.Replicas
 	}
 	tags := map[string]string{
-		"statefulset_name": *s.Metadata.Name,
-		"namespace":        *s.Metadata.Namespace,
+		"statefulset_name": s.Name,
+		"namespace":        s.Namespace,
 	}
 
 	acc.AddFields(statefulSetMeasurement, fields, tags)
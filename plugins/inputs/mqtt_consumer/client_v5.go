@@ -0,0 +1,229 @@
+package mqtt_consumer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/eclipse/paho.mqtt.golang"
+	commonmqtt "github.com/influxdata/telegraf/plugins/common/mqtt"
+)
+
+// clientV5 adapts the MQTT 5 client to the Client interface of the MQTT 3.1.1
+// client, so the plugin handles both protocols alike.
+type clientV5 struct {
+	opts              *mqtt.ClientOptions
+	topicAliasMaximum uint16
+
+	client *paho.Client
+	lost   *connectionLost
+
+	sync.Mutex
+	callback mqtt.MessageHandler
+}
+
+func newClientV5(opts *mqtt.ClientOptions, topicAliasMaximum uint16) *clientV5 {
+	return &clientV5{
+		opts:              opts,
+		topicAliasMaximum: topicAliasMaximum,
+	}
+}
+
+func (c *clientV5) Connect() mqtt.Token {
+	conn, err := commonmqtt.Dial(c.opts.Servers, c.opts.TLSConfig, c.opts.ConnectTimeout)
+	if err != nil {
+		return &token{err: err}
+	}
+
+	lost := &connectionLost{onLost: c.onConnectionLost}
+	c.lost = lost
+	c.client = paho.NewClient(paho.ClientConfig{
+		Conn: conn,
+		// The router resolves the topic aliases used by the server
+		Router:        paho.NewSingleHandlerRouter(c.route),
+		OnClientError: lost.report,
+		OnServerDisconnect: func(d *paho.Disconnect) {
+			lost.report(fmt.Errorf("disconnected by server, reason code %d", d.ReasonCode))
+		},
+	})
+
+	cp := &paho.Connect{
+		ClientID:   c.opts.ClientID,
+		KeepAlive:  uint16(c.opts.KeepAlive),
+		CleanStart: c.opts.CleanSession,
+		Properties: &paho.ConnectProperties{},
+	}
+	if c.opts.Username != "" {
+		cp.Username = c.opts.Username
+		cp.UsernameFlag = true
+	}
+	if c.opts.Password != "" {
+		cp.Password = []byte(c.opts.Password)
+		cp.PasswordFlag = true
+	}
+	if !c.opts.CleanSession {
+		// Without expiry interval the server drops the session on disconnect
+		cp.Properties.SessionExpiryInterval = paho.Uint32(math.MaxUint32)
+	}
+	if c.topicAliasMaximum > 0 {
+		cp.Properties.TopicAliasMaximum = paho.Uint16(c.topicAliasMaximum)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.ConnectTimeout)
+	defer cancel()
+	ca, err := c.client.Connect(ctx, cp)
+	if err != nil {
+		lost.ignore()
+		conn.Close()
+		return &token{err: err}
+	}
+	return &token{sessionPresent: ca.SessionPresent}
+}
+
+func (c *clientV5) SubscribeMultiple(filters map[string]byte, callback mqtt.MessageHandler) mqtt.Token {
+	c.setCallback(callback)
+
+	sub := &paho.Subscribe{
+		Subscriptions: make(map[string]paho.SubscribeOptions, len(filters)),
+	}
+	for topic, qos := range filters {
+		sub.Subscriptions[topic] = paho.SubscribeOptions{QoS: qos}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.ConnectTimeout)
+	defer cancel()
+	_, err := c.client.Subscribe(ctx, sub)
+	return &token{err: err}
+}
+
+// AddRoute sets the message handler, all messages are routed to the same
+// handler as the plugin uses one for all topics.
+func (c *clientV5) AddRoute(topic string, callback mqtt.MessageHandler) {
+	c.setCallback(callback)
+}
+
+func (c *clientV5) Disconnect(quiesce uint) {
+	if c.client == nil {
+		return
+	}
+	c.lost.ignore()
+	c.client.Disconnect(&paho.Disconnect{ReasonCode: 0})
+}
+
+func (c *clientV5) setCallback(callback mqtt.MessageHandler) {
+	c.Lock()
+	defer c.Unlock()
+	c.callback = callback
+}
+
+func (c *clientV5) onConnectionLost(err error) {
+	if c.opts.OnConnectionLost != nil {
+		c.opts.OnConnectionLost(nil, err)
+	}
+}
+
+func (c *clientV5) route(p *paho.Publish) {
+	c.Lock()
+	defer c.Unlock()
+
+	msg := &messageV5{
+		topic:    p.Topic,
+		qos:      p.QoS,
+		retained: p.Retain,
+		payload:  p.Payload,
+	}
+	if p.Properties != nil && len(p.Properties.User) > 0 {
+		msg.userProperties = make(map[string]string, len(p.Properties.User))
+		for _, property := range p.Properties.User {
+			msg.userProperties[property.Key] = property.Value
+		}
+	}
+
+	if c.callback != nil {
+		c.callback(nil, msg)
+	}
+}
+
+// connectionLost reports the loss of a connection once, unless the plugin
+// disconnects.
+type connectionLost struct {
+	onLost func(error)
+	once   sync.Once
+}
+
+func (l *connectionLost) report(err error) {
+	l.once.Do(func() {
+		l.onLost(err)
+	})
+}
+
+func (l *connectionLost) ignore() {
+	l.once.Do(func() {})
+}
+
+// token is the result of a synchronous operation of the MQTT 5 client.
+type token struct {
+	err            error
+	sessionPresent bool
+}
+
+func (t *token) Wait() bool {
+	return true
+}
+
+func (t *token) WaitTimeout(time.Duration) bool {
+	return true
+}
+
+func (t *token) Error() error {
+	return t.err
+}
+
+func (t *token) SessionPresent() bool {
+	return t.sessionPresent
+}
+
+// messageV5 is a message received with the MQTT 5 client.
+type messageV5 struct {
+	topic          string
+	qos            byte
+	retained       bool
+	payload        []byte
+	userProperties map[string]string
+}
+
+func (m *messageV5) Duplicate() bool {
+	return false
+}
+
+func (m *messageV5) Qos() byte {
+	return m.qos
+}
+
+func (m *messageV5) Retained() bool {
+	return m.retained
+}
+
+func (m *messageV5) Topic() string {
+	return m.topic
+}
+
+func (m *messageV5) MessageID() uint16 {
+	return 0
+}
+
+func (m *messageV5) Payload() []byte {
+	return m.payload
+}
+
+// Ack is a no-op, the client acknowledges messages on receipt.
+func (m *messageV5) Ack() {
+}
+
+// UserProperties returns the MQTT 5 user properties of the message.
+func (m *messageV5) UserProperties() map[string]string {
+	return m.userProperties
+}
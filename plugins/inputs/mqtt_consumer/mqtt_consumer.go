@@ -11,6 +11,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/tls"
+	commonmqtt "github.com/influxdata/telegraf/plugins/common/mqtt"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
 )
@@ -41,6 +42,23 @@ type Client interface {
 
 type ClientFactory func(o *mqtt.ClientOptions) Client
 
+// TopicParsingConfig extracts the measurement name and tags from the segments
+// of the topics matching the topic filter.
+type TopicParsingConfig struct {
+	Topic       string `toml:"topic"`
+	Measurement string `toml:"measurement"`
+	Tags        string `toml:"tags"`
+
+	filter      []string
+	measurement int
+	tags        map[int]string
+}
+
+// userPropertiesMessage is a message with MQTT 5 user properties.
+type userPropertiesMessage interface {
+	UserProperties() map[string]string
+}
+
 type MQTTConsumer struct {
 	Servers                []string          `toml:"servers"`
 	Topics                 []string          `toml:"topics"`
@@ -50,6 +68,10 @@ type MQTTConsumer struct {
 	QoS                    int               `toml:"qos"`
 	ConnectionTimeout      internal.Duration `toml:"connection_timeout"`
 	MaxUndeliveredMessages int               `toml:"max_undelivered_messages"`
+	ProtocolVersion        string            `toml:"protocol_version"`
+	TopicAliasMaximum      uint16            `toml:"topic_alias_maximum"`
+
+	TopicParsing []TopicParsingConfig `toml:"topic_parsing"`
 
 	parser parsers.Parser
 
@@ -80,13 +102,23 @@ var sampleConfig = `
   ## schema can be tcp, ssl, or ws.
   servers = ["tcp://127.0.0.1:1883"]
 
-  ## Topics that will be subscribed to.
+  ## Topics that will be subscribed to.  Shared subscriptions, of the form
+  ## "$share/<group>/<topic>", balance the messages between the consumers of
+  ## the group.
   topics = [
     "telegraf/host01/cpu",
     "telegraf/+/mem",
     "sensors/#",
   ]
 
+  ## MQTT protocol version, "3.1.1" or "5".  With MQTT 5 the user properties
+  ## of the messages are added as tags.
+  # protocol_version = "3.1.1"
+
+  ## Maximum number of topic aliases the server may use when sending messages,
+  ## only applies to MQTT 5.
+  # topic_alias_maximum = 0
+
   ## The message topic will be stored in a tag specified by this value.  If set
   ## to the empty string no topic tag will be created.
   # topic_tag = "topic"
@@ -139,6 +171,14 @@ var sampleConfig = `
   ## more about them here:
   ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
   data_format = "influx"
+
+  ## Extract the measurement name and tags from the topic of the messages
+  ## matching the topic filter.  The segments of measurement and tags name the
+  ## topic segment at the same position, "_" skips a segment.
+  # [[inputs.mqtt_consumer.topic_parsing]]
+  #   topic = "telegraf/+/cpu/+"
+  #   measurement = "_/_/measurement/_"
+  #   tags = "_/host/_/core"
 `
 
 func (m *MQTTConsumer) SampleConfig() string {
@@ -168,11 +208,21 @@ func (m *MQTTConsumer) Init() error {
 		return fmt.Errorf("connection_timeout must be greater than 1s: %s", m.ConnectionTimeout.Duration)
 	}
 
+	if err := commonmqtt.ValidateProtocolVersion(m.ProtocolVersion); err != nil {
+		return err
+	}
+
 	m.topicTag = "topic"
 	if m.TopicTag != nil {
 		m.topicTag = *m.TopicTag
 	}
 
+	for i := range m.TopicParsing {
+		if err := m.TopicParsing[i].init(); err != nil {
+			return err
+		}
+	}
+
 	opts, err := m.createOpts()
 	if err != nil {
 		return err
@@ -190,7 +240,11 @@ func (m *MQTTConsumer) Start(acc telegraf.Accumulator) error {
 	m.sem = make(semaphore, m.MaxUndeliveredMessages)
 	m.ctx, m.cancel = context.WithCancel(context.Background())
 
-	m.client = m.clientFactory(m.opts)
+	if m.ProtocolVersion == commonmqtt.ProtocolV5 {
+		m.client = newClientV5(m.opts, m.TopicAliasMaximum)
+	} else {
+		m.client = m.clientFactory(m.opts)
+	}
 
 	// AddRoute sets up the function for handling messages.  These need to be
 	// added in case we find a persistent session containing subscriptions so we
@@ -279,13 +333,25 @@ func (m *MQTTConsumer) onMessage(acc telegraf.TrackingAccumulator, msg mqtt.Mess
 		return err
 	}
 
+	topic := msg.Topic()
 	if m.topicTag != "" {
-		topic := msg.Topic()
 		for _, metric := range metrics {
 			metric.AddTag(m.topicTag, topic)
 		}
 	}
 
+	for _, p := range m.TopicParsing {
+		p.apply(topic, metrics)
+	}
+
+	if msg, ok := msg.(userPropertiesMessage); ok {
+		for k, v := range msg.UserProperties() {
+			for _, metric := range metrics {
+				metric.AddTag(k, v)
+			}
+		}
+	}
+
 	id := acc.AddTrackingMetricGroup(metrics)
 	m.messages[id] = true
 	return nil
@@ -365,6 +431,78 @@ func (m *MQTTConsumer) createOpts() (*mqtt.ClientOptions, error) {
 	return opts, nil
 }
 
+func (p *TopicParsingConfig) init() error {
+	p.filter = strings.Split(p.Topic, "/")
+	if p.Topic == "" {
+		p.filter = []string{"#"}
+	}
+	if strings.HasPrefix(p.Topic, "$share/") {
+		if len(p.filter) < 3 {
+			return fmt.Errorf("topic_parsing: invalid shared subscription %q", p.Topic)
+		}
+		p.filter = p.filter[2:]
+	}
+
+	p.measurement = -1
+	if p.Measurement != "" {
+		for i, segment := range strings.Split(p.Measurement, "/") {
+			if segment == "_" {
+				continue
+			}
+			if p.measurement >= 0 {
+				return fmt.Errorf("topic_parsing: measurement %q names more than one segment", p.Measurement)
+			}
+			p.measurement = i
+		}
+	}
+
+	p.tags = make(map[int]string)
+	if p.Tags != "" {
+		for i, segment := range strings.Split(p.Tags, "/") {
+			if segment != "_" && segment != "" {
+				p.tags[i] = segment
+			}
+		}
+	}
+	return nil
+}
+
+// apply sets the measurement name and tags of the metrics of a message, if
+// its topic matches the topic filter.
+func (p *TopicParsingConfig) apply(topic string, metrics []telegraf.Metric) {
+	segments := strings.Split(topic, "/")
+	if !matchTopic(p.filter, segments) {
+		return
+	}
+
+	for _, metric := range metrics {
+		if p.measurement >= 0 && p.measurement < len(segments) {
+			metric.SetName(segments[p.measurement])
+		}
+		for i, key := range p.tags {
+			if i < len(segments) {
+				metric.AddTag(key, segments[i])
+			}
+		}
+	}
+}
+
+// matchTopic reports if the topic matches the filter with "+" and "#"
+// wildcards.
+func matchTopic(filter []string, topic []string) bool {
+	for i, segment := range filter {
+		switch {
+		case segment == "#":
+			return true
+		case i >= len(topic):
+			return false
+		case segment != "+" && segment != topic[i]:
+			return false
+		}
+	}
+	return len(filter) == len(topic)
+}
+
 func New(factory ClientFactory) *MQTTConsumer {
 	return &MQTTConsumer{
 		Servers:                []string{"tcp://127.0.0.1:1883"},
@@ -1,9 +1,12 @@
 package mqtt_consumer
 
 import (
+	"net"
 	"testing"
 	"time"
 
+	"github.com/eclipse/paho.golang/packets"
+	"github.com/eclipse/paho.golang/paho"
 	"github.com/eclipse/paho.mqtt.golang"
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/parsers"
@@ -376,3 +379,260 @@ func TestSubscribeNotCalledIfSession(t *testing.T) {
 
 	require.Equal(t, client.subscribeCallCount, 0)
 }
+
+func TestTopicParsing(t *testing.T) {
+	tests := []struct {
+		name         string
+		topic        string
+		topicParsing []TopicParsingConfig
+		expected     []telegraf.Metric
+	}{
+		{
+			name:  "measurement and tags from topic",
+			topic: "telegraf/host01/cpu/0",
+			topicParsing: []TopicParsingConfig{
+				{
+					Topic:       "telegraf/+/cpu/+",
+					Measurement: "_/_/measurement/_",
+					Tags:        "_/host/_/core",
+				},
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{
+						"topic": "telegraf/host01/cpu/0",
+						"host":  "host01",
+						"core":  "0",
+					},
+					map[string]interface{}{
+						"time_idle": 42,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:  "shared subscription and multi-level wildcard",
+			topic: "sensors/kitchen/temperature",
+			topicParsing: []TopicParsingConfig{
+				{
+					Topic:       "$share/group/sensors/#",
+					Measurement: "_/_/measurement",
+					Tags:        "_/room",
+				},
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"temperature",
+					map[string]string{
+						"topic": "sensors/kitchen/temperature",
+						"room":  "kitchen",
+					},
+					map[string]interface{}{
+						"time_idle": 42,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:  "topic not matching the filter",
+			topic: "telegraf/host01/mem",
+			topicParsing: []TopicParsingConfig{
+				{
+					Topic: "telegraf/+/cpu/+",
+					Tags:  "_/host/_/core",
+				},
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{
+						"topic": "telegraf/host01/mem",
+					},
+					map[string]interface{}{
+						"time_idle": 42,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handler mqtt.MessageHandler
+			client := &FakeClient{
+				ConnectF: func() mqtt.Token {
+					return &FakeToken{}
+				},
+				AddRouteF: func(topic string, callback mqtt.MessageHandler) {
+					handler = callback
+				},
+				SubscribeMultipleF: func(filters map[string]byte, callback mqtt.MessageHandler) mqtt.Token {
+					return &FakeToken{}
+				},
+				DisconnectF: func(quiesce uint) {
+				},
+			}
+
+			plugin := New(func(o *mqtt.ClientOptions) Client {
+				return client
+			})
+			plugin.Log = testutil.Logger{}
+			plugin.Topics = []string{"telegraf"}
+			plugin.TopicParsing = tt.topicParsing
+
+			parser, err := parsers.NewInfluxParser()
+			require.NoError(t, err)
+			plugin.SetParser(parser)
+
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+
+			handler(nil, &messageV5{
+				topic:   tt.topic,
+				payload: []byte("cpu time_idle=42i"),
+			})
+
+			plugin.Stop()
+
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(),
+				testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestTopicParsingInvalid(t *testing.T) {
+	plugin := New(nil)
+	plugin.Log = testutil.Logger{}
+	plugin.TopicParsing = []TopicParsingConfig{
+		{
+			Topic:       "telegraf/+/cpu",
+			Measurement: "_/measurement/measurement",
+		},
+	}
+	require.Error(t, plugin.Init())
+}
+
+func TestInvalidProtocolVersion(t *testing.T) {
+	plugin := New(nil)
+	plugin.Log = testutil.Logger{}
+	plugin.ProtocolVersion = "4"
+	require.Error(t, plugin.Init())
+}
+
+func TestMQTTv5(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	connects := make(chan *packets.Connect, 1)
+	subscribes := make(chan *packets.Subscribe, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			p, err := packets.ReadPacket(conn)
+			if err != nil {
+				return
+			}
+			switch c := p.Content.(type) {
+			case *packets.Connect:
+				connects <- c
+				connack := &packets.Connack{Properties: &packets.Properties{}}
+				connack.WriteTo(conn)
+			case *packets.Subscribe:
+				subscribes <- c
+				suback := &packets.Suback{
+					PacketID:   c.PacketID,
+					Reasons:    []byte{packets.SubackGrantedQoS0},
+					Properties: &packets.Properties{},
+				}
+				suback.WriteTo(conn)
+
+				// The second message refers to the topic by its alias
+				first := &packets.Publish{
+					Topic:   "telegraf/host01/cpu",
+					Payload: []byte("cpu time_idle=42i"),
+					Properties: &packets.Properties{
+						TopicAlias: paho.Uint16(1),
+						User:       []packets.User{{Key: "region", Value: "eu"}},
+					},
+				}
+				first.WriteTo(conn)
+				second := &packets.Publish{
+					Payload: []byte("cpu time_idle=43i"),
+					Properties: &packets.Properties{
+						TopicAlias: paho.Uint16(1),
+					},
+				}
+				second.WriteTo(conn)
+			}
+		}
+	}()
+
+	plugin := New(nil)
+	plugin.Log = testutil.Logger{}
+	plugin.Servers = []string{"tcp://" + listener.Addr().String()}
+	plugin.Topics = []string{"$share/telegraf/telegraf/+/cpu"}
+	plugin.ProtocolVersion = "5"
+	plugin.TopicAliasMaximum = 10
+	plugin.TopicParsing = []TopicParsingConfig{
+		{
+			Topic: "telegraf/+/cpu",
+			Tags:  "_/host/_",
+		},
+	}
+
+	parser, err := parsers.NewInfluxParser()
+	require.NoError(t, err)
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	acc.Wait(2)
+	plugin.Stop()
+
+	connect := <-connects
+	require.Equal(t, byte(5), connect.ProtocolVersion)
+	require.Equal(t, uint16(10), *connect.Properties.TopicAliasMaximum)
+
+	subscribe := <-subscribes
+	require.Contains(t, subscribe.Subscriptions, "$share/telegraf/telegraf/+/cpu")
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{
+				"topic":  "telegraf/host01/cpu",
+				"host":   "host01",
+				"region": "eu",
+			},
+			map[string]interface{}{
+				"time_idle": 42,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{
+				"topic": "telegraf/host01/cpu",
+				"host":  "host01",
+			},
+			map[string]interface{}{
+				"time_idle": 43,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(),
+		testutil.SortMetrics(), testutil.IgnoreTime())
+}
@@ -0,0 +1,437 @@
+package netflow
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+const (
+	metricName        = "netflow"
+	optionsMetricName = "netflow_options"
+
+	// variableLength marks IPFIX information elements whose length is encoded
+	// in each record.
+	variableLength = 65535
+
+	enterpriseBit = 0x8000
+)
+
+type templateField struct {
+	enterprise uint32
+	id         uint16
+	length     uint16
+	scope      bool
+}
+
+type template struct {
+	fields  []templateField
+	options bool
+	updated time.Time
+}
+
+// minLength is the smallest possible length of a record of the template,
+// variable length elements taking at least one byte.
+func (t *template) minLength() int {
+	var n int
+	for _, f := range t.fields {
+		if f.length == variableLength {
+			n++
+		} else {
+			n += int(f.length)
+		}
+	}
+	return n
+}
+
+// templateKey identifies a template of an exporter.  Template IDs are only
+// unique within the observation domain, or source ID with NetFlow v9.
+type templateKey struct {
+	exporter string
+	version  uint16
+	domain   uint32
+	id       uint16
+}
+
+// Decoder decodes NetFlow v5, v9 and IPFIX packets into metrics.  The
+// templates received from each exporter are cached until they expire.
+type Decoder struct {
+	TemplateTimeout time.Duration
+	Log             telegraf.Logger
+
+	elements map[elementKey]element
+	now      func() time.Time
+
+	sync.Mutex
+	templates map[templateKey]*template
+}
+
+// NewDecoder creates a decoder using the given definitions for custom
+// information elements.
+func NewDecoder(fields []FieldDefinition) (*Decoder, error) {
+	d := &Decoder{
+		TemplateTimeout: defaultTemplateTimeout,
+		elements:        map[elementKey]element{},
+		now:             time.Now,
+		templates:       map[templateKey]*template{},
+	}
+	for _, f := range fields {
+		if f.Name == "" {
+			return nil, fmt.Errorf("missing name for element %d of enterprise %d", f.ID, f.Enterprise)
+		}
+		typ := typeHex
+		if f.Type != "" {
+			var ok bool
+			if typ, ok = valueTypes[f.Type]; !ok {
+				return nil, fmt.Errorf("invalid type %q for element %q", f.Type, f.Name)
+			}
+		}
+		d.elements[elementKey{f.Enterprise, f.ID}] = element{name: f.Name, typ: typ, isTag: f.IsTag}
+	}
+	return d, nil
+}
+
+// Decode decodes a packet received from the exporter.
+func (d *Decoder) Decode(exporter string, buf []byte) ([]telegraf.Metric, error) {
+	if len(buf) < 2 {
+		return nil, fmt.Errorf("packet too short")
+	}
+
+	switch version := binary.BigEndian.Uint16(buf); version {
+	case 5:
+		return d.decodeV5(exporter, buf)
+	case 9:
+		return d.decodeV9(exporter, buf)
+	case 10:
+		return d.decodeIPFIX(exporter, buf)
+	default:
+		return nil, fmt.Errorf("unsupported version %d", version)
+	}
+}
+
+// ExpireTemplates removes the templates that have not been refreshed within
+// the template timeout.
+func (d *Decoder) ExpireTemplates() {
+	d.Lock()
+	defer d.Unlock()
+
+	now := d.now()
+	for key, t := range d.templates {
+		if now.Sub(t.updated) > d.TemplateTimeout {
+			delete(d.templates, key)
+		}
+	}
+}
+
+func (d *Decoder) setTemplate(key templateKey, t *template) {
+	d.Lock()
+	defer d.Unlock()
+	t.updated = d.now()
+	d.templates[key] = t
+}
+
+func (d *Decoder) getTemplate(key templateKey) *template {
+	d.Lock()
+	defer d.Unlock()
+	t, ok := d.templates[key]
+	if !ok {
+		return nil
+	}
+	if d.now().Sub(t.updated) > d.TemplateTimeout {
+		delete(d.templates, key)
+		return nil
+	}
+	return t
+}
+
+// withdrawTemplates removes all templates of an observation domain, or only
+// the options templates.
+func (d *Decoder) withdrawTemplates(key templateKey, options bool) {
+	d.Lock()
+	defer d.Unlock()
+	for k, t := range d.templates {
+		if k.exporter == key.exporter && k.version == key.version && k.domain == key.domain && t.options == options {
+			delete(d.templates, k)
+		}
+	}
+}
+
+func (d *Decoder) decodeV9(exporter string, buf []byte) ([]telegraf.Metric, error) {
+	const headerLength = 20
+	if len(buf) < headerLength {
+		return nil, fmt.Errorf("packet too short for NetFlow v9 header")
+	}
+	sourceID := binary.BigEndian.Uint32(buf[16:])
+
+	tags := map[string]string{
+		"source":  exporter,
+		"version": "NetFlowV9",
+	}
+
+	var metrics []telegraf.Metric
+	for sets := buf[headerLength:]; len(sets) >= 4; {
+		id := binary.BigEndian.Uint16(sets)
+		length := int(binary.BigEndian.Uint16(sets[2:]))
+		if length < 4 || length > len(sets) {
+			return nil, fmt.Errorf("invalid length %d of flowset %d", length, id)
+		}
+		body := sets[4:length]
+		sets = sets[length:]
+
+		key := templateKey{exporter: exporter, version: 9, domain: sourceID}
+		switch {
+		case id == 0:
+			if err := d.decodeV9Templates(key, body); err != nil {
+				return nil, err
+			}
+		case id == 1:
+			if err := d.decodeV9OptionsTemplates(key, body); err != nil {
+				return nil, err
+			}
+		case id >= 256:
+			key.id = id
+			m, err := d.decodeDataSet(key, body, tags)
+			if err != nil {
+				return nil, err
+			}
+			metrics = append(metrics, m...)
+		}
+	}
+	return metrics, nil
+}
+
+func (d *Decoder) decodeV9Templates(key templateKey, body []byte) error {
+	for len(body) >= 4 {
+		key.id = binary.BigEndian.Uint16(body)
+		count := int(binary.BigEndian.Uint16(body[2:]))
+		body = body[4:]
+		if len(body) < 4*count {
+			return fmt.Errorf("template %d too short", key.id)
+		}
+
+		t := &template{}
+		for i := 0; i < count; i++ {
+			t.fields = append(t.fields, templateField{
+				id:     binary.BigEndian.Uint16(body),
+				length: binary.BigEndian.Uint16(body[2:]),
+			})
+			body = body[4:]
+		}
+		d.setTemplate(key, t)
+	}
+	return nil
+}
+
+func (d *Decoder) decodeV9OptionsTemplates(key templateKey, body []byte) error {
+	for len(body) >= 6 {
+		key.id = binary.BigEndian.Uint16(body)
+		scopeLength := int(binary.BigEndian.Uint16(body[2:]))
+		optionLength := int(binary.BigEndian.Uint16(body[4:]))
+		body = body[6:]
+		if scopeLength%4 != 0 || optionLength%4 != 0 || len(body) < scopeLength+optionLength {
+			return fmt.Errorf("invalid options template %d", key.id)
+		}
+
+		t := &template{options: true}
+		for i := 0; i < scopeLength+optionLength; i += 4 {
+			t.fields = append(t.fields, templateField{
+				id:     binary.BigEndian.Uint16(body[i:]),
+				length: binary.BigEndian.Uint16(body[i+2:]),
+				scope:  i < scopeLength,
+			})
+		}
+		body = body[scopeLength+optionLength:]
+		d.setTemplate(key, t)
+	}
+	return nil
+}
+
+func (d *Decoder) decodeIPFIX(exporter string, buf []byte) ([]telegraf.Metric, error) {
+	const headerLength = 16
+	if len(buf) < headerLength {
+		return nil, fmt.Errorf("packet too short for IPFIX header")
+	}
+	length := int(binary.BigEndian.Uint16(buf[2:]))
+	if length < headerLength || length > len(buf) {
+		return nil, fmt.Errorf("invalid IPFIX message length %d", length)
+	}
+	domain := binary.BigEndian.Uint32(buf[12:])
+
+	tags := map[string]string{
+		"source":  exporter,
+		"version": "IPFIX",
+	}
+
+	var metrics []telegraf.Metric
+	for sets := buf[headerLength:length]; len(sets) >= 4; {
+		id := binary.BigEndian.Uint16(sets)
+		length := int(binary.BigEndian.Uint16(sets[2:]))
+		if length < 4 || length > len(sets) {
+			return nil, fmt.Errorf("invalid length %d of set %d", length, id)
+		}
+		body := sets[4:length]
+		sets = sets[length:]
+
+		key := templateKey{exporter: exporter, version: 10, domain: domain}
+		switch {
+		case id == 2 || id == 3:
+			if err := d.decodeIPFIXTemplates(key, body, id == 3); err != nil {
+				return nil, err
+			}
+		case id >= 256:
+			key.id = id
+			m, err := d.decodeDataSet(key, body, tags)
+			if err != nil {
+				return nil, err
+			}
+			metrics = append(metrics, m...)
+		}
+	}
+	return metrics, nil
+}
+
+func (d *Decoder) decodeIPFIXTemplates(key templateKey, body []byte, options bool) error {
+	headerLength := 4
+	if options {
+		headerLength = 6
+	}
+
+	for len(body) >= 4 {
+		key.id = binary.BigEndian.Uint16(body)
+		count := int(binary.BigEndian.Uint16(body[2:]))
+
+		// A template without fields withdraws the template, see RFC 7011
+		// section 8.1.
+		if count == 0 {
+			if key.id == 2 || key.id == 3 {
+				d.withdrawTemplates(key, key.id == 3)
+			} else {
+				d.Lock()
+				delete(d.templates, key)
+				d.Unlock()
+			}
+			body = body[4:]
+			continue
+		}
+
+		if len(body) < headerLength {
+			return fmt.Errorf("template %d too short", key.id)
+		}
+		var scopeCount int
+		if options {
+			scopeCount = int(binary.BigEndian.Uint16(body[4:]))
+		}
+		body = body[headerLength:]
+
+		t := &template{options: options}
+		for i := 0; i < count; i++ {
+			if len(body) < 4 {
+				return fmt.Errorf("template %d too short", key.id)
+			}
+			f := templateField{
+				id:     binary.BigEndian.Uint16(body),
+				length: binary.BigEndian.Uint16(body[2:]),
+				scope:  i < scopeCount,
+			}
+			body = body[4:]
+			if f.id&enterpriseBit != 0 {
+				if len(body) < 4 {
+					return fmt.Errorf("template %d too short", key.id)
+				}
+				f.id &^= enterpriseBit
+				f.enterprise = binary.BigEndian.Uint32(body)
+				body = body[4:]
+			}
+			t.fields = append(t.fields, f)
+		}
+		d.setTemplate(key, t)
+	}
+	return nil
+}
+
+func (d *Decoder) decodeDataSet(key templateKey, body []byte, tags map[string]string) ([]telegraf.Metric, error) {
+	t := d.getTemplate(key)
+	if t == nil {
+		if d.Log != nil {
+			d.Log.Debugf("Dropping data of unknown template %d from %s", key.id, key.exporter)
+		}
+		return nil, nil
+	}
+
+	name := metricName
+	if t.options {
+		name = optionsMetricName
+	}
+
+	var metrics []telegraf.Metric
+	minLength := t.minLength()
+	// The remaining bytes are padding once they cannot hold another record.
+	for minLength > 0 && len(body) >= minLength {
+		rtags := make(map[string]string, len(tags))
+		for k, v := range tags {
+			rtags[k] = v
+		}
+		fields := map[string]interface{}{}
+
+		for _, f := range t.fields {
+			length := int(f.length)
+			if f.length == variableLength {
+				if len(body) < 1 {
+					return nil, fmt.Errorf("record of template %d too short", key.id)
+				}
+				length = int(body[0])
+				body = body[1:]
+				if length == 255 {
+					if len(body) < 2 {
+						return nil, fmt.Errorf("record of template %d too short", key.id)
+					}
+					length = int(binary.BigEndian.Uint16(body))
+					body = body[2:]
+				}
+			}
+			if len(body) < length {
+				return nil, fmt.Errorf("record of template %d too short", key.id)
+			}
+			raw := body[:length]
+			body = body[length:]
+
+			var e element
+			if t.options && f.scope && key.version == 9 {
+				var ok bool
+				if e, ok = scopeElements[f.id]; !ok {
+					e = element{name: fmt.Sprintf("scope_%d", f.id), typ: typeHex, isTag: true}
+				}
+			} else {
+				e = d.lookupElement(f.enterprise, f.id)
+				e.isTag = e.isTag || f.scope
+			}
+
+			v, err := decodeValue(e.typ, raw)
+			if err != nil {
+				if d.Log != nil {
+					d.Log.Debugf("Skipping element %q of template %d: %v", e.name, key.id, err)
+				}
+				continue
+			}
+			if e.isTag {
+				rtags[e.name] = formatTag(v)
+			} else {
+				fields[e.name] = v
+			}
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+		m, err := metric.New(name, rtags, fields, d.now())
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
@@ -0,0 +1,330 @@
+package netflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// packet builds packets in network byte order.
+type packet []byte
+
+func (p packet) u8(v uint8) packet {
+	return append(p, v)
+}
+
+func (p packet) u16(v uint16) packet {
+	return append(p, byte(v>>8), byte(v))
+}
+
+func (p packet) u32(v uint32) packet {
+	return append(p, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func (p packet) raw(b ...byte) packet {
+	return append(p, b...)
+}
+
+// set prefixes the body with the id and length of a NetFlow v9 flowset or
+// IPFIX set.
+func set(id uint16, body packet) packet {
+	return packet{}.u16(id).u16(uint16(len(body) + 4)).raw(body...)
+}
+
+func v9Packet(sets ...packet) packet {
+	p := packet{}.u16(9).u16(uint16(len(sets))).u32(1000).u32(1584473704).u32(1).u32(42)
+	for _, s := range sets {
+		p = p.raw(s...)
+	}
+	return p
+}
+
+func ipfixPacket(sets ...packet) packet {
+	var body packet
+	for _, s := range sets {
+		body = body.raw(s...)
+	}
+	p := packet{}.u16(10).u16(uint16(len(body) + 16)).u32(1584473704).u32(1).u32(7)
+	return p.raw(body...)
+}
+
+func newTestDecoder(t *testing.T, fields ...FieldDefinition) *Decoder {
+	d, err := NewDecoder(fields)
+	require.NoError(t, err)
+	d.Log = testutil.Logger{}
+	d.now = func() time.Time { return time.Unix(0, 0) }
+	return d
+}
+
+func TestDecodeV5(t *testing.T) {
+	d := newTestDecoder(t)
+
+	p := packet{}.u16(5).u16(1).u32(1000).u32(1584473704).u32(0).u32(1).u8(1).u8(2).u16(0x4000 | 100)
+	p = p.raw(192, 168, 1, 10).raw(10, 0, 0, 1).raw(192, 168, 1, 1)
+	p = p.u16(3).u16(4).u32(10).u32(1500).u32(900).u32(990)
+	p = p.u16(443).u16(51234).u8(0).u8(0x18).u8(6).u8(0)
+	p = p.u16(65001).u16(65002).u8(24).u8(8).u16(0)
+
+	metrics, err := d.Decode("10.1.1.1", p)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"netflow",
+			map[string]string{
+				"source":         "10.1.1.1",
+				"version":        "NetFlowV5",
+				"engine_type":    "1",
+				"engine_id":      "2",
+				"src_ip":         "192.168.1.10",
+				"dst_ip":         "10.0.0.1",
+				"next_hop":       "192.168.1.1",
+				"input_ifindex":  "3",
+				"output_ifindex": "4",
+				"src_port":       "443",
+				"dst_port":       "51234",
+				"protocol":       "tcp",
+				"src_as":         "65001",
+				"dst_as":         "65002",
+				"src_mask_len":   "24",
+				"dst_mask_len":   "8",
+			},
+			map[string]interface{}{
+				"packets":                uint64(10),
+				"bytes":                  uint64(1500),
+				"flow_start_sys_up_time": uint64(900),
+				"flow_end_sys_up_time":   uint64(990),
+				"tcp_flags":              uint64(0x18),
+				"ip_tos":                 uint64(0),
+				"sampling_interval":      uint64(100),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+
+	_, err = d.Decode("10.1.1.1", p[:len(p)-1])
+	require.Error(t, err)
+}
+
+func TestDecodeV9(t *testing.T) {
+	d := newTestDecoder(t)
+
+	template := set(0, packet{}.
+		u16(256).u16(5).
+		u16(8).u16(4).  // src_ip
+		u16(12).u16(4). // dst_ip
+		u16(4).u16(1).  // protocol
+		u16(1).u16(4).  // bytes
+		u16(2).u16(4),  // packets
+	)
+	data := set(256, packet{}.
+		raw(10, 0, 0, 1).raw(10, 0, 0, 2).u8(17).u32(1200).u32(3).
+		raw(10, 0, 0, 3).raw(10, 0, 0, 4).u8(1).u32(84).u32(1).
+		raw(0, 0, 0), // padding
+	)
+
+	// Data is dropped until the template is known.
+	metrics, err := d.Decode("10.1.1.1", v9Packet(data))
+	require.NoError(t, err)
+	require.Empty(t, metrics)
+
+	metrics, err = d.Decode("10.1.1.1", v9Packet(template, data))
+	require.NoError(t, err)
+
+	tags := map[string]string{"source": "10.1.1.1", "version": "NetFlowV9"}
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"netflow",
+			merge(tags, map[string]string{"src_ip": "10.0.0.1", "dst_ip": "10.0.0.2", "protocol": "udp"}),
+			map[string]interface{}{"bytes": uint64(1200), "packets": uint64(3)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"netflow",
+			merge(tags, map[string]string{"src_ip": "10.0.0.3", "dst_ip": "10.0.0.4", "protocol": "icmp"}),
+			map[string]interface{}{"bytes": uint64(84), "packets": uint64(1)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+
+	// Templates are cached per exporter.
+	metrics, err = d.Decode("10.1.1.2", v9Packet(data))
+	require.NoError(t, err)
+	require.Empty(t, metrics)
+}
+
+func TestDecodeV9Options(t *testing.T) {
+	d := newTestDecoder(t)
+
+	template := set(1, packet{}.
+		u16(257).u16(4).u16(8).
+		u16(2).u16(4).  // scope interface
+		u16(34).u16(4). // sampling_interval
+		u16(82).u16(8), // interface_name
+	)
+	data := set(257, packet{}.u32(5).u32(1000).raw([]byte("eth0\x00\x00\x00\x00")...))
+
+	metrics, err := d.Decode("10.1.1.1", v9Packet(template, data))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"netflow_options",
+			map[string]string{
+				"source":          "10.1.1.1",
+				"version":         "NetFlowV9",
+				"scope_interface": "5",
+				"interface_name":  "eth0",
+			},
+			map[string]interface{}{"sampling_interval": uint64(1000)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestDecodeIPFIX(t *testing.T) {
+	d := newTestDecoder(t, FieldDefinition{
+		Enterprise: 9,
+		ID:         12235,
+		Name:       "application_name",
+		Type:       "string",
+		IsTag:      true,
+	})
+
+	template := set(2, packet{}.
+		u16(300).u16(5).
+		u16(27).u16(16).                             // src_ip
+		u16(7).u16(2).                               // src_port
+		u16(1).u16(8).                               // bytes
+		u16(12235|enterpriseBit).u16(65535).u32(9).  // custom, variable length
+		u16(1|enterpriseBit).u16(8).u32(reversePEN), // reverse bytes
+	)
+	data := set(300, packet{}.
+		raw(0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1).
+		u16(8080).
+		u32(0).u32(4096).
+		u8(5).raw([]byte("https")...).
+		u32(0).u32(512),
+	)
+
+	metrics, err := d.Decode("2001:db8::ff", ipfixPacket(template, data))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"netflow",
+			map[string]string{
+				"source":           "2001:db8::ff",
+				"version":          "IPFIX",
+				"src_ip":           "2001:db8::1",
+				"src_port":         "8080",
+				"application_name": "https",
+			},
+			map[string]interface{}{
+				"bytes":         uint64(4096),
+				"reverse_bytes": uint64(512),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+
+	// Withdraw the template.
+	withdrawal := set(2, packet{}.u16(300).u16(0))
+	metrics, err = d.Decode("2001:db8::ff", ipfixPacket(withdrawal, data))
+	require.NoError(t, err)
+	require.Empty(t, metrics)
+}
+
+func TestDecodeIPFIXUnknownElement(t *testing.T) {
+	d := newTestDecoder(t)
+
+	template := set(2, packet{}.
+		u16(301).u16(2).
+		u16(1).u16(4).
+		u16(500|enterpriseBit).u16(2).u32(1234),
+	)
+	data := set(301, packet{}.u32(10).u16(0xbeef))
+
+	metrics, err := d.Decode("10.1.1.1", ipfixPacket(template, data))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]interface{}{
+		"bytes":       uint64(10),
+		"ie_1234_500": "beef",
+	}, metrics[0].Fields())
+}
+
+func TestTemplateExpiry(t *testing.T) {
+	d := newTestDecoder(t)
+	d.TemplateTimeout = time.Minute
+
+	template := set(0, packet{}.u16(256).u16(1).u16(1).u16(4))
+	data := set(256, packet{}.u32(100))
+
+	metrics, err := d.Decode("10.1.1.1", v9Packet(template, data))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+
+	d.now = func() time.Time { return time.Unix(61, 0) }
+	metrics, err = d.Decode("10.1.1.1", v9Packet(data))
+	require.NoError(t, err)
+	require.Empty(t, metrics)
+
+	_, err = d.Decode("10.1.1.1", v9Packet(template))
+	require.NoError(t, err)
+	d.now = func() time.Time { return time.Unix(200, 0) }
+	d.ExpireTemplates()
+	require.Empty(t, d.templates)
+}
+
+func TestInvalidPackets(t *testing.T) {
+	d := newTestDecoder(t)
+
+	for name, p := range map[string]packet{
+		"empty":           {},
+		"version":         packet{}.u16(7),
+		"v9 header":       packet{}.u16(9).u16(0),
+		"ipfix length":    packet{}.u16(10).u16(200).u32(0).u32(0).u32(0),
+		"flowset length":  v9Packet(packet{}.u16(256).u16(100)),
+		"template length": v9Packet(set(0, packet{}.u16(256).u16(3).u16(1).u16(4))),
+	} {
+		_, err := d.Decode("10.1.1.1", p)
+		require.Error(t, err, name)
+	}
+}
+
+func TestInvalidFieldDefinition(t *testing.T) {
+	_, err := NewDecoder([]FieldDefinition{{ID: 1, Name: "bytes", Type: "number"}})
+	require.Error(t, err)
+
+	_, err = NewDecoder([]FieldDefinition{{ID: 1}})
+	require.Error(t, err)
+}
+
+func merge(a, b map[string]string) map[string]string {
+	m := make(map[string]string, len(a)+len(b))
+	for k, v := range a {
+		m[k] = v
+	}
+	for k, v := range b {
+		m[k] = v
+	}
+	return m
+}
+
+func TestDecodeValue(t *testing.T) {
+	v, err := decodeValue(typeInt, []byte{0xff, 0xfe})
+	require.NoError(t, err)
+	require.Equal(t, int64(-2), v)
+
+	v, err = decodeValue(typeUint, []byte{0x01, 0x00, 0x00})
+	require.NoError(t, err)
+	require.Equal(t, uint64(65536), v)
+}
@@ -0,0 +1,318 @@
+package netflow
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// reversePEN is the private enterprise number marking reverse direction
+// information elements of biflows, see RFC 5103.
+const reversePEN = 29305
+
+type valueType int
+
+const (
+	typeUint valueType = iota
+	typeInt
+	typeFloat
+	typeString
+	typeIP
+	typeMAC
+	typeHex
+	typeBool
+	typeProtocol
+	typeDirection
+)
+
+var valueTypes = map[string]valueType{
+	"uint":   typeUint,
+	"int":    typeInt,
+	"float":  typeFloat,
+	"string": typeString,
+	"ip":     typeIP,
+	"mac":    typeMAC,
+	"hex":    typeHex,
+	"bool":   typeBool,
+}
+
+// element describes how an information element is turned into a tag or
+// field.
+type element struct {
+	name  string
+	typ   valueType
+	isTag bool
+}
+
+type elementKey struct {
+	enterprise uint32
+	id         uint16
+}
+
+// elements maps the IANA IPFIX information elements to tags and fields.
+// NetFlow v9 field types share the numbering of the IPFIX elements.  Names
+// overlapping with the sflow input use the same tags and fields.
+var elements = map[uint16]element{
+	1:   {"bytes", typeUint, false},
+	2:   {"packets", typeUint, false},
+	3:   {"flows", typeUint, false},
+	4:   {"protocol", typeProtocol, true},
+	5:   {"ip_tos", typeUint, false},
+	6:   {"tcp_flags", typeUint, false},
+	7:   {"src_port", typeUint, true},
+	8:   {"src_ip", typeIP, true},
+	9:   {"src_mask_len", typeUint, true},
+	10:  {"input_ifindex", typeUint, true},
+	11:  {"dst_port", typeUint, true},
+	12:  {"dst_ip", typeIP, true},
+	13:  {"dst_mask_len", typeUint, true},
+	14:  {"output_ifindex", typeUint, true},
+	15:  {"next_hop", typeIP, true},
+	16:  {"src_as", typeUint, true},
+	17:  {"dst_as", typeUint, true},
+	18:  {"bgp_next_hop", typeIP, true},
+	19:  {"out_multicast_packets", typeUint, false},
+	20:  {"out_multicast_bytes", typeUint, false},
+	21:  {"flow_end_sys_up_time", typeUint, false},
+	22:  {"flow_start_sys_up_time", typeUint, false},
+	23:  {"out_bytes", typeUint, false},
+	24:  {"out_packets", typeUint, false},
+	25:  {"min_ip_total_length", typeUint, false},
+	26:  {"max_ip_total_length", typeUint, false},
+	27:  {"src_ip", typeIP, true},
+	28:  {"dst_ip", typeIP, true},
+	29:  {"src_mask_len", typeUint, true},
+	30:  {"dst_mask_len", typeUint, true},
+	31:  {"ipv6_flow_label", typeUint, false},
+	32:  {"icmp_type_code", typeUint, false},
+	33:  {"igmp_type", typeUint, false},
+	34:  {"sampling_interval", typeUint, false},
+	35:  {"sampling_algorithm", typeUint, false},
+	36:  {"flow_active_timeout", typeUint, false},
+	37:  {"flow_idle_timeout", typeUint, false},
+	38:  {"engine_type", typeUint, true},
+	39:  {"engine_id", typeUint, true},
+	40:  {"exported_bytes", typeUint, false},
+	41:  {"exported_messages", typeUint, false},
+	42:  {"exported_flows", typeUint, false},
+	44:  {"src_prefix", typeIP, true},
+	45:  {"dst_prefix", typeIP, true},
+	46:  {"mpls_top_label_type", typeUint, false},
+	47:  {"mpls_top_label_ip", typeIP, false},
+	48:  {"sampler_id", typeUint, true},
+	49:  {"sampler_mode", typeUint, false},
+	50:  {"sampler_random_interval", typeUint, false},
+	52:  {"min_ttl", typeUint, false},
+	53:  {"max_ttl", typeUint, false},
+	54:  {"fragment_id", typeUint, false},
+	55:  {"out_ip_tos", typeUint, false},
+	56:  {"src_mac", typeMAC, true},
+	57:  {"out_dst_mac", typeMAC, true},
+	58:  {"src_vlan", typeUint, true},
+	59:  {"dst_vlan", typeUint, true},
+	60:  {"ip_version", typeUint, true},
+	61:  {"direction", typeDirection, true},
+	62:  {"next_hop", typeIP, true},
+	63:  {"bgp_next_hop", typeIP, true},
+	64:  {"ipv6_extension_headers", typeUint, false},
+	70:  {"mpls_label_1", typeUint, false},
+	71:  {"mpls_label_2", typeUint, false},
+	72:  {"mpls_label_3", typeUint, false},
+	73:  {"mpls_label_4", typeUint, false},
+	74:  {"mpls_label_5", typeUint, false},
+	75:  {"mpls_label_6", typeUint, false},
+	76:  {"mpls_label_7", typeUint, false},
+	77:  {"mpls_label_8", typeUint, false},
+	78:  {"mpls_label_9", typeUint, false},
+	79:  {"mpls_label_10", typeUint, false},
+	80:  {"dst_mac", typeMAC, true},
+	81:  {"out_src_mac", typeMAC, true},
+	82:  {"interface_name", typeString, true},
+	83:  {"interface_description", typeString, false},
+	85:  {"total_bytes", typeUint, false},
+	86:  {"total_packets", typeUint, false},
+	88:  {"fragment_offset", typeUint, false},
+	89:  {"forwarding_status", typeUint, false},
+	90:  {"mpls_vpn_rd", typeHex, true},
+	94:  {"application_description", typeString, false},
+	95:  {"application_id", typeHex, true},
+	96:  {"application_name", typeString, true},
+	98:  {"out_ip_dscp", typeUint, false},
+	128: {"bgp_next_adjacent_as", typeUint, true},
+	129: {"bgp_prev_adjacent_as", typeUint, true},
+	130: {"exporter_ip", typeIP, true},
+	131: {"exporter_ip", typeIP, true},
+	136: {"flow_end_reason", typeUint, false},
+	138: {"observation_point_id", typeUint, true},
+	139: {"icmp_type_code", typeUint, false},
+	144: {"exporting_process_id", typeUint, true},
+	148: {"flow_id", typeUint, false},
+	149: {"observation_domain_id", typeUint, true},
+	150: {"flow_start_seconds", typeUint, false},
+	151: {"flow_end_seconds", typeUint, false},
+	152: {"flow_start_milliseconds", typeUint, false},
+	153: {"flow_end_milliseconds", typeUint, false},
+	160: {"system_init_time_milliseconds", typeUint, false},
+	161: {"flow_duration_milliseconds", typeUint, false},
+	176: {"icmp_type", typeUint, false},
+	177: {"icmp_code", typeUint, false},
+	178: {"icmp_type", typeUint, false},
+	179: {"icmp_code", typeUint, false},
+	180: {"src_port", typeUint, true},
+	181: {"dst_port", typeUint, true},
+	182: {"src_port", typeUint, true},
+	183: {"dst_port", typeUint, true},
+	192: {"ip_ttl", typeUint, false},
+	195: {"ip_dscp", typeUint, false},
+	196: {"ip_precedence", typeUint, false},
+	224: {"ip_total_length", typeUint, false},
+	225: {"post_nat_src_ip", typeIP, true},
+	226: {"post_nat_dst_ip", typeIP, true},
+	227: {"post_nat_src_port", typeUint, true},
+	228: {"post_nat_dst_port", typeUint, true},
+	230: {"nat_event", typeUint, false},
+	234: {"input_vrf", typeUint, true},
+	235: {"output_vrf", typeUint, true},
+	236: {"vrf_name", typeString, true},
+	239: {"biflow_direction", typeUint, false},
+	243: {"dot1q_vlan", typeUint, true},
+	256: {"ether_type", typeUint, true},
+	281: {"post_nat_src_ip", typeIP, true},
+	282: {"post_nat_dst_ip", typeIP, true},
+}
+
+// scopeElements maps the scope field types of NetFlow v9 options templates.
+var scopeElements = map[uint16]element{
+	1: {"scope_system", typeHex, true},
+	2: {"scope_interface", typeUint, true},
+	3: {"scope_line_card", typeUint, true},
+	4: {"scope_cache", typeHex, true},
+	5: {"scope_template", typeUint, true},
+}
+
+var protocolNames = map[uint64]string{
+	1:   "icmp",
+	2:   "igmp",
+	6:   "tcp",
+	17:  "udp",
+	47:  "gre",
+	50:  "esp",
+	51:  "ah",
+	58:  "ipv6-icmp",
+	89:  "ospf",
+	132: "sctp",
+}
+
+// lookupElement returns the definition of an information element, custom
+// definitions taking precedence over the standard ones.
+func (d *Decoder) lookupElement(enterprise uint32, id uint16) element {
+	if e, ok := d.elements[elementKey{enterprise, id}]; ok {
+		return e
+	}
+
+	switch enterprise {
+	case 0:
+		if e, ok := elements[id]; ok {
+			return e
+		}
+		return element{name: "ie_" + strconv.Itoa(int(id)), typ: typeHex}
+	case reversePEN:
+		if e, ok := elements[id]; ok && !e.isTag {
+			e.name = "reverse_" + e.name
+			return e
+		}
+	}
+	return element{
+		name: "ie_" + strconv.FormatUint(uint64(enterprise), 10) + "_" + strconv.Itoa(int(id)),
+		typ:  typeHex,
+	}
+}
+
+// decodeValue converts the raw value of an information element.  Unsigned
+// integers use reduced-size encoding, so any length up to 8 bytes is
+// accepted.
+func decodeValue(typ valueType, b []byte) (interface{}, error) {
+	switch typ {
+	case typeUint, typeProtocol, typeDirection:
+		if len(b) == 0 || len(b) > 8 {
+			return nil, fmt.Errorf("invalid length %d for unsigned integer", len(b))
+		}
+		var v uint64
+		for _, c := range b {
+			v = v<<8 | uint64(c)
+		}
+		switch typ {
+		case typeProtocol:
+			if name, ok := protocolNames[v]; ok {
+				return name, nil
+			}
+			return strconv.FormatUint(v, 10), nil
+		case typeDirection:
+			if v == 0 {
+				return "ingress", nil
+			}
+			return "egress", nil
+		}
+		return v, nil
+	case typeInt:
+		if len(b) == 0 || len(b) > 8 {
+			return nil, fmt.Errorf("invalid length %d for signed integer", len(b))
+		}
+		var v uint64
+		for _, c := range b {
+			v = v<<8 | uint64(c)
+		}
+		// Sign extend the reduced-size value.
+		shift := uint(64 - 8*len(b))
+		return int64(v<<shift) >> shift, nil
+	case typeFloat:
+		switch len(b) {
+		case 4:
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+		case 8:
+			return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+		}
+		return nil, fmt.Errorf("invalid length %d for float", len(b))
+	case typeString:
+		return strings.TrimRight(string(b), "\x00"), nil
+	case typeIP:
+		if len(b) != net.IPv4len && len(b) != net.IPv6len {
+			return nil, fmt.Errorf("invalid length %d for IP address", len(b))
+		}
+		return net.IP(b).String(), nil
+	case typeMAC:
+		if len(b) != 6 {
+			return nil, fmt.Errorf("invalid length %d for MAC address", len(b))
+		}
+		return net.HardwareAddr(b).String(), nil
+	case typeBool:
+		if len(b) != 1 {
+			return nil, fmt.Errorf("invalid length %d for boolean", len(b))
+		}
+		// IPFIX encodes true as 1 and false as 2.
+		return b[0] == 1, nil
+	default:
+		return hex.EncodeToString(b), nil
+	}
+}
+
+func formatTag(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,194 @@
+package netflow
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Address to listen for NetFlow v5, v9 and IPFIX packets.
+  ##   example: service_address = "udp://:2055"
+  ##            service_address = "udp4://:2055"
+  ##            service_address = "udp6://:2055"
+  service_address = "udp://:2055"
+
+  ## Set the size of the operating system's receive buffer.
+  ##   example: read_buffer_size = "64KiB"
+  # read_buffer_size = ""
+
+  ## Duration after which templates that have not been refreshed by the
+  ## exporter are removed.
+  # template_timeout = "30m"
+
+  ## Definitions of vendor specific information elements, or overrides of
+  ## standard ones.  Elements of NetFlow v9 and standard IPFIX elements use
+  ## enterprise 0.  Supported types are "uint", "int", "float", "string",
+  ## "ip", "mac", "hex" and "bool", elements without type are hex encoded.
+  # [[inputs.netflow.field]]
+  #   enterprise = 9
+  #   id = 12235
+  #   name = "application_name"
+  #   type = "string"
+  #   is_tag = true
+`
+
+const (
+	maxPacketSize = 64 * 1024
+
+	defaultTemplateTimeout = 30 * time.Minute
+)
+
+// FieldDefinition describes how a custom information element is turned into
+// a tag or field.
+type FieldDefinition struct {
+	Enterprise uint32 `toml:"enterprise"`
+	ID         uint16 `toml:"id"`
+	Name       string `toml:"name"`
+	Type       string `toml:"type"`
+	IsTag      bool   `toml:"is_tag"`
+}
+
+type NetFlow struct {
+	ServiceAddress  string            `toml:"service_address"`
+	ReadBufferSize  internal.Size     `toml:"read_buffer_size"`
+	TemplateTimeout internal.Duration `toml:"template_timeout"`
+	Fields          []FieldDefinition `toml:"field"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	addr    net.Addr
+	decoder *Decoder
+	closer  io.Closer
+	wg      sync.WaitGroup
+}
+
+// Description answers a description of this input plugin
+func (n *NetFlow) Description() string {
+	return "NetFlow v5, v9 and IPFIX Protocol Listener"
+}
+
+// SampleConfig answers a sample configuration
+func (n *NetFlow) SampleConfig() string {
+	return sampleConfig
+}
+
+func (n *NetFlow) Init() error {
+	decoder, err := NewDecoder(n.Fields)
+	if err != nil {
+		return err
+	}
+	decoder.Log = n.Log
+	if n.TemplateTimeout.Duration > 0 {
+		decoder.TemplateTimeout = n.TemplateTimeout.Duration
+	}
+	n.decoder = decoder
+	return nil
+}
+
+// Start starts this NetFlow listener listening on the configured network for
+// NetFlow and IPFIX packets
+func (n *NetFlow) Start(acc telegraf.Accumulator) error {
+	u, err := url.Parse(n.ServiceAddress)
+	if err != nil {
+		return err
+	}
+
+	conn, err := listenUDP(u.Scheme, u.Host)
+	if err != nil {
+		return err
+	}
+	n.closer = conn
+	n.addr = conn.LocalAddr()
+
+	if n.ReadBufferSize.Size > 0 {
+		conn.SetReadBuffer(int(n.ReadBufferSize.Size))
+	}
+
+	n.Log.Infof("Listening on %s://%s", n.addr.Network(), n.addr.String())
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		n.read(acc, conn)
+	}()
+
+	return nil
+}
+
+// Gather removes expired templates, the flows are received asynchronously
+func (n *NetFlow) Gather(_ telegraf.Accumulator) error {
+	n.decoder.ExpireTemplates()
+	return nil
+}
+
+func (n *NetFlow) Stop() {
+	if n.closer != nil {
+		n.closer.Close()
+	}
+	n.wg.Wait()
+}
+
+func (n *NetFlow) Address() net.Addr {
+	return n.addr
+}
+
+func (n *NetFlow) read(acc telegraf.Accumulator, conn net.PacketConn) {
+	buf := make([]byte, maxPacketSize)
+	for {
+		count, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if !strings.HasSuffix(err.Error(), ": use of closed network connection") {
+				acc.AddError(err)
+			}
+			break
+		}
+		n.process(acc, addr, buf[:count])
+	}
+}
+
+func (n *NetFlow) process(acc telegraf.Accumulator, addr net.Addr, buf []byte) {
+	exporter := addr.String()
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		exporter = udpAddr.IP.String()
+	}
+
+	metrics, err := n.decoder.Decode(exporter, buf)
+	if err != nil {
+		acc.AddError(fmt.Errorf("unable to parse incoming packet from %s: %s", exporter, err))
+		return
+	}
+	for _, m := range metrics {
+		acc.AddMetric(m)
+	}
+}
+
+func listenUDP(network string, address string) (*net.UDPConn, error) {
+	switch network {
+	case "udp", "udp4", "udp6":
+		addr, err := net.ResolveUDPAddr(network, address)
+		if err != nil {
+			return nil, err
+		}
+		return net.ListenUDP(network, addr)
+	default:
+		return nil, fmt.Errorf("unsupported network type: %s", network)
+	}
+}
+
+// init registers this NetFlow input plug in with the Telegraf framework
+func init() {
+	inputs.Add("netflow", func() telegraf.Input {
+		return &NetFlow{
+			ServiceAddress: "udp://:2055",
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package netflow
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetFlow(t *testing.T) {
+	netflow := &NetFlow{
+		ServiceAddress: "udp://127.0.0.1:0",
+		Log:            testutil.Logger{},
+	}
+	err := netflow.Init()
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	err = netflow.Start(&acc)
+	require.NoError(t, err)
+	defer netflow.Stop()
+
+	client, err := net.Dial(netflow.Address().Network(), netflow.Address().String())
+	require.NoError(t, err)
+
+	template := set(0, packet{}.
+		u16(256).u16(3).
+		u16(8).u16(4). // src_ip
+		u16(7).u16(2). // src_port
+		u16(1).u16(4), // bytes
+	)
+	data := set(256, packet{}.raw(10, 0, 0, 1).u16(53).u32(512))
+	client.Write(v9Packet(template, data))
+
+	acc.Wait(1)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"netflow",
+			map[string]string{
+				"source":   "127.0.0.1",
+				"version":  "NetFlowV9",
+				"src_ip":   "10.0.0.1",
+				"src_port": "53",
+			},
+			map[string]interface{}{
+				"bytes": uint64(512),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestInvalidPacket(t *testing.T) {
+	netflow := &NetFlow{
+		ServiceAddress: "udp://127.0.0.1:0",
+		Log:            testutil.Logger{},
+	}
+	err := netflow.Init()
+	require.NoError(t, err)
+
+	var acc testutil.Accumulator
+	err = netflow.Start(&acc)
+	require.NoError(t, err)
+	defer netflow.Stop()
+
+	client, err := net.Dial(netflow.Address().Network(), netflow.Address().String())
+	require.NoError(t, err)
+	client.Write([]byte{0, 7, 0, 0})
+
+	acc.WaitError(1)
+	require.Len(t, acc.GetTelegrafMetrics(), 0)
+}
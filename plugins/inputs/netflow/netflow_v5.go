@@ -0,0 +1,81 @@
+package netflow
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+const (
+	v5HeaderLength = 24
+	v5RecordLength = 48
+)
+
+// decodeV5 decodes a NetFlow v5 packet, whose records have a fixed format.
+// The records are mapped to the same tags and fields as the equivalent
+// information elements of NetFlow v9 and IPFIX.
+func (d *Decoder) decodeV5(exporter string, buf []byte) ([]telegraf.Metric, error) {
+	if len(buf) < v5HeaderLength {
+		return nil, fmt.Errorf("packet too short for NetFlow v5 header")
+	}
+	count := int(binary.BigEndian.Uint16(buf[2:]))
+	if len(buf) < v5HeaderLength+count*v5RecordLength {
+		return nil, fmt.Errorf("packet too short for %d NetFlow v5 records", count)
+	}
+
+	tags := map[string]string{
+		"source":      exporter,
+		"version":     "NetFlowV5",
+		"engine_type": strconv.Itoa(int(buf[20])),
+		"engine_id":   strconv.Itoa(int(buf[21])),
+	}
+	// The two most significant bits hold the sampling mode.
+	samplingInterval := uint64(binary.BigEndian.Uint16(buf[22:]) & 0x3fff)
+
+	now := d.now()
+	metrics := make([]telegraf.Metric, 0, count)
+	for i := 0; i < count; i++ {
+		r := buf[v5HeaderLength+i*v5RecordLength:]
+
+		rtags := make(map[string]string, len(tags)+11)
+		for k, v := range tags {
+			rtags[k] = v
+		}
+		rtags["src_ip"] = net.IP(r[0:4]).String()
+		rtags["dst_ip"] = net.IP(r[4:8]).String()
+		rtags["next_hop"] = net.IP(r[8:12]).String()
+		rtags["input_ifindex"] = strconv.Itoa(int(binary.BigEndian.Uint16(r[12:])))
+		rtags["output_ifindex"] = strconv.Itoa(int(binary.BigEndian.Uint16(r[14:])))
+		rtags["src_port"] = strconv.Itoa(int(binary.BigEndian.Uint16(r[32:])))
+		rtags["dst_port"] = strconv.Itoa(int(binary.BigEndian.Uint16(r[34:])))
+		protocol, _ := decodeValue(typeProtocol, r[38:39])
+		rtags["protocol"] = protocol.(string)
+		rtags["src_as"] = strconv.Itoa(int(binary.BigEndian.Uint16(r[40:])))
+		rtags["dst_as"] = strconv.Itoa(int(binary.BigEndian.Uint16(r[42:])))
+		rtags["src_mask_len"] = strconv.Itoa(int(r[44]))
+		rtags["dst_mask_len"] = strconv.Itoa(int(r[45]))
+
+		fields := map[string]interface{}{
+			"packets":                uint64(binary.BigEndian.Uint32(r[16:])),
+			"bytes":                  uint64(binary.BigEndian.Uint32(r[20:])),
+			"flow_start_sys_up_time": uint64(binary.BigEndian.Uint32(r[24:])),
+			"flow_end_sys_up_time":   uint64(binary.BigEndian.Uint32(r[28:])),
+			"tcp_flags":              uint64(r[37]),
+			"ip_tos":                 uint64(r[39]),
+		}
+		if samplingInterval > 0 {
+			fields["sampling_interval"] = samplingInterval
+		}
+
+		m, err := metric.New(metricName, rtags, fields, now)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
@@ -0,0 +1,218 @@
+package opentelemetry
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+// convert returns the metrics of the export request.  The attributes of the
+// resource and of the data points become tags, points without a timestamp
+// are given the time now.
+func convert(request *colmetricpb.ExportMetricsServiceRequest, version int, now time.Time) []telegraf.Metric {
+	var metrics []telegraf.Metric
+	for _, rm := range request.ResourceMetrics {
+		resource := rm.GetResource().GetAttributes()
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				switch data := m.Data.(type) {
+				case *metricpb.Metric_Gauge:
+					for _, dp := range data.Gauge.DataPoints {
+						tags, t := pointTags(resource, dp.Attributes), pointTime(dp.TimeUnixNano, now)
+						metrics = append(metrics, convertNumber(version, m.Name, dp, tags, t, telegraf.Gauge)...)
+					}
+				case *metricpb.Metric_Sum:
+					// Non monotonic sums can go up and down and are gauges.
+					vt := telegraf.Gauge
+					if data.Sum.IsMonotonic {
+						vt = telegraf.Counter
+					}
+					for _, dp := range data.Sum.DataPoints {
+						tags, t := pointTags(resource, dp.Attributes), pointTime(dp.TimeUnixNano, now)
+						metrics = append(metrics, convertNumber(version, m.Name, dp, tags, t, vt)...)
+					}
+				case *metricpb.Metric_Histogram:
+					for _, dp := range data.Histogram.DataPoints {
+						tags, t := pointTags(resource, dp.Attributes), pointTime(dp.TimeUnixNano, now)
+						metrics = append(metrics, convertHistogram(version, m.Name, dp, tags, t)...)
+					}
+				case *metricpb.Metric_Summary:
+					for _, dp := range data.Summary.DataPoints {
+						tags, t := pointTags(resource, dp.Attributes), pointTime(dp.TimeUnixNano, now)
+						metrics = append(metrics, convertSummary(version, m.Name, dp, tags, t)...)
+					}
+				}
+			}
+		}
+	}
+	return metrics
+}
+
+// convertNumber returns the metric of a gauge or sum data point.  In the
+// layout of metric_version 1 of the prometheus input the metric is named
+// after the OTLP metric with a "gauge" or "counter" field, in the layout of
+// metric_version 2 it is named "prometheus" with the OTLP metric name as
+// field.
+func convertNumber(version int, name string, dp *metricpb.NumberDataPoint, tags map[string]string, t time.Time, vt telegraf.ValueType) []telegraf.Metric {
+	v, ok := numberValue(dp)
+	if !ok {
+		return nil
+	}
+
+	if version == 2 {
+		return newMetrics(nil, "prometheus", tags, map[string]interface{}{name: v}, t, vt)
+	}
+
+	field := "gauge"
+	if vt == telegraf.Counter {
+		field = "counter"
+	}
+	return newMetrics(nil, name, tags, map[string]interface{}{field: v}, t, vt)
+}
+
+// convertHistogram returns the metrics of a histogram data point, with the
+// buckets as fields in metric_version 1 and as separate metrics tagged with
+// "le" in metric_version 2.
+func convertHistogram(version int, name string, dp *metricpb.HistogramDataPoint, tags map[string]string, t time.Time) []telegraf.Metric {
+	buckets := cumulativeBuckets(dp)
+
+	if version == 2 {
+		metrics := newMetrics(nil, "prometheus", tags, map[string]interface{}{
+			name + "_count": float64(dp.Count),
+			name + "_sum":   dp.GetSum(),
+		}, t, telegraf.Histogram)
+		for _, b := range buckets {
+			metrics = newMetrics(metrics, "prometheus", withTag(tags, "le", fmt.Sprint(b.bound)),
+				map[string]interface{}{name + "_bucket": float64(b.count)}, t, telegraf.Histogram)
+		}
+		return metrics
+	}
+
+	fields := map[string]interface{}{
+		"count": float64(dp.Count),
+		"sum":   dp.GetSum(),
+	}
+	for _, b := range buckets {
+		fields[fmt.Sprint(b.bound)] = float64(b.count)
+	}
+	return newMetrics(nil, name, tags, fields, t, telegraf.Histogram)
+}
+
+// convertSummary returns the metrics of a summary data point, with the
+// quantiles as fields in metric_version 1 and as separate metrics tagged with
+// "quantile" in metric_version 2.
+func convertSummary(version int, name string, dp *metricpb.SummaryDataPoint, tags map[string]string, t time.Time) []telegraf.Metric {
+	if version == 2 {
+		metrics := newMetrics(nil, "prometheus", tags, map[string]interface{}{
+			name + "_count": float64(dp.Count),
+			name + "_sum":   dp.Sum,
+		}, t, telegraf.Summary)
+		for _, q := range dp.QuantileValues {
+			if math.IsNaN(q.Value) {
+				continue
+			}
+			metrics = newMetrics(metrics, "prometheus", withTag(tags, "quantile", fmt.Sprint(q.Quantile)),
+				map[string]interface{}{name: q.Value}, t, telegraf.Summary)
+		}
+		return metrics
+	}
+
+	fields := map[string]interface{}{
+		"count": float64(dp.Count),
+		"sum":   dp.Sum,
+	}
+	for _, q := range dp.QuantileValues {
+		if !math.IsNaN(q.Value) {
+			fields[fmt.Sprint(q.Quantile)] = q.Value
+		}
+	}
+	return newMetrics(nil, name, tags, fields, t, telegraf.Summary)
+}
+
+func newMetrics(metrics []telegraf.Metric, name string, tags map[string]string, fields map[string]interface{}, t time.Time, vt telegraf.ValueType) []telegraf.Metric {
+	m, err := metric.New(name, tags, fields, t, vt)
+	if err != nil {
+		return metrics
+	}
+	return append(metrics, m)
+}
+
+func numberValue(dp *metricpb.NumberDataPoint) (float64, bool) {
+	switch v := dp.Value.(type) {
+	case *metricpb.NumberDataPoint_AsInt:
+		return float64(v.AsInt), true
+	case *metricpb.NumberDataPoint_AsDouble:
+		if math.IsNaN(v.AsDouble) {
+			return 0, false
+		}
+		return v.AsDouble, true
+	default:
+		return 0, false
+	}
+}
+
+type bucket struct {
+	bound float64
+	count uint64
+}
+
+// cumulativeBuckets converts the per bucket counts of OTLP to the cumulative
+// buckets of Prometheus, the overflow bucket has an infinite bound.
+func cumulativeBuckets(dp *metricpb.HistogramDataPoint) []bucket {
+	buckets := make([]bucket, 0, len(dp.BucketCounts))
+	var cumulative uint64
+	for n, count := range dp.BucketCounts {
+		cumulative += count
+		bound := math.Inf(1)
+		if n < len(dp.ExplicitBounds) {
+			bound = dp.ExplicitBounds[n]
+		}
+		buckets = append(buckets, bucket{bound: bound, count: cumulative})
+	}
+	return buckets
+}
+
+func pointTime(ts uint64, now time.Time) time.Time {
+	if ts > 0 {
+		return time.Unix(0, int64(ts))
+	}
+	return now
+}
+
+func pointTags(resource, attributes []*commonpb.KeyValue) map[string]string {
+	tags := make(map[string]string, len(resource)+len(attributes))
+	addTags(tags, resource)
+	addTags(tags, attributes)
+	return tags
+}
+
+func addTags(tags map[string]string, attributes []*commonpb.KeyValue) {
+	for _, kv := range attributes {
+		switch v := kv.GetValue().GetValue().(type) {
+		case *commonpb.AnyValue_StringValue:
+			tags[kv.Key] = v.StringValue
+		case *commonpb.AnyValue_BoolValue:
+			tags[kv.Key] = strconv.FormatBool(v.BoolValue)
+		case *commonpb.AnyValue_IntValue:
+			tags[kv.Key] = strconv.FormatInt(v.IntValue, 10)
+		case *commonpb.AnyValue_DoubleValue:
+			tags[kv.Key] = strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+		}
+	}
+}
+
+func withTag(tags map[string]string, key string, value string) map[string]string {
+	result := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		result[k] = v
+	}
+	result[key] = value
+	return result
+}
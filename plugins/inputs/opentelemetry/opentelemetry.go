@@ -0,0 +1,283 @@
+package opentelemetry
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	tlsint "github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip" // register gzip decompressor
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// defaultMaxBodySize is the default maximum size of a request, in bytes.
+	defaultMaxBodySize = 32 * 1024 * 1024
+
+	contentType = "application/x-protobuf"
+)
+
+// TimeFunc provides a timestamp for the metrics
+type TimeFunc func() time.Time
+
+type OpenTelemetry struct {
+	GRPCServiceAddress string            `toml:"grpc_service_address"`
+	HTTPServiceAddress string            `toml:"http_service_address"`
+	Path               string            `toml:"path"`
+	MetricVersion      int               `toml:"metric_version"`
+	ReadTimeout        internal.Duration `toml:"read_timeout"`
+	WriteTimeout       internal.Duration `toml:"write_timeout"`
+	MaxBodySize        internal.Size     `toml:"max_body_size"`
+	tlsint.ServerConfig
+
+	TimeFunc
+	Log telegraf.Logger `toml:"-"`
+
+	acc          telegraf.Accumulator
+	grpcServer   *grpc.Server
+	grpcListener net.Listener
+	httpListener net.Listener
+	wg           sync.WaitGroup
+}
+
+const sampleConfig = `
+  ## Address and port to receive OTLP/gRPC requests on, set to empty to
+  ## disable the gRPC receiver.
+  # grpc_service_address = ":4317"
+
+  ## Address and port to receive OTLP/HTTP requests on, set to empty to
+  ## disable the HTTP receiver.
+  # http_service_address = ":4318"
+
+  ## Path of the OTLP/HTTP metrics service.
+  # path = "/v1/metrics"
+
+  ## Layout of the metrics, matching the metric_version option of the
+  ## prometheus input:
+  ##   1: a metric per OTLP metric with fields named after the kind of value
+  ##   2: metrics named "prometheus" with the OTLP metric name as field
+  # metric_version = 1
+
+  ## Maximum duration before timing out read of the HTTP request.
+  # read_timeout = "10s"
+  ## Maximum duration before timing out write of the HTTP response.
+  # write_timeout = "10s"
+
+  ## Maximum allowed size of a request.
+  # max_body_size = "32MiB"
+
+  ## Set one or more allowed client CA certificate file names to
+  ## enable mutually authenticated TLS connections
+  # tls_allowed_cacerts = ["/etc/telegraf/clientca.pem"]
+
+  ## Add service certificate and key
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+`
+
+func (o *OpenTelemetry) SampleConfig() string {
+	return sampleConfig
+}
+
+func (o *OpenTelemetry) Description() string {
+	return "Receive metrics sent using the OpenTelemetry protocol (OTLP)"
+}
+
+func (o *OpenTelemetry) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+// Start starts the gRPC and HTTP receivers.
+func (o *OpenTelemetry) Start(acc telegraf.Accumulator) error {
+	if o.MaxBodySize.Size == 0 {
+		o.MaxBodySize.Size = defaultMaxBodySize
+	}
+	if o.ReadTimeout.Duration < time.Second {
+		o.ReadTimeout.Duration = time.Second * 10
+	}
+	if o.WriteTimeout.Duration < time.Second {
+		o.WriteTimeout.Duration = time.Second * 10
+	}
+
+	o.acc = acc
+
+	tlsConf, err := o.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	if o.GRPCServiceAddress != "" {
+		if err := o.startGRPC(tlsConf); err != nil {
+			return err
+		}
+	}
+
+	if o.HTTPServiceAddress != "" {
+		if err := o.startHTTP(tlsConf); err != nil {
+			o.Stop()
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *OpenTelemetry) startGRPC(tlsConf *tls.Config) error {
+	listener, err := net.Listen("tcp", o.GRPCServiceAddress)
+	if err != nil {
+		return err
+	}
+	o.grpcListener = listener
+
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(int(o.MaxBodySize.Size)),
+	}
+	if tlsConf != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConf)))
+	}
+	o.grpcServer = grpc.NewServer(opts...)
+	colmetricpb.RegisterMetricsServiceServer(o.grpcServer, &metricsService{receiver: o})
+
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+		o.grpcServer.Serve(listener)
+	}()
+
+	o.Log.Infof("Listening for OTLP/gRPC on %s", listener.Addr().String())
+	return nil
+}
+
+func (o *OpenTelemetry) startHTTP(tlsConf *tls.Config) error {
+	server := &http.Server{
+		Handler:      o,
+		ReadTimeout:  o.ReadTimeout.Duration,
+		WriteTimeout: o.WriteTimeout.Duration,
+		TLSConfig:    tlsConf,
+	}
+
+	var listener net.Listener
+	var err error
+	if tlsConf != nil {
+		listener, err = tls.Listen("tcp", o.HTTPServiceAddress, tlsConf)
+	} else {
+		listener, err = net.Listen("tcp", o.HTTPServiceAddress)
+	}
+	if err != nil {
+		return err
+	}
+	o.httpListener = listener
+
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+		server.Serve(listener)
+	}()
+
+	o.Log.Infof("Listening for OTLP/HTTP on %s", listener.Addr().String())
+	return nil
+}
+
+// Stop stops the receivers.
+func (o *OpenTelemetry) Stop() {
+	if o.grpcServer != nil {
+		o.grpcServer.Stop()
+	}
+	if o.httpListener != nil {
+		o.httpListener.Close()
+	}
+	o.wg.Wait()
+}
+
+// metricsService handles the requests of the gRPC receiver.
+type metricsService struct {
+	colmetricpb.UnimplementedMetricsServiceServer
+
+	receiver *OpenTelemetry
+}
+
+func (s *metricsService) Export(ctx context.Context, request *colmetricpb.ExportMetricsServiceRequest) (*colmetricpb.ExportMetricsServiceResponse, error) {
+	s.receiver.addMetrics(request)
+	return &colmetricpb.ExportMetricsServiceResponse{}, nil
+}
+
+// ServeHTTP handles the requests of the HTTP receiver.
+func (o *OpenTelemetry) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != o.Path {
+		http.NotFound(res, req)
+		return
+	}
+	if req.Method != http.MethodPost {
+		http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if req.Header.Get("Content-Type") != contentType {
+		http.Error(res, "unsupported content type", http.StatusUnsupportedMediaType)
+		return
+	}
+	if req.ContentLength > o.MaxBodySize.Size {
+		http.Error(res, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var body io.Reader = req.Body
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		r, err := gzip.NewReader(req.Body)
+		if err != nil {
+			o.Log.Debugf("Error decompressing request: %v", err)
+			http.Error(res, "invalid gzip body", http.StatusBadRequest)
+			return
+		}
+		defer r.Close()
+		body = r
+	}
+
+	buf, err := ioutil.ReadAll(http.MaxBytesReader(res, ioutil.NopCloser(body), o.MaxBodySize.Size))
+	if err != nil {
+		http.Error(res, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var request colmetricpb.ExportMetricsServiceRequest
+	if err := proto.Unmarshal(buf, &request); err != nil {
+		o.Log.Debugf("Error decoding request: %v", err)
+		http.Error(res, "invalid request", http.StatusBadRequest)
+		return
+	}
+	o.addMetrics(&request)
+
+	response, _ := proto.Marshal(&colmetricpb.ExportMetricsServiceResponse{})
+	res.Header().Set("Content-Type", contentType)
+	res.WriteHeader(http.StatusOK)
+	res.Write(response)
+}
+
+func (o *OpenTelemetry) addMetrics(request *colmetricpb.ExportMetricsServiceRequest) {
+	for _, m := range convert(request, o.MetricVersion, o.TimeFunc()) {
+		o.acc.AddMetric(m)
+	}
+}
+
+func init() {
+	inputs.Add("opentelemetry", func() telegraf.Input {
+		return &OpenTelemetry{
+			GRPCServiceAddress: ":4317",
+			HTTPServiceAddress: ":4318",
+			Path:               "/v1/metrics",
+			MetricVersion:      1,
+			TimeFunc:           time.Now,
+		}
+	})
+}
@@ -0,0 +1,264 @@
+package opentelemetry
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+func testRequest() *colmetricpb.ExportMetricsServiceRequest {
+	sum := 4.5
+	return &colmetricpb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricpb.ResourceMetrics{
+			{
+				Resource: &resourcepb.Resource{
+					Attributes: []*commonpb.KeyValue{stringAttribute("service.name", "app")},
+				},
+				ScopeMetrics: []*metricpb.ScopeMetrics{
+					{
+						Metrics: []*metricpb.Metric{
+							{
+								Name: "queue_size",
+								Data: &metricpb.Metric_Gauge{Gauge: &metricpb.Gauge{
+									DataPoints: []*metricpb.NumberDataPoint{
+										{
+											Attributes:   []*commonpb.KeyValue{stringAttribute("queue", "a")},
+											TimeUnixNano: 1000,
+											Value:        &metricpb.NumberDataPoint_AsInt{AsInt: 3},
+										},
+									},
+								}},
+							},
+							{
+								Name: "requests",
+								Data: &metricpb.Metric_Sum{Sum: &metricpb.Sum{
+									IsMonotonic:            true,
+									AggregationTemporality: metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+									DataPoints: []*metricpb.NumberDataPoint{
+										{
+											TimeUnixNano: 1000,
+											Value:        &metricpb.NumberDataPoint_AsDouble{AsDouble: 42},
+										},
+									},
+								}},
+							},
+							{
+								Name: "latency",
+								Data: &metricpb.Metric_Histogram{Histogram: &metricpb.Histogram{
+									AggregationTemporality: metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+									DataPoints: []*metricpb.HistogramDataPoint{
+										{
+											TimeUnixNano:   1000,
+											Count:          6,
+											Sum:            &sum,
+											BucketCounts:   []uint64{1, 2, 3},
+											ExplicitBounds: []float64{0.5, 1},
+										},
+									},
+								}},
+							},
+							{
+								Name: "duration",
+								Data: &metricpb.Metric_Summary{Summary: &metricpb.Summary{
+									DataPoints: []*metricpb.SummaryDataPoint{
+										{
+											Count: 4,
+											Sum:   10,
+											QuantileValues: []*metricpb.SummaryDataPoint_ValueAtQuantile{
+												{Quantile: 0.5, Value: 1},
+											},
+										},
+									},
+								}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+func TestConvertV1(t *testing.T) {
+	now := time.Unix(10, 0)
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"queue_size",
+			map[string]string{"service.name": "app", "queue": "a"},
+			map[string]interface{}{"gauge": 3.0},
+			time.Unix(0, 1000),
+			telegraf.Gauge,
+		),
+		testutil.MustMetric(
+			"requests",
+			map[string]string{"service.name": "app"},
+			map[string]interface{}{"counter": 42.0},
+			time.Unix(0, 1000),
+			telegraf.Counter,
+		),
+		testutil.MustMetric(
+			"latency",
+			map[string]string{"service.name": "app"},
+			map[string]interface{}{"0.5": 1.0, "1": 3.0, "+Inf": 6.0, "count": 6.0, "sum": 4.5},
+			time.Unix(0, 1000),
+			telegraf.Histogram,
+		),
+		testutil.MustMetric(
+			"duration",
+			map[string]string{"service.name": "app"},
+			map[string]interface{}{"0.5": 1.0, "count": 4.0, "sum": 10.0},
+			now,
+			telegraf.Summary,
+		),
+	}
+
+	actual := convert(testRequest(), 1, now)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestConvertV2(t *testing.T) {
+	now := time.Unix(10, 0)
+	tags := map[string]string{"service.name": "app"}
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"service.name": "app", "queue": "a"},
+			map[string]interface{}{"queue_size": 3.0},
+			time.Unix(0, 1000),
+			telegraf.Gauge,
+		),
+		testutil.MustMetric(
+			"prometheus",
+			tags,
+			map[string]interface{}{"requests": 42.0},
+			time.Unix(0, 1000),
+			telegraf.Counter,
+		),
+		testutil.MustMetric(
+			"prometheus",
+			tags,
+			map[string]interface{}{"latency_count": 6.0, "latency_sum": 4.5},
+			time.Unix(0, 1000),
+			telegraf.Histogram,
+		),
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"service.name": "app", "le": "0.5"},
+			map[string]interface{}{"latency_bucket": 1.0},
+			time.Unix(0, 1000),
+			telegraf.Histogram,
+		),
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"service.name": "app", "le": "1"},
+			map[string]interface{}{"latency_bucket": 3.0},
+			time.Unix(0, 1000),
+			telegraf.Histogram,
+		),
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"service.name": "app", "le": "+Inf"},
+			map[string]interface{}{"latency_bucket": 6.0},
+			time.Unix(0, 1000),
+			telegraf.Histogram,
+		),
+		testutil.MustMetric(
+			"prometheus",
+			tags,
+			map[string]interface{}{"duration_count": 4.0, "duration_sum": 10.0},
+			now,
+			telegraf.Summary,
+		),
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"service.name": "app", "quantile": "0.5"},
+			map[string]interface{}{"duration": 1.0},
+			now,
+			telegraf.Summary,
+		),
+	}
+
+	actual := convert(testRequest(), 2, now)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func newReceiver() *OpenTelemetry {
+	return &OpenTelemetry{
+		GRPCServiceAddress: "127.0.0.1:0",
+		HTTPServiceAddress: "127.0.0.1:0",
+		Path:               "/v1/metrics",
+		MetricVersion:      1,
+		TimeFunc:           time.Now,
+		Log:                testutil.Logger{},
+	}
+}
+
+func TestReceiveHTTP(t *testing.T) {
+	o := newReceiver()
+	o.GRPCServiceAddress = ""
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, o.Start(acc))
+	defer o.Stop()
+
+	body, err := proto.Marshal(testRequest())
+	require.NoError(t, err)
+
+	url := "http://" + o.httpListener.Addr().String() + "/v1/metrics"
+	resp, err := http.Post(url, contentType, bytes.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Post(url, "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+
+	resp, err = http.Post(url, contentType, bytes.NewReader([]byte{0x0a, 0x10}))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	require.Len(t, acc.GetTelegrafMetrics(), 4)
+}
+
+func TestReceiveGRPC(t *testing.T) {
+	o := newReceiver()
+	o.HTTPServiceAddress = ""
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, o.Start(acc))
+	defer o.Stop()
+
+	conn, err := grpc.Dial(o.grpcListener.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = colmetricpb.NewMetricsServiceClient(conn).Export(ctx, testRequest())
+	require.NoError(t, err)
+
+	require.Len(t, acc.GetTelegrafMetrics(), 4)
+}
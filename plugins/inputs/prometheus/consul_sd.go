@@ -0,0 +1,120 @@
+package prometheus
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// consulSD discovers the instances of services registered in the Consul
+// catalog.
+type consulSD struct {
+	client      *api.Client
+	services    []string
+	tags        []string
+	scheme      string
+	metricsPath string
+}
+
+func newConsulSD(p *Prometheus) (*consulSD, error) {
+	config := api.DefaultConfig()
+	if p.ConsulSDAddress != "" {
+		config.Address = p.ConsulSDAddress
+	}
+	if p.ConsulSDScheme != "" {
+		config.Scheme = p.ConsulSDScheme
+	}
+	if p.ConsulSDDatacenter != "" {
+		config.Datacenter = p.ConsulSDDatacenter
+	}
+	if p.ConsulSDToken != "" {
+		config.Token = p.ConsulSDToken
+	}
+
+	client, err := api.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &consulSD{
+		client:      client,
+		services:    p.ConsulSDServices,
+		tags:        p.ConsulSDTags,
+		scheme:      p.DiscoveryScheme,
+		metricsPath: p.DiscoveryMetricsPath,
+	}, nil
+}
+
+func (c *consulSD) discover(ctx context.Context) (map[string]URLAndAddress, error) {
+	q := (&api.QueryOptions{}).WithContext(ctx)
+
+	services := c.services
+	if len(services) == 0 {
+		all, _, err := c.client.Catalog().Services(q)
+		if err != nil {
+			return nil, err
+		}
+		for name := range all {
+			if name != "consul" {
+				services = append(services, name)
+			}
+		}
+		sort.Strings(services)
+	}
+
+	targets := map[string]URLAndAddress{}
+	for _, service := range services {
+		instances, _, err := c.client.Catalog().Service(service, "", q)
+		if err != nil {
+			return nil, err
+		}
+		for _, instance := range instances {
+			if !hasTags(instance.ServiceTags, c.tags) {
+				continue
+			}
+			target := consulTarget(instance, c.scheme, c.metricsPath)
+			targets[target.URL.String()] = target
+		}
+	}
+	return targets, nil
+}
+
+// consulTarget creates the target of a service instance, the service address
+// defaults to the address of the node.
+func consulTarget(instance *api.CatalogService, scheme, metricsPath string) URLAndAddress {
+	host := instance.ServiceAddress
+	if host == "" {
+		host = instance.Address
+	}
+
+	tags := map[string]string{}
+	for k, v := range instance.ServiceMeta {
+		tags[k] = v
+	}
+	tags["consul_service"] = instance.ServiceName
+	tags["consul_node"] = instance.Node
+	if instance.Datacenter != "" {
+		tags["consul_datacenter"] = instance.Datacenter
+	}
+
+	return newTarget(scheme, net.JoinHostPort(host, strconv.Itoa(instance.ServicePort)), metricsPath, tags)
+}
+
+func hasTags(tags []string, required []string) bool {
+	for _, r := range required {
+		found := false
+		for _, tag := range tags {
+			if tag == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package prometheus
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"path"
+	"time"
+)
+
+// discoverFunc returns the current targets of a discovery source keyed by
+// their URL.
+type discoverFunc func(ctx context.Context) (map[string]URLAndAddress, error)
+
+// newTarget creates a target scraped at the address, which includes the port.
+func newTarget(scheme, address, metricsPath string, tags map[string]string) URLAndAddress {
+	if scheme == "" {
+		scheme = "http"
+	}
+	if metricsPath == "" {
+		metricsPath = "/metrics"
+	}
+	u := &url.URL{
+		Scheme: scheme,
+		Host:   address,
+		Path:   path.Join("/", metricsPath),
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	return URLAndAddress{
+		URL:         u,
+		OriginalURL: u,
+		Address:     host,
+		Tags:        tags,
+	}
+}
+
+// setTargets replaces the targets of a discovery source, an empty set removes
+// the source.
+func (p *Prometheus) setTargets(source string, targets map[string]URLAndAddress) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.discovered == nil {
+		p.discovered = map[string]map[string]URLAndAddress{}
+	}
+
+	previous := p.discovered[source]
+	for u := range targets {
+		if _, ok := previous[u]; !ok {
+			p.Log.Debugf("Will scrape metrics from %q discovered by %s", u, source)
+		}
+	}
+	for u := range previous {
+		if _, ok := targets[u]; !ok {
+			p.Log.Debugf("Will stop scraping %q no longer discovered by %s", u, source)
+		}
+	}
+
+	if len(targets) == 0 {
+		delete(p.discovered, source)
+		return
+	}
+	p.discovered[source] = targets
+}
+
+// runDiscovery refreshes the targets of the source immediately and then on
+// every interval until the context is done.  The previous targets are kept
+// when a refresh fails.
+func (p *Prometheus) runDiscovery(ctx context.Context, source string, interval time.Duration, discover discoverFunc) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			targets, err := discover(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				p.Log.Errorf("Unable to discover targets from %s: %s", source, err.Error())
+			} else {
+				p.setTargets(source, targets)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
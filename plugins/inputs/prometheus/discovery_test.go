@@ -0,0 +1,293 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func targetURLs(targets map[string]URLAndAddress) []string {
+	var urls []string
+	for u := range targets {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+func TestSetTargets(t *testing.T) {
+	p := &Prometheus{Log: testutil.Logger{}}
+
+	p.setTargets("dns_sd", map[string]URLAndAddress{
+		"http://10.0.0.1:9100/metrics": newTarget("", "10.0.0.1:9100", "", nil),
+	})
+	p.setTargets("file_sd", map[string]URLAndAddress{
+		"http://10.0.0.2:9100/metrics": newTarget("", "10.0.0.2:9100", "", nil),
+	})
+
+	all, err := p.GetAllURLs()
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://10.0.0.1:9100/metrics", "http://10.0.0.2:9100/metrics"}, targetURLs(all))
+
+	p.setTargets("dns_sd", nil)
+	all, err = p.GetAllURLs()
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://10.0.0.2:9100/metrics"}, targetURLs(all))
+}
+
+func TestNewTarget(t *testing.T) {
+	target := newTarget("https", "[2001:db8::1]:9100", "federate", map[string]string{"job": "node"})
+	require.Equal(t, "https://[2001:db8::1]:9100/federate", target.URL.String())
+	require.Equal(t, "2001:db8::1", target.Address)
+	require.Equal(t, map[string]string{"job": "node"}, target.Tags)
+}
+
+func TestFileSD(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf-prometheus")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	jsonFile := filepath.Join(dir, "node.json")
+	require.NoError(t, ioutil.WriteFile(jsonFile, []byte(`[
+		{
+			"targets": ["10.0.0.1:9100", "10.0.0.2:9100"],
+			"labels": {"job": "node", "__meta_ignored": "x"}
+		}
+	]`), 0644))
+	yamlFile := filepath.Join(dir, "app.yml")
+	require.NoError(t, ioutil.WriteFile(yamlFile, []byte(`
+- targets:
+  - app:8443
+  labels:
+    job: app
+    __scheme__: https
+    __metrics_path__: /internal/metrics
+`), 0644))
+
+	f, err := newFileSD([]string{filepath.Join(dir, "*.json"), filepath.Join(dir, "*.yml")}, "", "", testutil.Logger{})
+	require.NoError(t, err)
+
+	targets, err := f.discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"http://10.0.0.1:9100/metrics",
+		"http://10.0.0.2:9100/metrics",
+		"https://app:8443/internal/metrics",
+	}, targetURLs(targets))
+	require.Equal(t, map[string]string{"job": "node"}, targets["http://10.0.0.1:9100/metrics"].Tags)
+	require.Equal(t, "app", targets["https://app:8443/internal/metrics"].Address)
+
+	// Invalid content keeps the targets of the previous version.
+	require.NoError(t, ioutil.WriteFile(jsonFile, []byte(`[{"targets": [`), 0644))
+	require.NoError(t, os.Chtimes(jsonFile, time.Now(), time.Now().Add(time.Minute)))
+	targets, err = f.discover(context.Background())
+	require.NoError(t, err)
+	require.Len(t, targets, 3)
+
+	// Changed files are reloaded.
+	require.NoError(t, ioutil.WriteFile(jsonFile, []byte(`[{"targets": ["10.0.0.3:9100"]}]`), 0644))
+	require.NoError(t, os.Chtimes(jsonFile, time.Now(), time.Now().Add(2*time.Minute)))
+	targets, err = f.discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"http://10.0.0.3:9100/metrics",
+		"https://app:8443/internal/metrics",
+	}, targetURLs(targets))
+
+	// Removed files remove their targets.
+	require.NoError(t, os.Remove(yamlFile))
+	targets, err = f.discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://10.0.0.3:9100/metrics"}, targetURLs(targets))
+}
+
+type mockResolver struct {
+	srv map[string][]*net.SRV
+	ips map[string][]net.IPAddr
+}
+
+func (r *mockResolver) LookupSRV(_ context.Context, _, _, name string) (string, []*net.SRV, error) {
+	records, ok := r.srv[name]
+	if !ok {
+		return "", nil, fmt.Errorf("no such host")
+	}
+	return name, records, nil
+}
+
+func (r *mockResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := r.ips[host]
+	if !ok {
+		return nil, fmt.Errorf("no such host")
+	}
+	return ips, nil
+}
+
+func TestDNSSD(t *testing.T) {
+	r := &mockResolver{
+		srv: map[string][]*net.SRV{
+			"_prometheus._tcp.example.com": {
+				{Target: "node1.example.com.", Port: 9100},
+				{Target: "node2.example.com.", Port: 9101},
+			},
+		},
+		ips: map[string][]net.IPAddr{
+			"nodes.example.com": {
+				{IP: net.ParseIP("10.0.0.1")},
+				{IP: net.ParseIP("2001:db8::1")},
+			},
+		},
+	}
+
+	d, err := newDNSSD([]string{"_prometheus._tcp.example.com"}, "srv", 0, "", "")
+	require.NoError(t, err)
+	d.resolver = r
+	targets, err := d.discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"http://node1.example.com:9100/metrics",
+		"http://node2.example.com:9101/metrics",
+	}, targetURLs(targets))
+	require.Equal(t, map[string]string{"dns_name": "_prometheus._tcp.example.com"},
+		targets["http://node1.example.com:9100/metrics"].Tags)
+
+	d, err = newDNSSD([]string{"nodes.example.com"}, "AAAA", 9100, "", "")
+	require.NoError(t, err)
+	d.resolver = r
+	targets, err = d.discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://[2001:db8::1]:9100/metrics"}, targetURLs(targets))
+
+	d, err = newDNSSD([]string{"missing.example.com"}, "A", 9100, "", "")
+	require.NoError(t, err)
+	d.resolver = r
+	_, err = d.discover(context.Background())
+	require.Error(t, err)
+
+	_, err = newDNSSD([]string{"nodes.example.com"}, "A", 0, "", "")
+	require.Error(t, err)
+	_, err = newDNSSD([]string{"nodes.example.com"}, "MX", 0, "", "")
+	require.Error(t, err)
+}
+
+func TestConsulSD(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/catalog/services":
+			fmt.Fprint(w, `{"consul": [], "web": ["metrics"]}`)
+		case "/v1/catalog/service/web":
+			fmt.Fprint(w, `[
+				{"Node": "node1", "Address": "10.0.0.1", "Datacenter": "dc1", "ServiceName": "web",
+				 "ServiceAddress": "", "ServicePort": 8080, "ServiceTags": ["metrics"], "ServiceMeta": {"version": "1.2"}},
+				{"Node": "node2", "Address": "10.0.0.2", "Datacenter": "dc1", "ServiceName": "web",
+				 "ServiceAddress": "10.1.0.2", "ServicePort": 8080, "ServiceTags": []}
+			]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	p := &Prometheus{
+		ConsulSDAddress:      ts.Listener.Addr().String(),
+		DiscoveryMetricsPath: "/stats",
+	}
+	c, err := newConsulSD(p)
+	require.NoError(t, err)
+
+	targets, err := c.discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://10.0.0.1:8080/stats", "http://10.1.0.2:8080/stats"}, targetURLs(targets))
+	require.Equal(t, map[string]string{
+		"consul_service":    "web",
+		"consul_node":       "node1",
+		"consul_datacenter": "dc1",
+		"version":           "1.2",
+	}, targets["http://10.0.0.1:8080/stats"].Tags)
+
+	c.tags = []string{"metrics"}
+	targets, err = c.discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://10.0.0.1:8080/stats"}, targetURLs(targets))
+}
+
+func TestEndpointsTargets(t *testing.T) {
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:        "web",
+		Namespace:   "default",
+		Labels:      map[string]string{"app": "web"},
+		Annotations: map[string]string{"prometheus.io/scrape": "true"},
+	}}
+	ep := &v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Subsets: []v1.EndpointSubset{{
+			Addresses: []v1.EndpointAddress{
+				{IP: "10.0.0.1", TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "web-1"}},
+			},
+			NotReadyAddresses: []v1.EndpointAddress{{IP: "10.0.0.2"}},
+			Ports: []v1.EndpointPort{
+				{Name: "http", Port: 8080, Protocol: v1.ProtocolTCP},
+				{Name: "dns", Port: 53, Protocol: v1.ProtocolUDP},
+			},
+		}},
+	}
+
+	targets := endpointsTargets(svc, ep)
+	require.Equal(t, []string{"http://10.0.0.1:8080/metrics"}, targetURLs(targets))
+	require.Equal(t, map[string]string{
+		"app":          "web",
+		"namespace":    "default",
+		"service_name": "web",
+		"pod_name":     "web-1",
+	}, targets["http://10.0.0.1:8080/metrics"].Tags)
+
+	svc.Annotations["prometheus.io/port"] = "9102"
+	svc.Annotations["prometheus.io/path"] = "/stats"
+	targets = endpointsTargets(svc, ep)
+	require.Equal(t, []string{"http://10.0.0.1:9102/stats"}, targetURLs(targets))
+
+	svc.Annotations["prometheus.io/scrape"] = "false"
+	require.Empty(t, endpointsTargets(svc, ep))
+}
+
+func TestDiscoveryStartStop(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf-prometheus")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	p := &Prometheus{
+		Log:                      testutil.Logger{},
+		MetricVersion:            2,
+		FileSDFiles:              []string{filepath.Join(dir, "*.json")},
+		DiscoveryRefreshInterval: internal.Duration{Duration: 10 * time.Millisecond},
+	}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Start(&testutil.Accumulator{}))
+	defer p.Stop()
+
+	file := filepath.Join(dir, "targets.json")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`[{"targets": ["10.0.0.1:9100"]}]`), 0644))
+	require.Eventually(t, func() bool {
+		all, err := p.GetAllURLs()
+		return err == nil && len(all) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.Remove(file))
+	require.Eventually(t, func() bool {
+		all, err := p.GetAllURLs()
+		return err == nil && len(all) == 0
+	}, time.Second, 10*time.Millisecond)
+}
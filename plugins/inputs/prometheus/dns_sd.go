@@ -0,0 +1,93 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// resolver is implemented by net.Resolver.
+type resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// dnsSD discovers targets from DNS SRV records, or from A and AAAA records
+// combined with a fixed port.
+type dnsSD struct {
+	names       []string
+	recordType  string
+	port        int
+	scheme      string
+	metricsPath string
+	resolver    resolver
+}
+
+func newDNSSD(names []string, recordType string, port int, scheme, metricsPath string) (*dnsSD, error) {
+	recordType = strings.ToUpper(recordType)
+	switch recordType {
+	case "", "SRV":
+		recordType = "SRV"
+	case "A", "AAAA":
+		if port <= 0 {
+			return nil, fmt.Errorf("dns_sd_port is required for %s records", recordType)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported dns_sd_type %q", recordType)
+	}
+
+	return &dnsSD{
+		names:       names,
+		recordType:  recordType,
+		port:        port,
+		scheme:      scheme,
+		metricsPath: metricsPath,
+		resolver:    net.DefaultResolver,
+	}, nil
+}
+
+func (d *dnsSD) discover(ctx context.Context) (map[string]URLAndAddress, error) {
+	targets := map[string]URLAndAddress{}
+	for _, name := range d.names {
+		addresses, err := d.lookup(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("looking up %s records of %q: %v", d.recordType, name, err)
+		}
+
+		for _, address := range addresses {
+			target := newTarget(d.scheme, address, d.metricsPath, map[string]string{"dns_name": name})
+			targets[target.URL.String()] = target
+		}
+	}
+	return targets, nil
+}
+
+func (d *dnsSD) lookup(ctx context.Context, name string) ([]string, error) {
+	var addresses []string
+	switch d.recordType {
+	case "SRV":
+		_, records, err := d.resolver.LookupSRV(ctx, "", "", name)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			host := strings.TrimSuffix(record.Target, ".")
+			addresses = append(addresses, net.JoinHostPort(host, strconv.Itoa(int(record.Port))))
+		}
+	default:
+		ips, err := d.resolver.LookupIPAddr(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			isV4 := ip.IP.To4() != nil
+			if isV4 != (d.recordType == "A") {
+				continue
+			}
+			addresses = append(addresses, net.JoinHostPort(ip.IP.String(), strconv.Itoa(d.port)))
+		}
+	}
+	return addresses, nil
+}
@@ -0,0 +1,135 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/globpath"
+)
+
+// fileSDGroup is a target group of a Prometheus file_sd file.
+type fileSDGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+type fileSDEntry struct {
+	modTime time.Time
+	size    int64
+	targets map[string]URLAndAddress
+}
+
+// fileSD discovers targets from file_sd files in JSON or YAML format.  Files
+// are parsed again only when their modification time or size changes.
+type fileSD struct {
+	globs       []*globpath.GlobPath
+	scheme      string
+	metricsPath string
+	files       map[string]*fileSDEntry
+	log         telegraf.Logger
+}
+
+func newFileSD(patterns []string, scheme, metricsPath string, log telegraf.Logger) (*fileSD, error) {
+	f := &fileSD{
+		log:         log,
+		scheme:      scheme,
+		metricsPath: metricsPath,
+		files:       map[string]*fileSDEntry{},
+	}
+	for _, pattern := range patterns {
+		g, err := globpath.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file_sd_files pattern %q: %v", pattern, err)
+		}
+		f.globs = append(f.globs, g)
+	}
+	return f, nil
+}
+
+func (f *fileSD) discover(_ context.Context) (map[string]URLAndAddress, error) {
+	seen := map[string]bool{}
+	targets := map[string]URLAndAddress{}
+	for _, g := range f.globs {
+		for _, path := range g.Match() {
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				continue
+			}
+
+			entry, ok := f.files[path]
+			if !ok || !entry.modTime.Equal(info.ModTime()) || entry.size != info.Size() {
+				parsed, err := f.parseFile(path)
+				if err != nil {
+					// Keep the targets of the last valid version of the
+					// file, it may be in the middle of being written.
+					f.log.Errorf("Unable to parse file_sd file %q: %s", path, err.Error())
+					if ok {
+						parsed = entry.targets
+					}
+				}
+				entry = &fileSDEntry{modTime: info.ModTime(), size: info.Size(), targets: parsed}
+				f.files[path] = entry
+			}
+
+			for u, target := range entry.targets {
+				targets[u] = target
+			}
+		}
+	}
+
+	for path := range f.files {
+		if !seen[path] {
+			delete(f.files, path)
+		}
+	}
+	return targets, nil
+}
+
+func (f *fileSD) parseFile(path string) (map[string]URLAndAddress, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML is a superset of JSON, so both formats are parsed alike.
+	var groups []fileSDGroup
+	if err := yaml.Unmarshal(data, &groups); err != nil {
+		return nil, err
+	}
+
+	targets := map[string]URLAndAddress{}
+	for _, group := range groups {
+		scheme := f.scheme
+		metricsPath := f.metricsPath
+		tags := map[string]string{}
+		for k, v := range group.Labels {
+			switch {
+			case k == "__scheme__":
+				scheme = v
+			case k == "__metrics_path__":
+				metricsPath = v
+			case strings.HasPrefix(k, "__"):
+				// Reserved for internal use by Prometheus.
+			default:
+				tags[k] = v
+			}
+		}
+
+		for _, address := range group.Targets {
+			target := newTarget(scheme, address, metricsPath, tags)
+			targets[target.URL.String()] = target
+		}
+	}
+	return targets, nil
+}
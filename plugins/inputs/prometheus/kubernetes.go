@@ -3,18 +3,21 @@ package prometheus
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net"
 	"net/url"
 	"os/user"
 	"path/filepath"
-	"sync"
+	"strconv"
 	"time"
 
-	"github.com/ericchiang/k8s"
-	corev1 "github.com/ericchiang/k8s/apis/core/v1"
-	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 type payload struct {
@@ -23,41 +26,36 @@ type payload struct {
 }
 
 // loadClient parses a kubeconfig from a file and returns a Kubernetes
-// client. It does not support extensions or client auth providers.
-func loadClient(kubeconfigPath string) (*k8s.Client, error) {
-	data, err := ioutil.ReadFile(kubeconfigPath)
+// client.
+func loadClient(kubeconfigPath string) (*kubernetes.Clientset, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed reading '%s': %v", kubeconfigPath, err)
 	}
+	return kubernetes.NewForConfig(config)
+}
 
-	// Unmarshal YAML into a Kubernetes config object.
-	var config k8s.Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, err
+// kubernetesClient returns the in-cluster client, or a client using the
+// kubeconfig when running outside of a cluster.
+func (p *Prometheus) kubernetesClient() (*kubernetes.Clientset, error) {
+	config, err := rest.InClusterConfig()
+	if err == nil {
+		return kubernetes.NewForConfig(config)
 	}
-	return k8s.NewClient(&config)
-}
 
-func (p *Prometheus) start(ctx context.Context) error {
-	client, err := k8s.NewInClusterClient()
+	u, err := user.Current()
 	if err != nil {
-		u, err := user.Current()
-		if err != nil {
-			return fmt.Errorf("Failed to get current user - %v", err)
-		}
-
-		configLocation := filepath.Join(u.HomeDir, ".kube/config")
-		if p.KubeConfig != "" {
-			configLocation = p.KubeConfig
-		}
-		client, err = loadClient(configLocation)
-		if err != nil {
-			return err
-		}
+		return nil, fmt.Errorf("Failed to get current user - %v", err)
 	}
 
-	p.wg = sync.WaitGroup{}
+	configLocation := filepath.Join(u.HomeDir, ".kube/config")
+	if p.KubeConfig != "" {
+		configLocation = p.KubeConfig
+	}
+	return loadClient(configLocation)
+}
 
+func (p *Prometheus) start(ctx context.Context, client *kubernetes.Clientset) error {
 	p.wg.Add(1)
 	go func() {
 		defer p.wg.Done()
@@ -81,42 +79,45 @@ func (p *Prometheus) start(ctx context.Context) error {
 // (without the scrape annotations). K8s may re-assign the old pod ip to the non-scrape
 // pod, causing errors in the logs. This is only true if the pod going offline is not
 // directed to do so by K8s.
-func (p *Prometheus) watch(ctx context.Context, client *k8s.Client) error {
-
-	selectors := podSelector(p)
-
-	pod := &corev1.Pod{}
-	watcher, err := client.Watch(ctx, p.PodNamespace, &corev1.Pod{}, selectors...)
+func (p *Prometheus) watch(ctx context.Context, client *kubernetes.Clientset) error {
+	watcher, err := client.CoreV1().Pods(p.PodNamespace).Watch(ctx, podSelector(p))
 	if err != nil {
 		return err
 	}
-	defer watcher.Close()
+	defer watcher.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
-		default:
-			pod = &corev1.Pod{}
-			// An error here means we need to reconnect the watcher.
-			eventType, err := watcher.Next(pod)
-			if err != nil {
-				return err
+		case event, ok := <-watcher.ResultChan():
+			// A closed channel or an error means we need to reconnect the
+			// watcher.
+			if !ok {
+				return nil
+			}
+			if event.Type == watch.Error {
+				return apierrors.FromObject(event.Object)
+			}
+
+			pod, ok := event.Object.(*corev1.Pod)
+			if !ok {
+				continue
 			}
 
 			// If the pod is not "ready", there will be no ip associated with it.
-			if pod.GetMetadata().GetAnnotations()["prometheus.io/scrape"] != "true" ||
-				!podReady(pod.Status.GetContainerStatuses()) {
+			if pod.Annotations["prometheus.io/scrape"] != "true" ||
+				!podReady(pod.Status.ContainerStatuses) {
 				continue
 			}
 
-			switch eventType {
-			case k8s.EventAdded:
+			switch event.Type {
+			case watch.Added:
 				registerPod(pod, p)
-			case k8s.EventModified:
+			case watch.Modified:
 				// To avoid multiple actions for each event, unregister on the first event
 				// in the delete sequence, when the containers are still "ready".
-				if pod.Metadata.GetDeletionTimestamp() != nil {
+				if pod.DeletionTimestamp != nil {
 					unregisterPod(pod, p)
 				} else {
 					registerPod(pod, p)
@@ -126,31 +127,23 @@ func (p *Prometheus) watch(ctx context.Context, client *k8s.Client) error {
 	}
 }
 
-func podReady(statuss []*corev1.ContainerStatus) bool {
+func podReady(statuss []corev1.ContainerStatus) bool {
 	if len(statuss) == 0 {
 		return false
 	}
 	for _, cs := range statuss {
-		if !cs.GetReady() {
+		if !cs.Ready {
 			return false
 		}
 	}
 	return true
 }
 
-func podSelector(p *Prometheus) []k8s.Option {
-	options := []k8s.Option{}
-
-	if len(p.KubernetesLabelSelector) > 0 {
-		options = append(options, k8s.QueryParam("labelSelector", p.KubernetesLabelSelector))
+func podSelector(p *Prometheus) metav1.ListOptions {
+	return metav1.ListOptions{
+		LabelSelector: p.KubernetesLabelSelector,
+		FieldSelector: p.KubernetesFieldSelector,
 	}
-
-	if len(p.KubernetesFieldSelector) > 0 {
-		options = append(options, k8s.QueryParam("fieldSelector", p.KubernetesFieldSelector))
-	}
-
-	return options
-
 }
 
 func registerPod(pod *corev1.Pod, p *Prometheus) {
@@ -164,14 +157,14 @@ func registerPod(pod *corev1.Pod, p *Prometheus) {
 
 	log.Printf("D! [inputs.prometheus] will scrape metrics from %q", *targetURL)
 	// add annotation as metrics tags
-	tags := pod.GetMetadata().GetAnnotations()
-	if tags == nil {
-		tags = map[string]string{}
+	tags := map[string]string{}
+	for k, v := range pod.Annotations {
+		tags[k] = v
 	}
-	tags["pod_name"] = pod.GetMetadata().GetName()
-	tags["namespace"] = pod.GetMetadata().GetNamespace()
+	tags["pod_name"] = pod.Name
+	tags["namespace"] = pod.Namespace
 	// add labels as metrics tags
-	for k, v := range pod.GetMetadata().GetLabels() {
+	for k, v := range pod.Labels {
 		tags[k] = v
 	}
 	URL, err := url.Parse(*targetURL)
@@ -191,16 +184,16 @@ func registerPod(pod *corev1.Pod, p *Prometheus) {
 }
 
 func getScrapeURL(pod *corev1.Pod) *string {
-	ip := pod.Status.GetPodIP()
+	ip := pod.Status.PodIP
 	if ip == "" {
 		// return as if scrape was disabled, we will be notified again once the pod
 		// has an IP
 		return nil
 	}
 
-	scheme := pod.GetMetadata().GetAnnotations()["prometheus.io/scheme"]
-	path := pod.GetMetadata().GetAnnotations()["prometheus.io/path"]
-	port := pod.GetMetadata().GetAnnotations()["prometheus.io/port"]
+	scheme := pod.Annotations["prometheus.io/scheme"]
+	path := pod.Annotations["prometheus.io/path"]
+	port := pod.Annotations["prometheus.io/port"]
 
 	if scheme == "" {
 		scheme = "http"
@@ -230,7 +223,7 @@ func unregisterPod(pod *corev1.Pod, p *Prometheus) {
 	}
 
 	log.Printf("D! [inputs.prometheus] registered a delete request for %q in namespace %q",
-		pod.GetMetadata().GetName(), pod.GetMetadata().GetNamespace())
+		pod.Name, pod.Namespace)
 
 	p.lock.Lock()
 	defer p.lock.Unlock()
@@ -239,3 +232,85 @@ func unregisterPod(pod *corev1.Pod, p *Prometheus) {
 		log.Printf("D! [inputs.prometheus] will stop scraping for %q", *url)
 	}
 }
+
+// kubernetesEndpointsSD discovers the ready endpoints of Services annotated
+// with prometheus.io/scrape.
+type kubernetesEndpointsSD struct {
+	client    *kubernetes.Clientset
+	namespace string
+}
+
+func (k *kubernetesEndpointsSD) discover(ctx context.Context) (map[string]URLAndAddress, error) {
+	services, err := k.client.CoreV1().Services(k.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing services: %v", err)
+	}
+	endpoints, err := k.client.CoreV1().Endpoints(k.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing endpoints: %v", err)
+	}
+
+	byService := map[string]*corev1.Endpoints{}
+	for i := range endpoints.Items {
+		ep := &endpoints.Items[i]
+		byService[ep.Namespace+"/"+ep.Name] = ep
+	}
+
+	targets := map[string]URLAndAddress{}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		ep, ok := byService[svc.Namespace+"/"+svc.Name]
+		if !ok {
+			continue
+		}
+		for u, target := range endpointsTargets(svc, ep) {
+			targets[u] = target
+		}
+	}
+	return targets, nil
+}
+
+// endpointsTargets returns a target for each ready address of the Service
+// endpoints.  The prometheus.io/port annotation selects the port, otherwise
+// each TCP port of the endpoints is scraped.
+func endpointsTargets(svc *corev1.Service, ep *corev1.Endpoints) map[string]URLAndAddress {
+	annotations := svc.Annotations
+	if annotations["prometheus.io/scrape"] != "true" {
+		return nil
+	}
+	scheme := annotations["prometheus.io/scheme"]
+	path := annotations["prometheus.io/path"]
+	port := annotations["prometheus.io/port"]
+
+	targets := map[string]URLAndAddress{}
+	for _, subset := range ep.Subsets {
+		var ports []string
+		if port != "" {
+			ports = []string{port}
+		} else {
+			for _, p := range subset.Ports {
+				if p.Protocol == "" || p.Protocol == corev1.ProtocolTCP {
+					ports = append(ports, strconv.Itoa(int(p.Port)))
+				}
+			}
+		}
+
+		for _, address := range subset.Addresses {
+			tags := map[string]string{}
+			for k, v := range svc.Labels {
+				tags[k] = v
+			}
+			tags["namespace"] = svc.Namespace
+			tags["service_name"] = svc.Name
+			if ref := address.TargetRef; ref != nil && ref.Kind == "Pod" {
+				tags["pod_name"] = ref.Name
+			}
+
+			for _, p := range ports {
+				target := newTarget(scheme, net.JoinHostPort(address.IP, p), path, tags)
+				targets[target.URL.String()] = target
+			}
+		}
+	}
+	return targets
+}
@@ -62,6 +62,33 @@ type Prometheus struct {
 	kubernetesPods map[string]URLAndAddress
 	cancel         context.CancelFunc
 	wg             sync.WaitGroup
+
+	// Discover targets from file_sd files
+	FileSDFiles []string `toml:"file_sd_files"`
+
+	// Discover targets from DNS records
+	DNSSDNames []string `toml:"dns_sd_names"`
+	DNSSDType  string   `toml:"dns_sd_type"`
+	DNSSDPort  int      `toml:"dns_sd_port"`
+
+	// Discover targets from the Consul catalog
+	ConsulSDAddress    string   `toml:"consul_sd_address"`
+	ConsulSDScheme     string   `toml:"consul_sd_scheme"`
+	ConsulSDDatacenter string   `toml:"consul_sd_datacenter"`
+	ConsulSDToken      string   `toml:"consul_sd_token"`
+	ConsulSDServices   []string `toml:"consul_sd_services"`
+	ConsulSDTags       []string `toml:"consul_sd_tags"`
+
+	// Should we scrape the endpoints of Kubernetes services with prometheus annotations
+	MonitorEndpoints   bool   `toml:"monitor_kubernetes_endpoints"`
+	EndpointsNamespace string `toml:"monitor_kubernetes_endpoints_namespace"`
+
+	DiscoveryScheme          string            `toml:"discovery_scheme"`
+	DiscoveryMetricsPath     string            `toml:"discovery_metrics_path"`
+	DiscoveryRefreshInterval internal.Duration `toml:"discovery_refresh_interval"`
+
+	sources    map[string]discoverFunc
+	discovered map[string]map[string]URLAndAddress
 }
 
 var sampleConfig = `
@@ -102,6 +129,40 @@ var sampleConfig = `
   # eg. To scrape pods on a specific node
   # kubernetes_field_selector = "spec.nodeName=$HOSTNAME"
 
+  ## Scrape the ready endpoints of Kubernetes services with the prometheus.io
+  ## annotations described above.
+  # monitor_kubernetes_endpoints = false
+  ## Restricts Kubernetes endpoints monitoring to a single namespace
+  # monitor_kubernetes_endpoints_namespace = ""
+
+  ## Discover targets from Prometheus file_sd files in JSON or YAML format,
+  ## files are reloaded when they change.  The __scheme__ and
+  ## __metrics_path__ labels are supported, other labels are added as tags.
+  # file_sd_files = ["/etc/telegraf/targets/*.json"]
+
+  ## Discover targets from DNS SRV records, or from A or AAAA records with the
+  ## given port.  Targets are tagged with the dns_name.
+  # dns_sd_names = ["_prometheus._tcp.example.com"]
+  # dns_sd_type = "SRV"
+  # dns_sd_port = 9100
+
+  ## Discover the instances of services in the Consul catalog; all services
+  ## are used when no services are given.  Only instances having all of the
+  ## consul_sd_tags are scraped.
+  # consul_sd_address = "localhost:8500"
+  # consul_sd_scheme = "http"
+  # consul_sd_datacenter = ""
+  # consul_sd_token = ""
+  # consul_sd_services = ["node_exporter"]
+  # consul_sd_tags = []
+
+  ## Scheme and path of targets discovered from DNS, Consul and file_sd
+  ## files without a __metrics_path__ label.
+  # discovery_scheme = "http"
+  # discovery_metrics_path = "/metrics"
+  ## Interval at which discovered targets are refreshed.
+  # discovery_refresh_interval = "30s"
+
   ## Use bearer token for authorization. ('bearer_token' takes priority)
   # bearer_token = "/path/to/bearer/token"
   ## OR
@@ -136,6 +197,33 @@ func (p *Prometheus) Init() error {
 		p.Log.Warnf("Use of deprecated configuration: 'metric_version = 1'; please update to 'metric_version = 2'")
 	}
 
+	if p.DiscoveryRefreshInterval.Duration <= 0 {
+		p.DiscoveryRefreshInterval.Duration = 30 * time.Second
+	}
+
+	p.sources = map[string]discoverFunc{}
+	if len(p.FileSDFiles) > 0 {
+		f, err := newFileSD(p.FileSDFiles, p.DiscoveryScheme, p.DiscoveryMetricsPath, p.Log)
+		if err != nil {
+			return err
+		}
+		p.sources["file_sd"] = f.discover
+	}
+	if len(p.DNSSDNames) > 0 {
+		d, err := newDNSSD(p.DNSSDNames, p.DNSSDType, p.DNSSDPort, p.DiscoveryScheme, p.DiscoveryMetricsPath)
+		if err != nil {
+			return err
+		}
+		p.sources["dns_sd"] = d.discover
+	}
+	if p.ConsulSDAddress != "" {
+		c, err := newConsulSD(p)
+		if err != nil {
+			return err
+		}
+		p.sources["consul_sd"] = c.discover
+	}
+
 	return nil
 }
 
@@ -184,6 +272,12 @@ func (p *Prometheus) GetAllURLs() (map[string]URLAndAddress, error) {
 	for k, v := range p.kubernetesPods {
 		allURLs[k] = v
 	}
+	// and all targets found by the discovery sources
+	for _, targets := range p.discovered {
+		for k, v := range targets {
+			allURLs[k] = v
+		}
+	}
 
 	for _, service := range p.KubernetesServices {
 		URL, err := url.Parse(service)
@@ -363,18 +457,38 @@ func (p *Prometheus) gatherURL(u URLAndAddress, acc telegraf.Accumulator) error
 	return nil
 }
 
-// Start will start the Kubernetes scraping if enabled in the configuration
+// Start will start the Kubernetes scraping and the discovery of targets if
+// enabled in the configuration
 func (p *Prometheus) Start(a telegraf.Accumulator) error {
-	if p.MonitorPods {
-		var ctx context.Context
-		ctx, p.cancel = context.WithCancel(context.Background())
-		return p.start(ctx)
+	var ctx context.Context
+	ctx, p.cancel = context.WithCancel(context.Background())
+
+	if p.MonitorPods || p.MonitorEndpoints {
+		client, err := p.kubernetesClient()
+		if err != nil {
+			p.cancel()
+			return err
+		}
+		if p.MonitorPods {
+			if err := p.start(ctx, client); err != nil {
+				p.cancel()
+				return err
+			}
+		}
+		if p.MonitorEndpoints {
+			k := &kubernetesEndpointsSD{client: client, namespace: p.EndpointsNamespace}
+			p.runDiscovery(ctx, "kubernetes_endpoints", p.DiscoveryRefreshInterval.Duration, k.discover)
+		}
+	}
+
+	for name, discover := range p.sources {
+		p.runDiscovery(ctx, name, p.DiscoveryRefreshInterval.Duration, discover)
 	}
 	return nil
 }
 
 func (p *Prometheus) Stop() {
-	if p.MonitorPods {
+	if p.cancel != nil {
 		p.cancel()
 	}
 	p.wg.Wait()
@@ -383,9 +497,10 @@ func (p *Prometheus) Stop() {
 func init() {
 	inputs.Add("prometheus", func() telegraf.Input {
 		return &Prometheus{
-			ResponseTimeout: internal.Duration{Duration: time.Second * 3},
-			kubernetesPods:  map[string]URLAndAddress{},
-			URLTag:          "url",
+			ResponseTimeout:          internal.Duration{Duration: time.Second * 3},
+			kubernetesPods:           map[string]URLAndAddress{},
+			URLTag:                   "url",
+			DiscoveryRefreshInterval: internal.Duration{Duration: 30 * time.Second},
 		}
 	})
 }
@@ -0,0 +1,143 @@
+package psi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+// default host proc path
+const defaultHostProc = "/proc"
+
+// env host proc variable name
+const envProc = "HOST_PROC"
+
+// resources are the resources with pressure stall information
+var resources = []string{"cpu", "memory", "io", "irq"}
+
+type PSI struct {
+	HostProc string   `toml:"host_proc"`
+	Cgroups  []string `toml:"cgroups"`
+}
+
+var sampleConfig = `
+  ## Sets 'proc' directory path, the system-wide pressure is read from its
+  ## pressure directory.
+  ## If not specified, then default is /proc
+  # host_proc = "/proc"
+
+  ## cgroup v2 directories to read the pressure of, from their cpu.pressure,
+  ## memory.pressure, io.pressure and irq.pressure files, globs are supported.
+  # cgroups = ["/sys/fs/cgroup/system.slice/*.service"]
+`
+
+func (p *PSI) Description() string {
+	return "Read the pressure stall information (PSI) of the system and of cgroups"
+}
+
+func (p *PSI) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *PSI) Init() error {
+	if p.HostProc == "" {
+		p.HostProc = os.Getenv(envProc)
+	}
+	if p.HostProc == "" {
+		p.HostProc = defaultHostProc
+	}
+	return nil
+}
+
+func (p *PSI) Gather(acc telegraf.Accumulator) error {
+	dir := filepath.Join(p.HostProc, "pressure")
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("pressure stall information is not available: %v", err)
+	}
+
+	for _, resource := range resources {
+		p.gatherFile(acc, filepath.Join(dir, resource), resource, map[string]string{})
+	}
+
+	for _, pattern := range p.Cgroups {
+		dirs, err := filepath.Glob(pattern)
+		if err != nil {
+			acc.AddError(err)
+			continue
+		}
+		for _, dir := range dirs {
+			for _, resource := range resources {
+				file := filepath.Join(dir, resource+".pressure")
+				p.gatherFile(acc, file, resource, map[string]string{"path": dir})
+			}
+		}
+	}
+	return nil
+}
+
+// gatherFile adds a metric for each line of the pressure file; missing files
+// are skipped, as older kernels do not report the pressure of all resources.
+func (p *PSI) gatherFile(acc telegraf.Accumulator, file, resource string, tags map[string]string) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			acc.AddError(err)
+		}
+		return
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		typ, fields, err := parseLine(line)
+		if err != nil {
+			acc.AddError(fmt.Errorf("parsing %q: %v", file, err))
+			return
+		}
+
+		lineTags := map[string]string{"resource": resource, "type": typ}
+		for k, v := range tags {
+			lineTags[k] = v
+		}
+		acc.AddFields("psi", fields, lineTags)
+	}
+}
+
+// parseLine parses a line of a pressure file:
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func parseLine(line string) (string, map[string]interface{}, error) {
+	values := strings.Fields(line)
+	if len(values) < 2 || (values[0] != "some" && values[0] != "full") {
+		return "", nil, fmt.Errorf("invalid line %q", line)
+	}
+
+	fields := make(map[string]interface{})
+	for _, kv := range values[1:] {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return "", nil, fmt.Errorf("invalid value %q", kv)
+		}
+
+		var err error
+		if parts[0] == "total" {
+			fields[parts[0]], err = strconv.ParseInt(parts[1], 10, 64)
+		} else {
+			fields[parts[0]], err = strconv.ParseFloat(parts[1], 64)
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid value %q", kv)
+		}
+	}
+	return values[0], fields, nil
+}
+
+func init() {
+	inputs.Add("psi", func() telegraf.Input {
+		return &PSI{}
+	})
+}
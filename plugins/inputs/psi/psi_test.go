@@ -0,0 +1,85 @@
+package psi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGather(t *testing.T) {
+	p := &PSI{
+		HostProc: "testdata/proc",
+		Cgroups:  []string{"testdata/cgroup/*.service"},
+	}
+	require.NoError(t, p.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("psi",
+			map[string]string{"resource": "cpu", "type": "some"},
+			map[string]interface{}{"avg10": 1.5, "avg60": 0.75, "avg300": 0.25, "total": int64(1234567)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("psi",
+			map[string]string{"resource": "cpu", "type": "full"},
+			map[string]interface{}{"avg10": 0.0, "avg60": 0.0, "avg300": 0.0, "total": int64(0)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("psi",
+			map[string]string{"resource": "memory", "type": "some"},
+			map[string]interface{}{"avg10": 0.1, "avg60": 0.2, "avg300": 0.3, "total": int64(4567)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("psi",
+			map[string]string{"resource": "memory", "type": "full"},
+			map[string]interface{}{"avg10": 0.05, "avg60": 0.1, "avg300": 0.15, "total": int64(1234)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("psi",
+			map[string]string{"resource": "io", "type": "some"},
+			map[string]interface{}{"avg10": 2.0, "avg60": 1.0, "avg300": 0.5, "total": int64(99999)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("psi",
+			map[string]string{"resource": "io", "type": "full"},
+			map[string]interface{}{"avg10": 1.0, "avg60": 0.5, "avg300": 0.25, "total": int64(55555)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("psi",
+			map[string]string{"resource": "memory", "type": "some", "path": "testdata/cgroup/app.service"},
+			map[string]interface{}{"avg10": 0.12, "avg60": 0.05, "avg300": 0.0, "total": int64(12345)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("psi",
+			map[string]string{"resource": "memory", "type": "full", "path": "testdata/cgroup/app.service"},
+			map[string]interface{}{"avg10": 0.0, "avg60": 0.0, "avg300": 0.0, "total": int64(678)},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+
+	// The invalid io.pressure of the cgroup is reported as an error.
+	require.Len(t, acc.Errors, 1)
+}
+
+func TestGatherNotSupported(t *testing.T) {
+	p := &PSI{HostProc: "testdata/missing"}
+	require.NoError(t, p.Init())
+
+	var acc testutil.Accumulator
+	require.Error(t, p.Gather(&acc))
+}
+
+func TestParseLine(t *testing.T) {
+	typ, fields, err := parseLine("some avg10=0.12 avg60=0.05 avg300=0.00 total=12345")
+	require.NoError(t, err)
+	require.Equal(t, "some", typ)
+	require.Equal(t, map[string]interface{}{
+		"avg10":  0.12,
+		"avg60":  0.05,
+		"avg300": 0.0,
+		"total":  int64(12345),
+	}, fields)
+
+	_, _, err = parseLine("partial avg10=0.12")
+	require.Error(t, err)
+	_, _, err = parseLine("some total=abc")
+	require.Error(t, err)
+}
@@ -60,6 +60,16 @@ const sampleConfig = `
   ## Privacy password used for encrypted messages.
   # priv_password = ""
 
+  ## Translator used to resolve OIDs and the columns of tables, either
+  ## "netsnmp" to run the snmptranslate and snmptable programs or "native" to
+  ## parse the MIB files found in mib_paths.  The native translator falls
+  ## back to the net-snmp programs for OIDs not found in the loaded MIBs.
+  # translator = "netsnmp"
+
+  ## Directories to load MIB files from, including their subdirectories,
+  ## when using the native translator.
+  # mib_paths = ["/usr/share/snmp/mibs"]
+
   ## Add fields and tables defining the variables you wish to collect.  This
   ## example collects the system uptime and interface variables.  Reference the
   ## full plugin documentation for configuration details.
@@ -124,6 +134,11 @@ type Snmp struct {
 	EngineBoots  uint32 `toml:"-"`
 	EngineTime   uint32 `toml:"-"`
 
+	// Values: "netsnmp", "native". Default: "netsnmp"
+	Translator string `toml:"translator"`
+	// Directories to load MIB files from with the native translator.
+	MibPaths []string `toml:"mib_paths"`
+
 	Tables []Table `toml:"table"`
 
 	// Name & Fields are the elements of a Table.
@@ -132,7 +147,10 @@ type Snmp struct {
 	Name   string  // deprecated in 1.14; use name_override
 	Fields []Field `toml:"field"`
 
+	Log telegraf.Logger `toml:"-"`
+
 	connectionCache []snmpConnection
+	translator      translator
 	initialized     bool
 }
 
@@ -143,14 +161,20 @@ func (s *Snmp) init() error {
 
 	s.connectionCache = make([]snmpConnection, len(s.Agents))
 
+	tr, err := newTranslator(s.Translator, s.MibPaths, s.Log)
+	if err != nil {
+		return err
+	}
+	s.translator = tr
+
 	for i := range s.Tables {
-		if err := s.Tables[i].init(); err != nil {
+		if err := s.Tables[i].init(s.translator); err != nil {
 			return Errorf(err, "initializing table %s", s.Tables[i].Name)
 		}
 	}
 
 	for i := range s.Fields {
-		if err := s.Fields[i].init(); err != nil {
+		if err := s.Fields[i].init(s.translator); err != nil {
 			return Errorf(err, "initializing field %s", s.Fields[i].Name)
 		}
 	}
@@ -182,18 +206,18 @@ type Table struct {
 }
 
 // init() builds & initializes the nested fields.
-func (t *Table) init() error {
+func (t *Table) init(tr translator) error {
 	if t.initialized {
 		return nil
 	}
 
-	if err := t.initBuild(); err != nil {
+	if err := t.initBuild(tr); err != nil {
 		return err
 	}
 
 	// initialize all the nested fields
 	for i := range t.Fields {
-		if err := t.Fields[i].init(); err != nil {
+		if err := t.Fields[i].init(tr); err != nil {
 			return Errorf(err, "initializing field %s", t.Fields[i].Name)
 		}
 	}
@@ -203,14 +227,14 @@ func (t *Table) init() error {
 }
 
 // initBuild initializes the table if it has an OID configured. If so, the
-// net-snmp tools will be used to look up the OID and auto-populate the table's
+// translator will be used to look up the OID and auto-populate the table's
 // fields.
-func (t *Table) initBuild() error {
+func (t *Table) initBuild(tr translator) error {
 	if t.Oid == "" {
 		return nil
 	}
 
-	_, _, oidText, fields, err := snmpTable(t.Oid)
+	_, _, oidText, fields, err := tr.SnmpTable(t.Oid)
 	if err != nil {
 		return err
 	}
@@ -259,12 +283,12 @@ type Field struct {
 }
 
 // init() converts OID names to numbers, and sets the .Name attribute if unset.
-func (f *Field) init() error {
+func (f *Field) init(tr translator) error {
 	if f.initialized {
 		return nil
 	}
 
-	_, oidNum, oidText, conversion, err := SnmpTranslate(f.Oid)
+	_, oidNum, oidText, conversion, err := tr.SnmpTranslate(f.Oid)
 	if err != nil {
 		return Errorf(err, "translating")
 	}
@@ -331,6 +355,8 @@ func init() {
 			Timeout:        internal.Duration{Duration: 5 * time.Second},
 			Version:        2,
 			Community:      "public",
+			Translator:     "netsnmp",
+			MibPaths:       []string{"/usr/share/snmp/mibs"},
 		}
 	})
 }
@@ -1039,12 +1065,7 @@ func snmpTranslateCall(oid string) (mibName string, oidNum string, oidText strin
 
 		if strings.HasPrefix(line, "  -- TEXTUAL CONVENTION ") {
 			tc := strings.TrimPrefix(line, "  -- TEXTUAL CONVENTION ")
-			switch tc {
-			case "MacAddress", "PhysAddress":
-				conversion = "hwaddr"
-			case "InetAddressIPv4", "InetAddressIPv6", "InetAddress", "IPSIpAddress":
-				conversion = "ipaddr"
-			}
+			conversion = textualConventionConversion(tc)
 		} else if strings.HasPrefix(line, "::= { ") {
 			objs := strings.TrimPrefix(line, "::= { ")
 			objs = strings.TrimSuffix(objs, " }")
@@ -1066,3 +1087,15 @@ func snmpTranslateCall(oid string) (mibName string, oidNum string, oidText strin
 
 	return mibName, oidNum, oidText, conversion, nil
 }
+
+// textualConventionConversion returns the conversion used for values of a
+// textual convention.
+func textualConventionConversion(tc string) string {
+	switch tc {
+	case "MacAddress", "PhysAddress":
+		return "hwaddr"
+	case "InetAddressIPv4", "InetAddressIPv6", "InetAddress", "IPSIpAddress":
+		return "ipaddr"
+	}
+	return ""
+}
@@ -95,6 +95,8 @@ func TestSampleConfig(t *testing.T) {
 		MaxRepetitions: 10,
 		Retries:        3,
 		Name:           "snmp",
+		Translator:     "netsnmp",
+		MibPaths:       []string{"/usr/share/snmp/mibs"},
 	}
 	require.Equal(t, expected, conf)
 }
@@ -124,7 +126,7 @@ func TestFieldInit(t *testing.T) {
 
 	for _, txl := range translations {
 		f := Field{Oid: txl.inputOid, Name: txl.inputName, Conversion: txl.inputConversion}
-		err := f.init()
+		err := f.init(netsnmpTranslator{})
 		if !assert.NoError(t, err, "inputOid='%s' inputName='%s'", txl.inputOid, txl.inputName) {
 			continue
 		}
@@ -141,7 +143,7 @@ func TestTableInit(t *testing.T) {
 			{Oid: "TEST::description", Name: "description", IsTag: true},
 		},
 	}
-	err := tbl.init()
+	err := tbl.init(netsnmpTranslator{})
 	require.NoError(t, err)
 
 	assert.Equal(t, "testTable", tbl.Name)
@@ -154,6 +156,53 @@ func TestTableInit(t *testing.T) {
 	assert.Contains(t, tbl.Fields, Field{Oid: ".1.0.0.0.1.4", Name: "description", IsTag: true, initialized: true})
 }
 
+func TestTableInit_native(t *testing.T) {
+	tr, err := newTranslator("native", []string{"testdata"}, testutil.Logger{})
+	require.NoError(t, err)
+
+	tbl := Table{
+		Oid: "TEST::testTable",
+		Fields: []Field{
+			{Oid: "TEST::hostname", Name: "hostname", IsTag: true},
+		},
+	}
+	err = tbl.init(tr)
+	require.NoError(t, err)
+
+	assert.Equal(t, "testTable", tbl.Name)
+
+	assert.Len(t, tbl.Fields, 5)
+	assert.Contains(t, tbl.Fields, Field{Oid: ".1.0.0.1.1", Name: "hostname", IsTag: true, initialized: true})
+	assert.Contains(t, tbl.Fields, Field{Oid: ".1.0.0.0.1.1", Name: "server", IsTag: true, initialized: true})
+	assert.Contains(t, tbl.Fields, Field{Oid: ".1.0.0.0.1.2", Name: "connections", initialized: true})
+	assert.Contains(t, tbl.Fields, Field{Oid: ".1.0.0.0.1.3", Name: "latency", initialized: true})
+	assert.Contains(t, tbl.Fields, Field{Oid: ".1.0.0.0.1.4", Name: "description", initialized: true})
+}
+
+func TestFieldInit_native(t *testing.T) {
+	tr, err := newTranslator("native", []string{"testdata"}, testutil.Logger{})
+	require.NoError(t, err)
+
+	translations := []struct {
+		inputOid     string
+		expectedOid  string
+		expectedName string
+	}{
+		{".1.0.0.0.1.1", ".1.0.0.0.1.1", "server"},
+		{".1.0.0.0.1.1.0", ".1.0.0.0.1.1.0", "server.0"},
+		{"TEST::server.0", ".1.0.0.0.1.1.0", "server.0"},
+		{"hostname", ".1.0.0.1.1", "hostname"},
+	}
+
+	for _, txl := range translations {
+		f := Field{Oid: txl.inputOid}
+		err := f.init(tr)
+		require.NoError(t, err, txl.inputOid)
+		assert.Equal(t, txl.expectedOid, f.Oid, txl.inputOid)
+		assert.Equal(t, txl.expectedName, f.Name, txl.inputOid)
+	}
+}
+
 func TestSnmpInit(t *testing.T) {
 	s := &Snmp{
 		Tables: []Table{
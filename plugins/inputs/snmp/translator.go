@@ -0,0 +1,96 @@
+package snmp
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	mib "github.com/influxdata/telegraf/internal/snmp"
+)
+
+// translator resolves OIDs and the columns of tables.
+type translator interface {
+	SnmpTranslate(oid string) (mibName string, oidNum string, oidText string, conversion string, err error)
+	SnmpTable(oid string) (mibName string, oidNum string, oidText string, fields []Field, err error)
+}
+
+func newTranslator(name string, mibPaths []string, log telegraf.Logger) (translator, error) {
+	switch name {
+	case "native":
+		tree, err := mib.LoadTree(mibPaths, log)
+		if err != nil {
+			return nil, err
+		}
+		return &nativeTranslator{tree: tree}, nil
+	case "", "netsnmp":
+		return netsnmpTranslator{}, nil
+	default:
+		return nil, fmt.Errorf("invalid translator %q", name)
+	}
+}
+
+// netsnmpTranslator uses the snmptranslate and snmptable programs of
+// net-snmp.
+type netsnmpTranslator struct{}
+
+func (netsnmpTranslator) SnmpTranslate(oid string) (string, string, string, string, error) {
+	return SnmpTranslate(oid)
+}
+
+func (netsnmpTranslator) SnmpTable(oid string) (string, string, string, []Field, error) {
+	return snmpTable(oid)
+}
+
+// nativeTranslator resolves OIDs using the MIB files it loaded, falling back
+// to net-snmp for OIDs it cannot resolve.
+type nativeTranslator struct {
+	tree     *mib.Tree
+	fallback netsnmpTranslator
+}
+
+func (n *nativeTranslator) SnmpTranslate(oid string) (string, string, string, string, error) {
+	node, suffix, err := n.tree.Lookup(oid)
+	if err == mib.ErrNotFound {
+		return n.fallback.SnmpTranslate(oid)
+	}
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	var conversion string
+	for _, tc := range node.Types {
+		if conversion = textualConventionConversion(tc); conversion != "" {
+			break
+		}
+	}
+	return node.Module, node.Oid + suffix, node.Name + suffix, conversion, nil
+}
+
+func (n *nativeTranslator) SnmpTable(oid string) (string, string, string, []Field, error) {
+	node, suffix, err := n.tree.Lookup(oid)
+	if err == mib.ErrNotFound || (err == nil && suffix != "") {
+		return n.fallback.SnmpTable(oid)
+	}
+	if err != nil {
+		return "", "", "", nil, err
+	}
+
+	columns, err := node.Columns()
+	if err != nil {
+		return "", "", "", nil, err
+	}
+
+	index := map[string]bool{}
+	for _, name := range node.Row().Index {
+		index[name] = true
+	}
+
+	fields := make([]Field, 0, len(columns))
+	for _, column := range columns {
+		fields = append(fields, Field{
+			Name:  column.Name,
+			Oid:   column.Module + "::" + column.Name,
+			IsTag: index[column.Name],
+		})
+	}
+	return node.Module, node.Oid, node.Name, fields, nil
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	mib "github.com/influxdata/telegraf/internal/snmp"
 	"github.com/influxdata/telegraf/plugins/inputs"
 
 	"github.com/soniah/gosnmp"
@@ -31,6 +32,8 @@ type mibEntry struct {
 type SnmpTrap struct {
 	ServiceAddress string            `toml:"service_address"`
 	Timeout        internal.Duration `toml:"timeout"`
+	Translator     string            `toml:"translator"`
+	MibPaths       []string          `toml:"mib_paths"`
 
 	acc      telegraf.Accumulator
 	listener *gosnmp.TrapListener
@@ -44,6 +47,7 @@ type SnmpTrap struct {
 	cacheLock sync.Mutex
 	cache     map[string]mibEntry
 
+	tree    *mib.Tree
 	execCmd execer
 }
 
@@ -58,6 +62,16 @@ var sampleConfig = `
   # service_address = "udp://:162"
   ## Timeout running snmptranslate command
   # timeout = "5s"
+
+  ## Translator used to resolve OIDs, either "netsnmp" to run the
+  ## snmptranslate program or "native" to parse the MIB files found in
+  ## mib_paths.  The native translator falls back to snmptranslate for OIDs
+  ## not found in the loaded MIBs.
+  # translator = "netsnmp"
+
+  ## Directories to load MIB files from, including their subdirectories,
+  ## when using the native translator.
+  # mib_paths = ["/usr/share/snmp/mibs"]
 `
 
 func (s *SnmpTrap) SampleConfig() string {
@@ -78,6 +92,8 @@ func init() {
 			timeFunc:       time.Now,
 			ServiceAddress: "udp://:162",
 			Timeout:        defaultTimeout,
+			Translator:     "netsnmp",
+			MibPaths:       []string{"/usr/share/snmp/mibs"},
 		}
 	})
 }
@@ -96,6 +112,18 @@ func realExecCmd(Timeout internal.Duration, arg0 string, args ...string) ([]byte
 func (s *SnmpTrap) Init() error {
 	s.cache = map[string]mibEntry{}
 	s.execCmd = realExecCmd
+
+	switch s.Translator {
+	case "native":
+		tree, err := mib.LoadTree(s.MibPaths, s.Log)
+		if err != nil {
+			return err
+		}
+		s.tree = tree
+	case "", "netsnmp":
+	default:
+		return fmt.Errorf("invalid translator %q", s.Translator)
+	}
 	return nil
 }
 
@@ -254,8 +282,8 @@ func (s *SnmpTrap) lookup(oid string) (e mibEntry, err error) {
 	defer s.cacheLock.Unlock()
 	var ok bool
 	if e, ok = s.cache[oid]; !ok {
-		// cache miss.  exec snmptranslate
-		e, err = s.snmptranslate(oid)
+		// cache miss.  resolve using the loaded MIBs or exec snmptranslate
+		e, err = s.translate(oid)
 		if err == nil {
 			s.cache[oid] = e
 		}
@@ -276,6 +304,18 @@ func (s *SnmpTrap) load(oid string, e mibEntry) {
 	s.cache[oid] = e
 }
 
+func (s *SnmpTrap) translate(oid string) (e mibEntry, err error) {
+	if s.tree != nil {
+		node, suffix, err := s.tree.Lookup(oid)
+		if err == nil {
+			e.mibName = node.Module
+			e.oidText = node.Name + suffix
+			return e, nil
+		}
+	}
+	return s.snmptranslate(oid)
+}
+
 func (s *SnmpTrap) snmptranslate(oid string) (e mibEntry, err error) {
 	var out []byte
 	out, err = s.execCmd(s.Timeout, "snmptranslate", "-Td", "-Ob", "-m", "all", oid)
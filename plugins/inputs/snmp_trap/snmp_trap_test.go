@@ -36,6 +36,29 @@ func TestLoad(t *testing.T) {
 	require.Equal(t, "coldStart", e.oidText)
 }
 
+func TestLookupNative(t *testing.T) {
+	s := &SnmpTrap{
+		Translator: "native",
+		MibPaths:   []string{"testdata"},
+		Log:        testutil.Logger{},
+	}
+	require.Nil(t, s.Init())
+	s.execCmd = fakeExecCmd
+
+	e, err := s.lookup(".1.2.3.0.1")
+	require.NoError(t, err)
+	require.Equal(t, mibEntry{"TEST-TRAP-MIB", "testTrap"}, e)
+
+	e, err = s.lookup(".1.2.3.4.5.0")
+	require.NoError(t, err)
+	require.Equal(t, mibEntry{"TEST-TRAP-MIB", "testTrapValue.0"}, e)
+
+	// OIDs not found in the loaded MIBs fall back to snmptranslate.
+	_, err = s.lookup(".1.3.6.1.6.3.1.1.5.1")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "mock snmptranslate")
+}
+
 func fakeExecCmd(_ internal.Duration, x string, y ...string) ([]byte, error) {
 	return nil, fmt.Errorf("mock " + x + " " + strings.Join(y, " "))
 }
@@ -0,0 +1,117 @@
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// normalize converts the values returned by the drivers to the types
+// supported by metrics; text is returned as a string and integers as int64.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case uint:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case uint16:
+		return uint64(v)
+	case uint8:
+		return uint64(v)
+	case float32:
+		return float64(v)
+	default:
+		return v
+	}
+}
+
+// convert converts a normalized value to the field type, values of date and
+// time columns become the unix time in nanoseconds unless converted to a
+// string.
+func convert(value interface{}, typ string) (interface{}, error) {
+	if t, ok := value.(time.Time); ok {
+		if typ == "string" {
+			return t.Format(time.RFC3339Nano), nil
+		}
+		value = t.UnixNano()
+	}
+
+	switch typ {
+	case "":
+		return value, nil
+	case "float":
+		switch v := value.(type) {
+		case int64:
+			return float64(v), nil
+		case uint64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		case bool:
+			if v {
+				return 1.0, nil
+			}
+			return 0.0, nil
+		case string:
+			return strconv.ParseFloat(v, 64)
+		}
+	case "int":
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case uint64:
+			return int64(v), nil
+		case float64:
+			return int64(v), nil
+		case bool:
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case string:
+			return strconv.ParseInt(v, 10, 64)
+		}
+	case "uint":
+		switch v := value.(type) {
+		case int64:
+			return uint64(v), nil
+		case uint64:
+			return v, nil
+		case float64:
+			return uint64(v), nil
+		case bool:
+			if v {
+				return uint64(1), nil
+			}
+			return uint64(0), nil
+		case string:
+			return strconv.ParseUint(v, 10, 64)
+		}
+	case "bool":
+		switch v := value.(type) {
+		case int64:
+			return v != 0, nil
+		case uint64:
+			return v != 0, nil
+		case float64:
+			return v != 0, nil
+		case bool:
+			return v, nil
+		case string:
+			return strconv.ParseBool(v)
+		}
+	case "string":
+		return fmt.Sprint(value), nil
+	}
+	return nil, fmt.Errorf("cannot convert %T to %s", value, typ)
+}
@@ -0,0 +1,29 @@
+package sql
+
+import (
+	"fmt"
+
+	_ "github.com/denisenkom/go-mssqldb"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/stdlib"
+)
+
+// driverNames maps the names accepted in the configuration to the names of
+// the registered database/sql drivers.
+var driverNames = map[string]string{
+	"postgres":  "pgx",
+	"pgx":       "pgx",
+	"mysql":     "mysql",
+	"sqlite":    "sqlite",
+	"sqlite3":   "sqlite",
+	"sqlserver": "sqlserver",
+	"mssql":     "sqlserver",
+}
+
+func driverName(driver string) (string, error) {
+	name, ok := driverNames[driver]
+	if !ok {
+		return "", fmt.Errorf("unsupported driver %q", driver)
+	}
+	return name, nil
+}
@@ -0,0 +1,81 @@
+package sql
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryScript(t *testing.T) {
+	f, err := ioutil.TempFile("", "telegraf-sql")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("SELECT requests FROM services")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	q := &Query{Script: f.Name()}
+	require.NoError(t, q.init())
+	require.Equal(t, "SELECT requests FROM services", q.Query)
+	require.Equal(t, "sql", q.Measurement)
+}
+
+func TestInitErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  *SQL
+	}{
+		{
+			name: "unsupported driver",
+			sql:  &SQL{Driver: "oracle", DataSourceName: "x", Queries: []*Query{{Query: "SELECT 1"}}},
+		},
+		{
+			name: "missing dsn",
+			sql:  &SQL{Driver: "mysql", Queries: []*Query{{Query: "SELECT 1"}}},
+		},
+		{
+			name: "missing query",
+			sql:  &SQL{Driver: "postgres", DataSourceName: "x", Queries: []*Query{{}}},
+		},
+		{
+			name: "conflicting types",
+			sql: &SQL{Driver: "sqlite", DataSourceName: "x", Queries: []*Query{{
+				Query:             "SELECT 1",
+				FieldColumnsInt:   []string{"a"},
+				FieldColumnsFloat: []string{"a"},
+			}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.sql.Init())
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		typ      string
+		expected interface{}
+	}{
+		{value: "1.5", typ: "float", expected: 1.5},
+		{value: int64(-3), typ: "int", expected: int64(-3)},
+		{value: "12", typ: "uint", expected: uint64(12)},
+		{value: int64(0), typ: "bool", expected: false},
+		{value: 2.5, typ: "string", expected: "2.5"},
+		{value: time.Unix(1, 0).UTC(), typ: "", expected: int64(1e9)},
+		{value: time.Unix(1, 0).UTC(), typ: "string", expected: "1970-01-01T00:00:01Z"},
+	}
+	for _, tt := range tests {
+		v, err := convert(tt.value, tt.typ)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, v)
+	}
+
+	_, err := convert("abc", "int")
+	require.Error(t, err)
+}
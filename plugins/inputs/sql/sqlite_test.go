@@ -0,0 +1,155 @@
+// +build darwin,amd64 darwin,arm64 freebsd,386 freebsd,amd64 linux,386 linux,amd64 linux,arm linux,arm64 linux,ppc64le linux,riscv64 linux,s390x netbsd,amd64 openbsd,amd64 windows,386 windows,amd64 windows,arm64
+
+package sql
+
+import (
+	gosql "database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLite(t *testing.T, queries ...*Query) (*SQL, func()) {
+	dir, err := ioutil.TempDir("", "telegraf-sql")
+	require.NoError(t, err)
+
+	dsn := filepath.Join(dir, "test.db")
+	db, err := gosql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	for _, stmt := range []string{
+		`CREATE TABLE services (name TEXT, kind TEXT, requests INTEGER, latency REAL, healthy INTEGER, version TEXT, updated INTEGER)`,
+		`INSERT INTO services VALUES ('api', 'http', 42, 0.5, 1, '3', 1600000000)`,
+		`INSERT INTO services VALUES ('db', 'sql', 7, 1.25, 0, NULL, 1600000060)`,
+	} {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err)
+	}
+
+	s := &SQL{
+		Driver:             "sqlite",
+		DataSourceName:     dsn,
+		MaxIdleConnections: 2,
+		Timeout:            internal.Duration{Duration: 5 * time.Second},
+		Queries:            queries,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+
+	return s, func() {
+		s.Stop()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestGather(t *testing.T) {
+	s, cleanup := newSQLite(t, &Query{
+		Query:               "SELECT name, kind, requests, latency, healthy, version, updated FROM services ORDER BY name",
+		TimeColumn:          "updated",
+		TagColumnsInclude:   []string{"name", "kind"},
+		FieldColumnsExclude: []string{"version"},
+		FieldColumnsBool:    []string{"healthy"},
+	})
+	defer cleanup()
+
+	var acc testutil.Accumulator
+	require.NoError(t, s.Start(&acc))
+	require.NoError(t, acc.GatherError(s.Gather))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"sql",
+			map[string]string{"name": "api", "kind": "http"},
+			map[string]interface{}{
+				"requests": int64(42),
+				"latency":  0.5,
+				"healthy":  true,
+			},
+			time.Unix(1600000000, 0),
+		),
+		testutil.MustMetric(
+			"sql",
+			map[string]string{"name": "db", "kind": "sql"},
+			map[string]interface{}{
+				"requests": int64(7),
+				"latency":  1.25,
+				"healthy":  false,
+			},
+			time.Unix(1600000060, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestGatherMeasurementColumn(t *testing.T) {
+	s, cleanup := newSQLite(t, &Query{
+		Query:              "SELECT kind, version, requests FROM services WHERE name = 'api'",
+		MeasurementColumn:  "kind",
+		FieldColumnsInt:    []string{"version"},
+		FieldColumnsString: []string{"requests"},
+	})
+	defer cleanup()
+
+	var acc testutil.Accumulator
+	require.NoError(t, s.Start(&acc))
+	require.NoError(t, acc.GatherError(s.Gather))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"http",
+			map[string]string{},
+			map[string]interface{}{
+				"version":  int64(3),
+				"requests": "42",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherQueryError(t *testing.T) {
+	s, cleanup := newSQLite(t,
+		&Query{Query: "SELECT requests FROM missing"},
+		&Query{Query: "SELECT requests FROM services", Measurement: "services"},
+	)
+	defer cleanup()
+
+	var acc testutil.Accumulator
+	require.NoError(t, s.Start(&acc))
+	require.NoError(t, s.Gather(&acc))
+
+	require.Len(t, acc.Errors, 1)
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+}
+
+func TestGatherRecoversConnection(t *testing.T) {
+	s, cleanup := newSQLite(t, &Query{Query: "SELECT requests FROM services"})
+	defer cleanup()
+
+	// A directory in place of the database fails to connect.
+	moved := s.DataSourceName + ".moved"
+	require.NoError(t, os.Rename(s.DataSourceName, moved))
+	require.NoError(t, os.Mkdir(s.DataSourceName, 0755))
+
+	var acc testutil.Accumulator
+	require.NoError(t, s.Start(&acc))
+	require.NoError(t, s.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	require.NoError(t, os.Remove(s.DataSourceName))
+	require.NoError(t, os.Rename(moved, s.DataSourceName))
+
+	acc = testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(s.Gather))
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+}
@@ -21,6 +21,9 @@ const (
 	eventSuccess = "success"
 )
 
+// service check statuses, indexed by their value
+var serviceCheckStatuses = []string{"ok", "warning", "critical", "unknown"}
+
 var uncommenter = strings.NewReplacer("\\n", "\n")
 
 func (s *Statsd) parseEventMessage(now time.Time, message string, defaultHostname string) error {
@@ -137,6 +140,74 @@ func (s *Statsd) parseEventMessage(now time.Time, message string, defaultHostnam
 	return nil
 }
 
+func (s *Statsd) parseServiceCheckMessage(now time.Time, message string, defaultHostname string) error {
+	// _sc|name|status
+	//  [
+	//   |d:timestamp
+	//   |h:hostname
+	//   |#tag1,tag2
+	//   |m:service_check_message
+	//  ]
+	//
+	//
+	// tag is key:value, the message must be the last field
+	rawFields := strings.Split(message, "|")
+	if len(rawFields) < 3 || rawFields[0] != "_sc" {
+		return fmt.Errorf("Invalid service check format")
+	}
+
+	name := rawFields[1]
+	if name == "" {
+		return fmt.Errorf("Invalid service check format: empty 'name' field")
+	}
+
+	status, err := strconv.ParseInt(rawFields[2], 10, 64)
+	if err != nil || status < 0 || status >= int64(len(serviceCheckStatuses)) {
+		return fmt.Errorf("Invalid service check format, could not parse status: '%s'", rawFields[2])
+	}
+
+	tags := make(map[string]string, strings.Count(message, ",")+2) // allocate for the approximate number of tags
+	fields := make(map[string]interface{}, 4)
+	fields["status"] = status
+	fields["status_text"] = serviceCheckStatuses[status]
+	if defaultHostname != "" {
+		tags["source"] = defaultHostname
+	}
+
+	for i := 3; i < len(rawFields); i++ {
+		if len(rawFields[i]) < 2 {
+			return errors.New("too short metadata field")
+		}
+		switch rawFields[i][:2] {
+		case "d:":
+			ts, err := strconv.ParseInt(rawFields[i][2:], 10, 64)
+			if err != nil {
+				continue
+			}
+			fields["ts"] = ts
+		case "h:":
+			tags["source"] = rawFields[i][2:]
+		case "m:":
+			// the message may contain pipes itself
+			fields["message"] = uncommenter.Replace(strings.Join(rawFields[i:], "|")[2:])
+			i = len(rawFields)
+		default:
+			if rawFields[i][0] == '#' {
+				parseDataDogTags(tags, rawFields[i][1:])
+			} else {
+				return fmt.Errorf("unknown metadata type: '%s'", rawFields[i])
+			}
+		}
+	}
+	// Use source tag because host is reserved tag key in Telegraf.
+	if host, ok := tags["host"]; ok {
+		delete(tags, "host")
+		tags["source"] = host
+	}
+	s.acc.AddFields(name, fields, tags, now)
+	return nil
+}
+
 func parseDataDogTags(tags map[string]string, message string) {
 	if len(message) == 0 {
 		return
@@ -1,6 +1,7 @@
 package statsd
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
@@ -482,3 +483,115 @@ func TestEventError(t *testing.T) {
 	err = s.parseEventMessage(now, "_e{5,4}:title|text|x:1234", "default-hostname")
 	require.Error(t, err)
 }
+
+func TestServiceCheck(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		message  string
+		hostname string
+		tags     map[string]string
+		fields   map[string]interface{}
+	}{
+		{
+			name:     "minimal",
+			message:  "_sc|agent.up|0",
+			hostname: "default-hostname",
+			tags:     map[string]string{"source": "default-hostname"},
+			fields: map[string]interface{}{
+				"status":      int64(0),
+				"status_text": "ok",
+			},
+		},
+		{
+			name:     "all metadata",
+			message:  "_sc|agent.up|2|d:21|h:localhost|#tag1:test,tag2|m:this is fine",
+			hostname: "default-hostname",
+			tags: map[string]string{
+				"source": "localhost",
+				"tag1":   "test",
+				"tag2":   "true",
+			},
+			fields: map[string]interface{}{
+				"status":      int64(2),
+				"status_text": "critical",
+				"ts":          int64(21),
+				"message":     "this is fine",
+			},
+		},
+		{
+			name:     "message with pipes and host tag",
+			message:  "_sc|agent.up|1|#host:foo|m:line1\\nline2|line3",
+			hostname: "default-hostname",
+			tags:     map[string]string{"source": "foo"},
+			fields: map[string]interface{}{
+				"status":      int64(1),
+				"status_text": "warning",
+				"message":     "line1\nline2|line3",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewTestStatsd()
+			acc := &testutil.Accumulator{}
+			s.acc = acc
+
+			require.NoError(t, s.parseServiceCheckMessage(now, tt.message, tt.hostname))
+			require.Equal(t, uint64(1), acc.NMetrics())
+			require.Equal(t, "agent.up", acc.Metrics[0].Measurement)
+			require.Equal(t, tt.tags, acc.Metrics[0].Tags)
+			require.Equal(t, tt.fields, acc.Metrics[0].Fields)
+			require.Equal(t, now, acc.Metrics[0].Time)
+		})
+	}
+}
+
+func TestServiceCheckError(t *testing.T) {
+	now := time.Now()
+	s := NewTestStatsd()
+	s.acc = &testutil.Accumulator{}
+
+	// not enough information
+	err := s.parseServiceCheckMessage(now, "_sc|agent.up", "default-hostname")
+	require.Error(t, err)
+
+	// empty name
+	err = s.parseServiceCheckMessage(now, "_sc||0", "default-hostname")
+	require.Error(t, err)
+
+	// invalid status
+	err = s.parseServiceCheckMessage(now, "_sc|agent.up|5", "default-hostname")
+	require.Error(t, err)
+
+	err = s.parseServiceCheckMessage(now, "_sc|agent.up|ok", "default-hostname")
+	require.Error(t, err)
+
+	// invalid timestamp
+	err = s.parseServiceCheckMessage(now, "_sc|agent.up|0|d:abc", "default-hostname")
+	require.NoError(t, err)
+
+	// unknown metadata
+	err = s.parseServiceCheckMessage(now, "_sc|agent.up|0|x:1234", "default-hostname")
+	require.Error(t, err)
+}
+
+func TestServiceCheckParser(t *testing.T) {
+	s := NewTestStatsd()
+	s.DataDogExtensions = true
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	s.in <- input{
+		Buffer: bytes.NewBufferString("_sc|agent.up|0|#env:prod\n"),
+		Time:   time.Now(),
+		Addr:   "127.0.0.1",
+	}
+	acc.Wait(1)
+
+	acc.AssertContainsTaggedFields(t, "agent.up",
+		map[string]interface{}{"status": int64(0), "status_text": "ok"},
+		map[string]string{"source": "127.0.0.1", "env": "prod"})
+}
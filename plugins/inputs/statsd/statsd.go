@@ -3,9 +3,11 @@ package statsd
 import (
 	"bufio"
 	"bytes"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	tlsint "github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/graphite"
 	"github.com/influxdata/telegraf/selfstat"
@@ -37,12 +40,15 @@ const (
 
 // Statsd allows the importing of statsd and dogstatsd data.
 type Statsd struct {
-	// Protocol used on listener - udp or tcp
+	// Protocol used on listener - udp, tcp or unixgram
 	Protocol string `toml:"protocol"`
 
-	// Address & Port to serve from
+	// Address & Port to serve from, or the socket path for unixgram
 	ServiceAddress string
 
+	// File mode bits of the unixgram socket
+	SocketMode string `toml:"socket_mode"`
+
 	// Number of messages allowed to queue up in between calls to Gather. If this
 	// fills up, packets will get dropped until the next Gather interval is ran.
 	AllowedPendingMessages int
@@ -65,7 +71,7 @@ type Statsd struct {
 	ParseDataDogTags bool // depreciated in 1.10; use datadog_extensions
 
 	// Parses extensions to statsd in the datadog statsd format
-	// currently supports metrics and datadog tags.
+	// currently supports metrics, datadog tags, events and service checks.
 	// http://docs.datadoghq.com/guides/dogstatsd/
 	DataDogExtensions bool `toml:"datadog_extensions"`
 
@@ -106,17 +112,21 @@ type Statsd struct {
 	Templates []string
 
 	// Protocol listeners
-	UDPlistener *net.UDPConn
-	TCPlistener *net.TCPListener
+	UDPlistener      *net.UDPConn
+	TCPlistener      *net.TCPListener
+	UnixgramListener *net.UnixConn
 
 	// track current connections so we can close them in Stop()
-	conns map[string]*net.TCPConn
+	conns map[string]net.Conn
 
 	MaxTCPConnections int `toml:"max_tcp_connections"`
 
 	TCPKeepAlive       bool               `toml:"tcp_keep_alive"`
 	TCPKeepAlivePeriod *internal.Duration `toml:"tcp_keep_alive_period"`
 
+	tlsint.ServerConfig
+	tlsConfig *tls.Config
+
 	graphiteParser *graphite.GraphiteParser
 
 	acc telegraf.Accumulator
@@ -187,7 +197,7 @@ func (_ *Statsd) Description() string {
 }
 
 const sampleConfig = `
-  ## Protocol, must be "tcp", "udp", "udp4" or "udp6" (default=udp)
+  ## Protocol, must be "tcp", "udp", "udp4", "udp6" or "unixgram" (default=udp)
   protocol = "udp"
 
   ## MaxTCPConnection - applicable when protocol is set to tcp (default=250)
@@ -201,9 +211,18 @@ const sampleConfig = `
   ## Defaults to the OS configuration.
   # tcp_keep_alive_period = "2h"
 
-  ## Address and port to host UDP listener on
+  ## Address and port to host UDP listener on, or the socket path for unixgram
   service_address = ":8125"
 
+  ## Change the file mode bits of the unixgram socket, ex: socket_mode = "777"
+  # socket_mode = ""
+
+  ## Optional TLS configuration, only applies when protocol is set to tcp.
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key  = "/etc/telegraf/key.pem"
+  ## Enables client authentication if set.
+  # tls_allowed_cacerts = ["/etc/telegraf/clientca.pem"]
+
   ## The following configuration options control when telegraf clears it's cache
   ## of previous values. If set to false, then telegraf will only clear it's
   ## cache when the daemon is restarted.
@@ -343,7 +362,7 @@ func (s *Statsd) Start(ac telegraf.Accumulator) error {
 	s.in = make(chan input, s.AllowedPendingMessages)
 	s.done = make(chan struct{})
 	s.accept = make(chan bool, s.MaxTCPConnections)
-	s.conns = make(map[string]*net.TCPConn)
+	s.conns = make(map[string]net.Conn)
 	s.bufPool = sync.Pool{
 		New: func() interface{} {
 			return new(bytes.Buffer)
@@ -361,7 +380,8 @@ func (s *Statsd) Start(ac telegraf.Accumulator) error {
 		s.MetricSeparator = defaultSeparator
 	}
 
-	if s.isUDP() {
+	switch {
+	case s.isUDP():
 		address, err := net.ResolveUDPAddr(s.Protocol, s.ServiceAddress)
 		if err != nil {
 			return err
@@ -380,7 +400,45 @@ func (s *Statsd) Start(ac telegraf.Accumulator) error {
 			defer s.wg.Done()
 			s.udpListen(conn)
 		}()
-	} else {
+	case s.isUnixgram():
+		// ignore the error, listening reports if a stale socket is in the way
+		os.Remove(s.ServiceAddress)
+
+		address, err := net.ResolveUnixAddr(s.Protocol, s.ServiceAddress)
+		if err != nil {
+			return err
+		}
+
+		conn, err := net.ListenUnixgram(s.Protocol, address)
+		if err != nil {
+			return err
+		}
+
+		if s.SocketMode != "" {
+			// Convert from octal in string to int
+			i, err := strconv.ParseUint(s.SocketMode, 8, 32)
+			if err != nil {
+				conn.Close()
+				return err
+			}
+			os.Chmod(s.ServiceAddress, os.FileMode(uint32(i)))
+		}
+
+		s.Log.Infof("Unixgram listening on %q", s.ServiceAddress)
+		s.UnixgramListener = conn
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.udpListen(conn)
+		}()
+	default:
+		tlsConfig, err := s.ServerConfig.TLSConfig()
+		if err != nil {
+			return err
+		}
+		s.tlsConfig = tlsConfig
+
 		address, err := net.ResolveTCPAddr("tcp", s.ServiceAddress)
 		if err != nil {
 			return err
@@ -390,7 +448,11 @@ func (s *Statsd) Start(ac telegraf.Accumulator) error {
 			return err
 		}
 
-		s.Log.Infof("TCP listening on %q", listener.Addr().String())
+		if s.tlsConfig != nil {
+			s.Log.Infof("TCP listening with TLS on %q", listener.Addr().String())
+		} else {
+			s.Log.Infof("TCP listening on %q", listener.Addr().String())
+		}
 		s.TCPlistener = listener
 
 		s.wg.Add(1)
@@ -443,8 +505,14 @@ func (s *Statsd) tcpListen(listener *net.TCPListener) error {
 				s.wg.Add(1)
 				// generate a random id for this TCPConn
 				id := internal.RandomString(6)
-				s.remember(id, conn)
-				go s.handler(conn, id)
+				if s.tlsConfig != nil {
+					tlsConn := tls.Server(conn, s.tlsConfig)
+					s.remember(id, tlsConn)
+					go s.handler(tlsConn, id)
+				} else {
+					s.remember(id, conn)
+					go s.handler(conn, id)
+				}
 			default:
 				// We are over the connection limit, refuse & close.
 				s.refuser(conn)
@@ -453,10 +521,11 @@ func (s *Statsd) tcpListen(listener *net.TCPListener) error {
 	}
 }
 
-// udpListen starts listening for udp packets on the configured port.
-func (s *Statsd) udpListen(conn *net.UDPConn) error {
+// udpListen starts listening for udp packets on the configured port, or for
+// datagrams on the unixgram socket.
+func (s *Statsd) udpListen(conn packetConn) error {
 	if s.ReadBufferSize > 0 {
-		s.UDPlistener.SetReadBuffer(s.ReadBufferSize)
+		conn.SetReadBuffer(s.ReadBufferSize)
 	}
 
 	buf := make([]byte, UDP_MAX_PACKET_SIZE)
@@ -465,7 +534,7 @@ func (s *Statsd) udpListen(conn *net.UDPConn) error {
 		case <-s.done:
 			return nil
 		default:
-			n, addr, err := conn.ReadFromUDP(buf)
+			n, addr, err := conn.ReadFrom(buf)
 			if err != nil {
 				if !strings.Contains(err.Error(), "closed network") {
 					s.Log.Errorf("Error reading: %s", err.Error())
@@ -478,11 +547,18 @@ func (s *Statsd) udpListen(conn *net.UDPConn) error {
 			b := s.bufPool.Get().(*bytes.Buffer)
 			b.Reset()
 			b.Write(buf[:n])
+
+			// unixgram senders have no address to use as default hostname
+			var remoteIP string
+			if addr, ok := addr.(*net.UDPAddr); ok {
+				remoteIP = addr.IP.String()
+			}
+
 			select {
 			case s.in <- input{
 				Buffer: b,
 				Time:   time.Now(),
-				Addr:   addr.IP.String()}:
+				Addr:   remoteIP}:
 			default:
 				s.UDPPacketsDrop.Incr(1)
 				s.drops++
@@ -514,6 +590,8 @@ func (s *Statsd) parser() error {
 				case line == "":
 				case s.DataDogExtensions && strings.HasPrefix(line, "_e"):
 					s.parseEventMessage(in.Time, line, in.Addr)
+				case s.DataDogExtensions && strings.HasPrefix(line, "_sc"):
+					s.parseServiceCheckMessage(in.Time, line, in.Addr)
 				default:
 					s.parseStatsdLine(line)
 				}
@@ -590,7 +668,7 @@ func (s *Statsd) parseStatsdLine(line string) error {
 
 		// Validate metric type
 		switch pipesplit[1] {
-		case "g", "c", "s", "ms", "h":
+		case "g", "c", "s", "ms", "h", "d":
 			m.mtype = pipesplit[1]
 		default:
 			s.Log.Errorf("Metric type %q unsupported", pipesplit[1])
@@ -607,7 +685,7 @@ func (s *Statsd) parseStatsdLine(line string) error {
 		}
 
 		switch m.mtype {
-		case "g", "ms", "h":
+		case "g", "ms", "h", "d":
 			v, err := strconv.ParseFloat(pipesplit[0], 64)
 			if err != nil {
 				s.Log.Errorf("Parsing value to float64, unable to parse metric: %s", line)
@@ -647,6 +725,8 @@ func (s *Statsd) parseStatsdLine(line string) error {
 			m.tags["metric_type"] = "timing"
 		case "h":
 			m.tags["metric_type"] = "histogram"
+		case "d":
+			m.tags["metric_type"] = "distribution"
 		}
 		if len(lineTags) > 0 {
 			for k, v := range lineTags {
@@ -735,7 +815,7 @@ func parseKeyValue(keyvalue string) (string, string) {
 // Delete* options, because those are dealt with in the Gather function.
 func (s *Statsd) aggregate(m metric) {
 	switch m.mtype {
-	case "ms", "h":
+	case "ms", "h", "d":
 		// Check if the measurement exists
 		cached, ok := s.timings[m.hash]
 		if !ok {
@@ -820,7 +900,7 @@ func (s *Statsd) aggregate(m metric) {
 }
 
 // handler handles a single TCP Connection
-func (s *Statsd) handler(conn *net.TCPConn, id string) {
+func (s *Statsd) handler(conn net.Conn, id string) {
 	s.CurrentConnections.Incr(1)
 	s.TotalConnections.Incr(1)
 	// connection cleanup function
@@ -889,7 +969,7 @@ func (s *Statsd) forget(id string) {
 }
 
 // remember a TCP connection
-func (s *Statsd) remember(id string, conn *net.TCPConn) {
+func (s *Statsd) remember(id string, conn net.Conn) {
 	s.cleanup.Lock()
 	defer s.cleanup.Unlock()
 	s.conns[id] = conn
@@ -899,15 +979,19 @@ func (s *Statsd) Stop() {
 	s.Lock()
 	s.Log.Infof("Stopping the statsd service")
 	close(s.done)
-	if s.isUDP() {
+	switch {
+	case s.isUDP():
 		s.UDPlistener.Close()
-	} else {
+	case s.isUnixgram():
+		s.UnixgramListener.Close()
+		os.Remove(s.ServiceAddress) // ignore error
+	default:
 		s.TCPlistener.Close()
 		// Close all open TCP connections
 		//  - get all conns from the s.conns map and put into slice
 		//  - this is so the forget() function doesnt conflict with looping
 		//    over the s.conns map
-		var conns []*net.TCPConn
+		var conns []net.Conn
 		s.cleanup.Lock()
 		for _, conn := range s.conns {
 			conns = append(conns, conn)
@@ -932,6 +1016,17 @@ func (s *Statsd) isUDP() bool {
 	return strings.HasPrefix(s.Protocol, "udp")
 }
 
+// isUnixgram returns true if the protocol is a unix datagram socket.
+func (s *Statsd) isUnixgram() bool {
+	return s.Protocol == "unixgram"
+}
+
+// packetConn is a datagram listener, either UDP or unixgram.
+type packetConn interface {
+	net.PacketConn
+	SetReadBuffer(bytes int) error
+}
+
 func init() {
 	inputs.Add("statsd", func() telegraf.Input {
 		return &Statsd{
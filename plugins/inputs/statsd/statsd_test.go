@@ -1,12 +1,16 @@
 package statsd
 
 import (
+	"crypto/tls"
 	"fmt"
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -19,6 +23,8 @@ const (
 	producerThreads = 10
 )
 
+var pki = testutil.NewPKI("../../../testutil/pki")
+
 func NewTestStatsd() *Statsd {
 	s := Statsd{Log: testutil.Logger{}}
 
@@ -459,6 +465,40 @@ func TestParse_Timings(t *testing.T) {
 	acc.AssertContainsFields(t, "test_timing", valid)
 }
 
+// Tests that distributions are aggregated like timings
+func TestParse_Distributions(t *testing.T) {
+	s := NewTestStatsd()
+	s.DataDogExtensions = true
+	s.Percentiles = []internal.Number{{Value: 50.0}, {Value: 90.0}}
+	acc := &testutil.Accumulator{}
+
+	validLines := []string{
+		"test.distribution:1|d",
+		"test.distribution:11|d",
+		"test.distribution:1|d|#env:prod",
+		"test.distribution:2|d|@0.5",
+	}
+
+	for _, line := range validLines {
+		require.NoError(t, s.parseStatsdLine(line))
+	}
+
+	s.Gather(acc)
+
+	valid := map[string]interface{}{
+		"50_percentile": float64(2),
+		"90_percentile": float64(11),
+		"count":         int64(4),
+		"lower":         float64(1),
+		"mean":          float64(4),
+		"stddev":        float64(4.06201920231798),
+		"sum":           float64(16),
+		"upper":         float64(11),
+	}
+	acc.AssertContainsTaggedFields(t, "test_distribution", valid,
+		map[string]string{"metric_type": "distribution"})
+}
+
 func TestParseScientificNotation(t *testing.T) {
 	s := NewTestStatsd()
 	sciNotationLines := []string{
@@ -1745,3 +1785,105 @@ func TestUdp(t *testing.T) {
 		testutil.IgnoreTime(),
 	)
 }
+
+func TestTCPWithTLS(t *testing.T) {
+	statsd := Statsd{
+		Log:                    testutil.Logger{},
+		Protocol:               "tcp",
+		ServiceAddress:         "127.0.0.1:0",
+		AllowedPendingMessages: 10000,
+		MaxTCPConnections:      2,
+		ServerConfig:           *pki.TLSServerConfig(),
+	}
+	var acc testutil.Accumulator
+	require.NoError(t, statsd.Start(&acc))
+	defer statsd.Stop()
+
+	tlsConfig, err := pki.TLSClientConfig().TLSConfig()
+	require.NoError(t, err)
+
+	conn, err := tls.Dial("tcp", statsd.TCPlistener.Addr().String(), tlsConfig)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("cpu.time_idle:42|c\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	require.Eventually(t, func() bool {
+		require.NoError(t, statsd.Gather(&acc))
+		return acc.NMetrics() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{
+			testutil.MustMetric(
+				"cpu_time_idle",
+				map[string]string{
+					"metric_type": "counter",
+				},
+				map[string]interface{}{
+					"value": 42,
+				},
+				time.Now(),
+				telegraf.Counter,
+			),
+		},
+		acc.GetTelegrafMetrics(),
+		testutil.IgnoreTime(),
+	)
+}
+
+func TestUnixgram(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "telegraf")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+	sock := filepath.Join(tmpdir, "statsd.sock")
+
+	statsd := Statsd{
+		Log:                    testutil.Logger{},
+		Protocol:               "unixgram",
+		ServiceAddress:         sock,
+		SocketMode:             "666",
+		AllowedPendingMessages: 10000,
+		DataDogExtensions:      true,
+	}
+	var acc testutil.Accumulator
+	require.NoError(t, statsd.Start(&acc))
+
+	info, err := os.Stat(sock)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0666), info.Mode().Perm())
+
+	conn, err := net.Dial("unixgram", sock)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("cpu.time_idle:42|g|#env:prod\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	require.Eventually(t, func() bool {
+		require.NoError(t, statsd.Gather(&acc))
+		return acc.NMetrics() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{
+			testutil.MustMetric(
+				"cpu_time_idle",
+				map[string]string{
+					"metric_type": "gauge",
+					"env":         "prod",
+				},
+				map[string]interface{}{
+					"value": 42.0,
+				},
+				time.Now(),
+				telegraf.Gauge,
+			),
+		},
+		acc.GetTelegrafMetrics(),
+		testutil.IgnoreTime(),
+	)
+
+	statsd.Stop()
+	_, err = os.Stat(sock)
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,108 @@
+// +build !solaris
+
+package tail
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+const (
+	defaultMultilineTimeout  = 5 * time.Second
+	defaultMultilineMaxLines = 500
+)
+
+// MultilineConfig groups consecutive lines into a single event.
+type MultilineConfig struct {
+	StartPattern    string            `toml:"start_pattern"`
+	ContinuePattern string            `toml:"continue_pattern"`
+	Timeout         internal.Duration `toml:"timeout"`
+	MaxLines        int               `toml:"max_lines"`
+
+	startRe    *regexp.Regexp
+	continueRe *regexp.Regexp
+}
+
+func (c *MultilineConfig) init() error {
+	if c.StartPattern == "" && c.ContinuePattern == "" {
+		return errors.New("multiline requires one of start_pattern or continue_pattern")
+	}
+	if c.StartPattern != "" && c.ContinuePattern != "" {
+		return errors.New("multiline start_pattern and continue_pattern are mutually exclusive")
+	}
+
+	var err error
+	if c.StartPattern != "" {
+		c.startRe, err = regexp.Compile(c.StartPattern)
+		if err != nil {
+			return err
+		}
+	}
+	if c.ContinuePattern != "" {
+		c.continueRe, err = regexp.Compile(c.ContinuePattern)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.Timeout.Duration == 0 {
+		c.Timeout.Duration = defaultMultilineTimeout
+	}
+	if c.MaxLines == 0 {
+		c.MaxLines = defaultMultilineMaxLines
+	}
+	return nil
+}
+
+// multiline buffers the lines of a single file until an event is complete.
+type multiline struct {
+	config *MultilineConfig
+	lines  []string
+}
+
+func newMultiline(config *MultilineConfig) *multiline {
+	return &multiline{config: config}
+}
+
+// processLine adds a line to the buffer and returns the events completed by
+// it, either because the line starts a new event or because the buffered
+// event reached the maximum number of lines.
+func (m *multiline) processLine(line string) []string {
+	var events []string
+
+	if len(m.lines) > 0 && !m.continues(line) {
+		if event, ok := m.flush(); ok {
+			events = append(events, event)
+		}
+	}
+
+	m.lines = append(m.lines, line)
+	if len(m.lines) >= m.config.MaxLines {
+		if event, ok := m.flush(); ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// continues reports whether line belongs to the buffered event.
+func (m *multiline) continues(line string) bool {
+	if m.config.startRe != nil {
+		return !m.config.startRe.MatchString(line)
+	}
+	return m.config.continueRe.MatchString(line)
+}
+
+// flush returns the buffered lines joined as one event and clears the buffer.
+func (m *multiline) flush() (string, bool) {
+	if len(m.lines) == 0 {
+		return "", false
+	}
+	event := strings.Join(m.lines, "\n")
+	m.lines = m.lines[:0]
+	return event, true
+}
@@ -0,0 +1,66 @@
+// +build !solaris
+
+package tail
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultilineConfigErrors(t *testing.T) {
+	c := &MultilineConfig{}
+	require.Error(t, c.init())
+
+	c = &MultilineConfig{StartPattern: "^a", ContinuePattern: "^b"}
+	require.Error(t, c.init())
+
+	c = &MultilineConfig{StartPattern: "("}
+	require.Error(t, c.init())
+}
+
+func TestMultilineStartPattern(t *testing.T) {
+	c := &MultilineConfig{StartPattern: `^\d`}
+	require.NoError(t, c.init())
+	m := newMultiline(c)
+
+	require.Empty(t, m.processLine("1 first"))
+	require.Empty(t, m.processLine("  at a"))
+	require.Empty(t, m.processLine("  at b"))
+	require.Equal(t, []string{"1 first\n  at a\n  at b"}, m.processLine("2 second"))
+
+	event, ok := m.flush()
+	require.True(t, ok)
+	require.Equal(t, "2 second", event)
+
+	_, ok = m.flush()
+	require.False(t, ok)
+}
+
+func TestMultilineContinuePattern(t *testing.T) {
+	c := &MultilineConfig{ContinuePattern: `^\s`}
+	require.NoError(t, c.init())
+	m := newMultiline(c)
+
+	require.Empty(t, m.processLine("Exception"))
+	require.Empty(t, m.processLine("\tat a"))
+	require.Equal(t, []string{"Exception\n\tat a"}, m.processLine("next"))
+	require.Equal(t, []string{"next"}, m.processLine("other"))
+}
+
+func TestMultilineMaxLines(t *testing.T) {
+	c := &MultilineConfig{ContinuePattern: `^\s`, MaxLines: 2}
+	require.NoError(t, c.init())
+	m := newMultiline(c)
+
+	require.Empty(t, m.processLine("a"))
+	require.Equal(t, []string{"a\n b"}, m.processLine(" b"))
+	require.Empty(t, m.processLine(" c"))
+
+	c = &MultilineConfig{ContinuePattern: `^\s`, MaxLines: 1}
+	require.NoError(t, c.init())
+	m = newMultiline(c)
+
+	require.Equal(t, []string{"a"}, m.processLine("a"))
+	require.Equal(t, []string{" b"}, m.processLine(" b"))
+}
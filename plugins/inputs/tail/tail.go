@@ -5,8 +5,11 @@ package tail
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/influxdata/tail"
 	"github.com/influxdata/telegraf"
@@ -36,7 +39,10 @@ type Tail struct {
 	WatchMethod         string   `toml:"watch_method"`
 	MaxUndeliveredLines int      `toml:"max_undelivered_lines"`
 
+	Multiline *MultilineConfig `toml:"multiline"`
+
 	Log        telegraf.Logger `toml:"-"`
+	mu         sync.Mutex
 	tailers    map[string]*tail.Tail
 	offsets    map[string]int64
 	parserFunc parsers.ParserFunc
@@ -93,6 +99,24 @@ const sampleConfig = `
   ## more about them here:
   ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
   data_format = "influx"
+
+  ## Group consecutive lines, such as stack traces, into a single event that
+  ## is passed to the parser as one buffer with the lines joined by newlines.
+  ## Set one of start_pattern or continue_pattern.
+  # [inputs.tail.multiline]
+    ## Lines matching the regular expression start a new event, other lines
+    ## are appended to the current event.
+    # start_pattern = '^\d{4}-\d{2}-\d{2}'
+
+    ## Lines matching the regular expression are appended to the current
+    ## event, other lines start a new event.
+    # continue_pattern = '^\s'
+
+    ## Time to wait for more lines before flushing a partial event.
+    # timeout = "5s"
+
+    ## Maximum number of lines in an event; longer events are split.
+    # max_lines = 500
 `
 
 func (t *Tail) SampleConfig() string {
@@ -108,6 +132,12 @@ func (t *Tail) Init() error {
 		return errors.New("max_undelivered_lines must be positive")
 	}
 	t.sem = make(semaphore, t.MaxUndeliveredLines)
+
+	if t.Multiline != nil {
+		if err := t.Multiline.init(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -133,12 +163,16 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 		}
 	}()
 
+	t.mu.Lock()
 	t.tailers = make(map[string]*tail.Tail)
+	t.mu.Unlock()
 
 	err := t.tailNewFiles(t.FromBeginning)
 
 	// clear offsets
+	t.mu.Lock()
 	t.offsets = make(map[string]int64)
+	t.mu.Unlock()
 	// assumption that once Start is called, all parallel plugins have already been initialized
 	offsetsMutex.Lock()
 	offsets = make(map[string]int64)
@@ -153,6 +187,9 @@ func (t *Tail) tailNewFiles(fromBeginning bool) error {
 		poll = true
 	}
 
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	// Create a "tailer" for each file
 	for _, filepath := range t.Files {
 		g, err := globpath.Compile(filepath)
@@ -166,14 +203,19 @@ func (t *Tail) tailNewFiles(fromBeginning bool) error {
 			}
 
 			var seek *tail.SeekInfo
-			if !t.Pipe && !fromBeginning {
+			if !t.Pipe {
 				if offset, ok := t.offsets[file]; ok {
+					// A file smaller than the offset has been truncated or
+					// replaced since, so read it from the start.
+					if info, err := os.Stat(file); err == nil && info.Size() < offset {
+						offset = 0
+					}
 					t.Log.Debugf("Using offset %d for %q", offset, file)
 					seek = &tail.SeekInfo{
 						Whence: 0,
 						Offset: offset,
 					}
-				} else {
+				} else if !fromBeginning {
 					seek = &tail.SeekInfo{
 						Whence: 2,
 						Offset: 0,
@@ -250,43 +292,101 @@ func parseLine(parser parsers.Parser, line string, firstLine bool) ([]telegraf.M
 // for changes, parse any incoming msgs, and add to the accumulator.
 func (t *Tail) receiver(parser parsers.Parser, tailer *tail.Tail) {
 	var firstLine = true
-	for line := range tailer.Lines {
-		if line.Err != nil {
-			t.Log.Errorf("Tailing %q: %s", tailer.Filename, line.Err.Error())
-			continue
-		}
-		// Fix up files with Windows line endings.
-		text := strings.TrimRight(line.Text, "\r")
-
-		metrics, err := parseLine(parser, text, firstLine)
-		if err != nil {
-			t.Log.Errorf("Malformed log line in %q: [%q]: %s",
-				tailer.Filename, line.Text, err.Error())
-			continue
-		}
-		firstLine = false
 
-		for _, metric := range metrics {
-			metric.AddTag("path", tailer.Filename)
-		}
+	var mline *multiline
+	var timer *time.Timer
+	var timeout <-chan time.Time
+	if t.Multiline != nil {
+		mline = newMultiline(t.Multiline)
+		timer = time.NewTimer(t.Multiline.Timeout.Duration)
+		defer timer.Stop()
+		timeout = timer.C
+	}
 
-		// Block until plugin is stopping or room is available to add metrics.
+	for {
 		select {
-		case <-t.ctx.Done():
-			return
-		case t.sem <- empty{}:
-			t.acc.AddTrackingMetricGroup(metrics)
+		case line, ok := <-tailer.Lines:
+			if !ok {
+				if mline != nil {
+					if event, ok := mline.flush(); ok {
+						t.parse(parser, tailer, event, &firstLine)
+					}
+				}
+				return
+			}
+			if line.Err != nil {
+				t.Log.Errorf("Tailing %q: %s", tailer.Filename, line.Err.Error())
+				continue
+			}
+			// Fix up files with Windows line endings.
+			text := strings.TrimRight(line.Text, "\r")
+
+			if mline == nil {
+				if !t.parse(parser, tailer, text, &firstLine) {
+					return
+				}
+				continue
+			}
+
+			for _, event := range mline.processLine(text) {
+				if !t.parse(parser, tailer, event, &firstLine) {
+					return
+				}
+			}
+
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(t.Multiline.Timeout.Duration)
+		case <-timeout:
+			// No line arrived in time, flush the partial event.
+			if event, ok := mline.flush(); ok {
+				if !t.parse(parser, tailer, event, &firstLine) {
+					return
+				}
+			}
+			timer.Reset(t.Multiline.Timeout.Duration)
 		}
 	}
 }
 
+// parse parses the text and adds the metrics to the accumulator.  It returns
+// false if the plugin is stopping.
+func (t *Tail) parse(parser parsers.Parser, tailer *tail.Tail, text string, firstLine *bool) bool {
+	metrics, err := parseLine(parser, text, *firstLine)
+	if err != nil {
+		t.Log.Errorf("Malformed log line in %q: [%q]: %s",
+			tailer.Filename, text, err.Error())
+		return true
+	}
+	*firstLine = false
+
+	for _, metric := range metrics {
+		metric.AddTag("path", tailer.Filename)
+	}
+
+	// Block until plugin is stopping or room is available to add metrics.
+	select {
+	case <-t.ctx.Done():
+		return false
+	case t.sem <- empty{}:
+		t.acc.AddTrackingMetricGroup(metrics)
+	}
+	return true
+}
+
 func (t *Tail) Stop() {
+	t.mu.Lock()
 	for _, tailer := range t.tailers {
-		if !t.Pipe && !t.FromBeginning {
+		if !t.Pipe {
 			// store offset for resume
 			offset, err := tailer.Tell()
 			if err == nil {
 				t.Log.Debugf("Recording offset %d for %q", offset, tailer.Filename)
+				t.offsets[tailer.Filename] = offset
 			} else {
 				t.Log.Errorf("Recording offset for %q: %s", tailer.Filename, err.Error())
 			}
@@ -296,18 +396,64 @@ func (t *Tail) Stop() {
 			t.Log.Errorf("Stopping tail on %q: %s", tailer.Filename, err.Error())
 		}
 	}
+	t.tailers = make(map[string]*tail.Tail)
+	t.mu.Unlock()
 
 	t.cancel()
 	t.wg.Wait()
 
 	// persist offsets
 	offsetsMutex.Lock()
+	t.mu.Lock()
 	for k, v := range t.offsets {
 		offsets[k] = v
 	}
+	t.mu.Unlock()
 	offsetsMutex.Unlock()
 }
 
+// GetState returns the offsets of the tailed files, keyed by file name.
+func (t *Tail) GetState() interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := make(map[string]int64, len(t.offsets)+len(t.tailers))
+	for file, offset := range t.offsets {
+		state[file] = offset
+	}
+	if t.Pipe {
+		return state
+	}
+	for file, tailer := range t.tailers {
+		offset, err := tailer.Tell()
+		if err != nil {
+			t.Log.Errorf("Recording offset for %q: %s", file, err.Error())
+			continue
+		}
+		state[file] = offset
+	}
+	return state
+}
+
+// SetState restores the offsets of the files, files with a known offset are
+// tailed from there regardless of from_beginning.
+func (t *Tail) SetState(state interface{}) error {
+	offsets, ok := state.(map[string]int64)
+	if !ok {
+		return fmt.Errorf("invalid state type %T", state)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.offsets == nil {
+		t.offsets = make(map[string]int64, len(offsets))
+	}
+	for file, offset := range offsets {
+		t.offsets[file] = offset
+	}
+	return nil
+}
+
 func (t *Tail) SetParserFunc(fn parsers.ParserFunc) {
 	t.parserFunc = fn
 }
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/plugins/parsers/csv"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
@@ -286,3 +287,138 @@ func TestMultipleMetricsOnFirstLine(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(),
 		testutil.IgnoreTime())
 }
+
+func TestTailMultilineGrok(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	defer tmpfile.Close()
+	_, err = tmpfile.WriteString("2020-01-01 ERROR failed\n  at a\n  at b\n2020-01-01 INFO done\n")
+	require.NoError(t, err)
+
+	tt := NewTail()
+	tt.Log = testutil.Logger{}
+	tt.FromBeginning = true
+	tt.Files = []string{tmpfile.Name()}
+	tt.Multiline = &MultilineConfig{
+		StartPattern: `^\d{4}-`,
+		Timeout:      internal.Duration{Duration: 100 * time.Millisecond},
+	}
+	tt.SetParserFunc(func() (parsers.Parser, error) {
+		return parsers.NewParser(&parsers.Config{
+			DataFormat:    "grok",
+			MetricName:    "log",
+			GrokPatterns:  []string{`(?s)^%{NOTSPACE} %{LOGLEVEL:level:tag} %{GREEDYDATA:message}`},
+			GrokMultiline: true,
+		})
+	})
+
+	err = tt.Init()
+	require.NoError(t, err)
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	defer tt.Stop()
+	require.NoError(t, acc.GatherError(tt.Gather))
+
+	// The last event is flushed by the timeout.
+	acc.Wait(2)
+	acc.AssertContainsTaggedFields(t, "log",
+		map[string]interface{}{
+			"message": "failed\n  at a\n  at b",
+		},
+		map[string]string{
+			"level": "ERROR",
+			"path":  tmpfile.Name(),
+		})
+	acc.AssertContainsTaggedFields(t, "log",
+		map[string]interface{}{
+			"message": "done",
+		},
+		map[string]string{
+			"level": "INFO",
+			"path":  tmpfile.Name(),
+		})
+}
+
+func TestTailResumeFromState(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	defer tmpfile.Close()
+	line := "cpu,mytag=foo usage_idle=100\n"
+	_, err = tmpfile.WriteString(line)
+	require.NoError(t, err)
+
+	tt := NewTail()
+	tt.Log = testutil.Logger{}
+	tt.FromBeginning = true
+	tt.Files = []string{tmpfile.Name()}
+	tt.SetParserFunc(parsers.NewInfluxParser)
+	require.NoError(t, tt.Init())
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	acc.Wait(1)
+	tt.Stop()
+
+	state := tt.GetState()
+	require.Equal(t, map[string]int64{tmpfile.Name(): int64(len(line))}, state)
+
+	_, err = tmpfile.WriteString("cpu,othertag=foo usage_idle=42\n")
+	require.NoError(t, err)
+
+	// A new instance restored from the state only reads the new line.
+	tt = NewTail()
+	tt.Log = testutil.Logger{}
+	tt.FromBeginning = true
+	tt.Files = []string{tmpfile.Name()}
+	tt.SetParserFunc(parsers.NewInfluxParser)
+	require.NoError(t, tt.Init())
+	require.NoError(t, tt.SetState(state))
+
+	acc = testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	defer tt.Stop()
+	acc.Wait(1)
+	acc.AssertContainsTaggedFields(t, "cpu",
+		map[string]interface{}{
+			"usage_idle": float64(42),
+		},
+		map[string]string{
+			"othertag": "foo",
+			"path":     tmpfile.Name(),
+		})
+	assert.Len(t, acc.Metrics, 1)
+}
+
+func TestTailStateOfTruncatedFile(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	defer tmpfile.Close()
+	_, err = tmpfile.WriteString("cpu,mytag=foo usage_idle=100\n")
+	require.NoError(t, err)
+
+	tt := NewTail()
+	tt.Log = testutil.Logger{}
+	tt.Files = []string{tmpfile.Name()}
+	tt.SetParserFunc(parsers.NewInfluxParser)
+	require.NoError(t, tt.Init())
+	require.NoError(t, tt.SetState(map[string]int64{tmpfile.Name(): 4096}))
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	defer tt.Stop()
+	acc.Wait(1)
+	acc.AssertContainsTaggedFields(t, "cpu",
+		map[string]interface{}{
+			"usage_idle": float64(100),
+		},
+		map[string]string{
+			"mytag": "foo",
+			"path":  tmpfile.Name(),
+		})
+
+	require.Error(t, tt.SetState("invalid"))
+}
@@ -0,0 +1,320 @@
+package websocket_listener
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	tlsint "github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"golang.org/x/net/websocket"
+)
+
+// defaultMaxMessageSize is the default maximum size of a message, in bytes.
+// Connections sending larger messages are closed.
+// 32 MB
+const defaultMaxMessageSize = 32 * 1024 * 1024
+
+// WebSocketListener is an input plugin that collects metrics sent over
+// WebSocket connections
+type WebSocketListener struct {
+	ServiceAddress string            `toml:"service_address"`
+	Path           string            `toml:"path"`
+	ReadTimeout    internal.Duration `toml:"read_timeout"`
+	MaxMessageSize internal.Size     `toml:"max_message_size"`
+	MaxConnections int               `toml:"max_connections"`
+	Port           int               `toml:"port"`
+	BasicUsername  string            `toml:"basic_username"`
+	BasicPassword  string            `toml:"basic_password"`
+	HTTPHeaderTags map[string]string `toml:"http_header_tags"`
+	AllowedOrigins []string          `toml:"allowed_origins"`
+	tlsint.ServerConfig
+
+	Log telegraf.Logger `toml:"-"`
+
+	wg sync.WaitGroup
+
+	listener net.Listener
+
+	mu     sync.Mutex
+	conns  map[*websocket.Conn]bool
+	closed bool
+
+	parsers.Parser
+	acc telegraf.Accumulator
+}
+
+const sampleConfig = `
+  ## Address and port to host the WebSocket listener on
+  service_address = ":8080"
+
+  ## Path to accept WebSocket connections on.
+  # path = "/telegraf"
+
+  ## Maximum duration without receiving a message before the connection is
+  ## closed, 0 to never close idle connections.
+  # read_timeout = "0s"
+
+  ## Maximum allowed message size in bytes, connections sending larger
+  ## messages are closed.
+  # max_message_size = "32MiB"
+
+  ## Maximum number of concurrent connections, 0 means unlimited.
+  # max_connections = 0
+
+  ## Set one or more allowed client CA certificate file names to
+  ## enable mutually authenticated TLS connections
+  # tls_allowed_cacerts = ["/etc/telegraf/clientca.pem"]
+
+  ## Add service certificate and key
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+
+  ## Optional username and password to accept for HTTP basic authentication
+  ## of the opening handshake.
+  ## You probably want to make sure you have TLS configured above for this.
+  # basic_username = "foobar"
+  # basic_password = "barfoo"
+
+  ## Optional setting to map http headers of the opening handshake into tags
+  ## If the http header is not present on the request, no corresponding tag will be added
+  ## If multiple instances of the http header are present, only the first value will be used
+  # http_header_tags = {"HTTP_HEADER" = "TAG_NAME"}
+
+  ## Origins allowed to open connections from a browser, such as
+  ## "https://example.com", or "*" for any origin.  By default only pages
+  ## served from the same host and port as the listener are allowed.
+  ## Requests without an Origin header, as sent by most clients other than
+  ## browsers, are always allowed.
+  # allowed_origins = []
+
+  ## Data format to consume.
+  ## Each data format has its own unique set of configuration options, read
+  ## more about them here:
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
+  data_format = "influx"
+`
+
+func (w *WebSocketListener) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WebSocketListener) Description() string {
+	return "Generic WebSocket listener"
+}
+
+func (w *WebSocketListener) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+func (w *WebSocketListener) SetParser(parser parsers.Parser) {
+	w.Parser = parser
+}
+
+// Start starts the WebSocket listener service.
+func (w *WebSocketListener) Start(acc telegraf.Accumulator) error {
+	if w.MaxMessageSize.Size == 0 {
+		w.MaxMessageSize.Size = defaultMaxMessageSize
+	}
+
+	w.acc = acc
+	w.conns = make(map[*websocket.Conn]bool)
+
+	tlsConf, err := w.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr:              w.ServiceAddress,
+		Handler:           w,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         tlsConf,
+	}
+
+	var listener net.Listener
+	if tlsConf != nil {
+		listener, err = tls.Listen("tcp", w.ServiceAddress, tlsConf)
+	} else {
+		listener, err = net.Listen("tcp", w.ServiceAddress)
+	}
+	if err != nil {
+		return err
+	}
+	w.listener = listener
+	w.Port = listener.Addr().(*net.TCPAddr).Port
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		server.Serve(w.listener)
+	}()
+
+	w.Log.Infof("Listening on %s", listener.Addr().String())
+
+	return nil
+}
+
+// Stop cleans up all resources
+func (w *WebSocketListener) Stop() {
+	w.listener.Close()
+
+	w.mu.Lock()
+	w.closed = true
+	for conn := range w.conns {
+		conn.Close()
+	}
+	w.mu.Unlock()
+
+	w.wg.Wait()
+}
+
+func (w *WebSocketListener) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != w.Path {
+		http.NotFound(res, req)
+		return
+	}
+
+	if !w.authenticated(req) {
+		http.Error(res, "Unauthorized.", http.StatusUnauthorized)
+		return
+	}
+
+	tags := make(map[string]string)
+	for headerName, tagName := range w.HTTPHeaderTags {
+		headerValues, foundHeader := req.Header[headerName]
+		if foundHeader && len(headerValues) > 0 {
+			tags[tagName] = headerValues[0]
+		}
+	}
+
+	server := websocket.Server{
+		Handshake: w.handshake,
+		Handler: func(conn *websocket.Conn) {
+			w.serveConn(conn, tags)
+		},
+	}
+	server.ServeHTTP(res, req)
+}
+
+// handshake refuses connections opened by browsers from pages of origins
+// that are not allowed, so that other sites cannot use the credentials of
+// the browser to send metrics.
+func (w *WebSocketListener) handshake(_ *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+
+	if len(w.AllowedOrigins) == 0 {
+		u, err := url.Parse(origin)
+		if err == nil && strings.EqualFold(u.Host, req.Host) {
+			return nil
+		}
+	}
+	for _, allowed := range w.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return nil
+		}
+	}
+
+	w.Log.Warnf("Refused connection from %s: origin %q not allowed", req.RemoteAddr, origin)
+	return fmt.Errorf("origin %q not allowed", origin)
+}
+
+func (w *WebSocketListener) serveConn(conn *websocket.Conn, tags map[string]string) {
+	if !w.track(conn) {
+		conn.Close()
+		return
+	}
+	defer w.untrack(conn)
+
+	conn.MaxPayloadBytes = int(w.MaxMessageSize.Size)
+
+	// The deadline of the HTTP server no longer applies to the hijacked
+	// connection.
+	conn.SetDeadline(time.Time{})
+
+	for {
+		if w.ReadTimeout.Duration > 0 {
+			conn.SetReadDeadline(time.Now().Add(w.ReadTimeout.Duration))
+		}
+
+		var msg []byte
+		err := websocket.Message.Receive(conn, &msg)
+		if err == websocket.ErrFrameTooLarge {
+			w.Log.Errorf("Message from %s larger than max_message_size, closing connection", conn.Request().RemoteAddr)
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		metrics, err := w.Parse(msg)
+		if err != nil {
+			w.Log.Debugf("Parse error: %s", err.Error())
+			continue
+		}
+
+		for _, m := range metrics {
+			for k, v := range tags {
+				m.AddTag(k, v)
+			}
+			w.acc.AddMetric(m)
+		}
+	}
+}
+
+func (w *WebSocketListener) track(conn *websocket.Conn) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return false
+	}
+	if w.MaxConnections > 0 && len(w.conns) >= w.MaxConnections {
+		w.Log.Warnf("Refused connection from %s: max_connections reached", conn.Request().RemoteAddr)
+		return false
+	}
+	w.conns[conn] = true
+	w.wg.Add(1)
+	return true
+}
+
+func (w *WebSocketListener) untrack(conn *websocket.Conn) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	conn.Close()
+	delete(w.conns, conn)
+	w.wg.Done()
+}
+
+func (w *WebSocketListener) authenticated(req *http.Request) bool {
+	if w.BasicUsername == "" || w.BasicPassword == "" {
+		return true
+	}
+
+	reqUsername, reqPassword, ok := req.BasicAuth()
+	return ok &&
+		subtle.ConstantTimeCompare([]byte(reqUsername), []byte(w.BasicUsername)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(reqPassword), []byte(w.BasicPassword)) == 1
+}
+
+func init() {
+	inputs.Add("websocket_listener", func() telegraf.Input {
+		return &WebSocketListener{
+			ServiceAddress: ":8080",
+			Path:           "/telegraf",
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package websocket_listener
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	testMsg = "cpu_load_short,host=server01 value=12.0 1422568543702900257\n"
+
+	basicUsername = "test-username-please-ignore"
+	basicPassword = "super-secure-password!"
+)
+
+func newTestListener() *WebSocketListener {
+	parser, _ := parsers.NewInfluxParser()
+
+	listener := &WebSocketListener{
+		Log:            testutil.Logger{},
+		ServiceAddress: "localhost:0",
+		Path:           "/telegraf",
+		Parser:         parser,
+	}
+	return listener
+}
+
+func dial(t *testing.T, listener *WebSocketListener, header http.Header) (*websocket.Conn, error) {
+	return dialOrigin(t, listener, fmt.Sprintf("http://localhost:%d", listener.Port), header)
+}
+
+func dialOrigin(t *testing.T, listener *WebSocketListener, origin string, header http.Header) (*websocket.Conn, error) {
+	url := fmt.Sprintf("ws://localhost:%d/telegraf", listener.Port)
+	config, err := websocket.NewConfig(url, origin)
+	require.NoError(t, err)
+	config.Header = header
+	return websocket.DialConfig(config)
+}
+
+func TestWriteFrames(t *testing.T) {
+	listener := newTestListener()
+	listener.HTTPHeaderTags = map[string]string{"X-Device": "device"}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	conn, err := dial(t, listener, http.Header{"X-Device": []string{"sensor01"}})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Text and binary frames are both accepted.
+	require.NoError(t, websocket.Message.Send(conn, testMsg))
+	require.NoError(t, websocket.Message.Send(conn, []byte(testMsg)))
+
+	acc.Wait(2)
+	expected := testutil.MustMetric(
+		"cpu_load_short",
+		map[string]string{"host": "server01", "device": "sensor01"},
+		map[string]interface{}{"value": 12.0},
+		time.Unix(0, 1422568543702900257),
+	)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{expected, expected}, acc.GetTelegrafMetrics())
+}
+
+func TestParseErrorKeepsConnection(t *testing.T) {
+	listener := newTestListener()
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	conn, err := dial(t, listener, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, websocket.Message.Send(conn, "not line protocol"))
+	require.NoError(t, websocket.Message.Send(conn, testMsg))
+
+	acc.Wait(1)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
+
+func TestBasicAuth(t *testing.T) {
+	listener := newTestListener()
+	listener.BasicUsername = basicUsername
+	listener.BasicPassword = basicPassword
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	_, err := dial(t, listener, nil)
+	require.Error(t, err)
+
+	auth := base64.StdEncoding.EncodeToString([]byte(basicUsername + ":" + basicPassword))
+	conn, err := dial(t, listener, http.Header{"Authorization": []string{"Basic " + auth}})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, websocket.Message.Send(conn, testMsg))
+	acc.Wait(1)
+}
+
+func TestAllowedOrigins(t *testing.T) {
+	listener := newTestListener()
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	_, err := dialOrigin(t, listener, "http://example.com", nil)
+	require.Error(t, err)
+
+	listener.AllowedOrigins = []string{"http://example.com"}
+	conn, err := dialOrigin(t, listener, "http://example.com", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = dialOrigin(t, listener, "http://example.org", nil)
+	require.Error(t, err)
+
+	require.NoError(t, websocket.Message.Send(conn, testMsg))
+	acc.Wait(1)
+}
+
+func TestMaxMessageSize(t *testing.T) {
+	listener := newTestListener()
+	listener.MaxMessageSize.Size = 16
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	conn, err := dial(t, listener, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, websocket.Message.Send(conn, testMsg))
+
+	// The listener closes the connection.
+	var msg []byte
+	require.Error(t, websocket.Message.Receive(conn, &msg))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestStopClosesConnections(t *testing.T) {
+	listener := newTestListener()
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Start(acc))
+
+	conn, err := dial(t, listener, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, websocket.Message.Send(conn, testMsg))
+	acc.Wait(1)
+
+	listener.Stop()
+
+	var msg []byte
+	require.Error(t, websocket.Message.Receive(conn, &msg))
+}
@@ -30,6 +30,12 @@ type Span interface {
 	Duration() time.Duration
 }
 
+// EndpointSpan is implemented by spans which carry the endpoint of the service
+// that recorded them, rather than having it on their annotations.
+type EndpointSpan interface {
+	Endpoint() Endpoint
+}
+
 // Annotation represents an event that explains latency with a timestamp.
 type Annotation interface {
 	Timestamp() time.Time
@@ -72,7 +78,12 @@ func NewTrace(spans []Span) (trace.Trace, error) {
 		if err != nil {
 			return nil, err
 		}
-		endpoint := serviceEndpoint(span.Annotations(), bin)
+		var endpoint Endpoint
+		if es, ok := span.(EndpointSpan); ok {
+			endpoint = es.Endpoint()
+		} else {
+			endpoint = serviceEndpoint(span.Annotations(), bin)
+		}
 		id, err := span.SpanID()
 		if err != nil {
 			return nil, err
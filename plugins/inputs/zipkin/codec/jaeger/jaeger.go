@@ -0,0 +1,271 @@
+package jaeger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/codec"
+)
+
+// Thrift decodes a Jaeger batch in the thrift binary protocol, as sent to the
+// HTTP endpoint of the Jaeger collector.
+type Thrift struct{}
+
+// Decode unmarshals the batch and returns its spans
+func (t *Thrift) Decode(octets []byte) ([]codec.Span, error) {
+	buffer := thrift.NewTMemoryBuffer()
+	if _, err := buffer.Write(octets); err != nil {
+		return nil, err
+	}
+
+	b, err := readBatch(thrift.NewTBinaryProtocolTransport(buffer))
+	if err != nil {
+		return nil, err
+	}
+	return newSpans(b), nil
+}
+
+// Compact decodes the emitBatch messages in the thrift compact protocol, as
+// sent by the Jaeger clients to the UDP port of the Jaeger agent.
+type Compact struct{}
+
+// Decode unmarshals the message and returns the spans of its batch
+func (c *Compact) Decode(octets []byte) ([]codec.Span, error) {
+	buffer := thrift.NewTMemoryBuffer()
+	if _, err := buffer.Write(octets); err != nil {
+		return nil, err
+	}
+	p := thrift.NewTCompactProtocol(buffer)
+
+	name, typ, _, err := p.ReadMessageBegin()
+	if err != nil {
+		return nil, err
+	}
+	if name != "emitBatch" {
+		return nil, fmt.Errorf("unsupported method %q", name)
+	}
+	if typ != thrift.ONEWAY && typ != thrift.CALL {
+		return nil, fmt.Errorf("unexpected message type %v", typ)
+	}
+
+	// The arguments of emitBatch are a struct holding the batch.
+	var b *batch
+	err = readStruct(p, func(id int16, typ thrift.TType) (bool, error) {
+		if id != 1 || typ != thrift.STRUCT {
+			return false, nil
+		}
+		var err error
+		b, err = readBatch(p)
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, fmt.Errorf("emitBatch without batch")
+	}
+	if err := p.ReadMessageEnd(); err != nil {
+		return nil, err
+	}
+	return newSpans(b), nil
+}
+
+func newSpans(b *batch) []codec.Span {
+	ep := newEndpoint(b.process)
+	res := make([]codec.Span, len(b.spans))
+	for i, s := range b.spans {
+		res[i] = &span{jspan: s, endpoint: ep}
+	}
+	return res
+}
+
+var _ codec.Endpoint = &endpoint{}
+
+// endpoint is the process that recorded the spans of a batch
+type endpoint struct {
+	host string
+	name string
+}
+
+func newEndpoint(proc *process) *endpoint {
+	ep := &endpoint{host: "0.0.0.0", name: proc.serviceName}
+	if ep.name == "" {
+		ep.name = codec.DefaultServiceName
+	}
+
+	// The clients report the address of the process in the "ip" tag, as a
+	// string or as an integer.
+	for _, t := range proc.tags {
+		if t.key != "ip" {
+			continue
+		}
+		switch t.vType {
+		case tagString:
+			if ip := net.ParseIP(t.vStr); ip != nil {
+				ep.host = ip.String()
+			}
+		case tagLong:
+			buf := make([]byte, 4)
+			binary.BigEndian.PutUint32(buf, uint32(t.vLong))
+			ep.host = net.IP(buf).String()
+		}
+	}
+	return ep
+}
+
+func (e *endpoint) Host() string {
+	return e.host
+}
+
+func (e *endpoint) Name() string {
+	return e.name
+}
+
+var _ codec.BinaryAnnotation = &binaryAnnotation{}
+
+// binaryAnnotation is a tag of a span
+type binaryAnnotation struct {
+	*tag
+	endpoint *endpoint
+}
+
+func (b *binaryAnnotation) Key() string {
+	return b.tag.key
+}
+
+func (b *binaryAnnotation) Value() string {
+	return b.tag.value()
+}
+
+func (b *binaryAnnotation) Host() codec.Endpoint {
+	return b.endpoint
+}
+
+func (t *tag) value() string {
+	switch t.vType {
+	case tagDouble:
+		return strconv.FormatFloat(t.vDouble, 'f', -1, 64)
+	case tagBool:
+		return strconv.FormatBool(t.vBool)
+	case tagLong:
+		return strconv.FormatInt(t.vLong, 10)
+	case tagBinary:
+		return string(t.vBinary)
+	default:
+		return t.vStr
+	}
+}
+
+var _ codec.Annotation = &annotation{}
+
+// annotation is a log of a span
+type annotation struct {
+	*spanLog
+	endpoint *endpoint
+}
+
+func (a *annotation) Timestamp() time.Time {
+	if a.spanLog.timestamp == 0 {
+		return time.Time{}
+	}
+	return codec.MicroToTime(a.spanLog.timestamp)
+}
+
+// Value returns the "event" field of the log, or all its fields if there is
+// none.
+func (a *annotation) Value() string {
+	fields := make([]string, 0, len(a.spanLog.fields))
+	for _, f := range a.spanLog.fields {
+		if f.key == "event" {
+			return f.value()
+		}
+		fields = append(fields, f.key+"="+f.value())
+	}
+	return strings.Join(fields, " ")
+}
+
+func (a *annotation) Host() codec.Endpoint {
+	return a.endpoint
+}
+
+var _ codec.Span = &span{}
+
+type span struct {
+	*jspan
+	endpoint *endpoint
+}
+
+func (s *span) Trace() (string, error) {
+	if s.jspan.traceIDHigh == 0 && s.jspan.traceIDLow == 0 {
+		return "", fmt.Errorf("Span does not have a trace ID")
+	}
+
+	if s.jspan.traceIDHigh == 0 {
+		return formatID(s.jspan.traceIDLow), nil
+	}
+	return fmt.Sprintf("%x%016x", uint64(s.jspan.traceIDHigh), uint64(s.jspan.traceIDLow)), nil
+}
+
+func (s *span) SpanID() (string, error) {
+	return formatID(s.jspan.spanID), nil
+}
+
+// Parent returns the parent span ID, or the span the span is a child of for
+// clients which only report references.
+func (s *span) Parent() (string, error) {
+	if s.jspan.parentSpanID != 0 {
+		return formatID(s.jspan.parentSpanID), nil
+	}
+	for _, ref := range s.jspan.references {
+		if ref.refType == refChildOf && ref.spanID != 0 {
+			return formatID(ref.spanID), nil
+		}
+	}
+	return "", nil
+}
+
+func (s *span) Name() string {
+	return s.jspan.operationName
+}
+
+func (s *span) Annotations() []codec.Annotation {
+	res := make([]codec.Annotation, len(s.jspan.logs))
+	for i := range s.jspan.logs {
+		res[i] = &annotation{spanLog: s.jspan.logs[i], endpoint: s.endpoint}
+	}
+	return res
+}
+
+func (s *span) BinaryAnnotations() ([]codec.BinaryAnnotation, error) {
+	res := make([]codec.BinaryAnnotation, len(s.jspan.tags))
+	for i := range s.jspan.tags {
+		res[i] = &binaryAnnotation{tag: s.jspan.tags[i], endpoint: s.endpoint}
+	}
+	return res, nil
+}
+
+func (s *span) Timestamp() time.Time {
+	if s.jspan.startTime == 0 {
+		return time.Time{}
+	}
+	return codec.MicroToTime(s.jspan.startTime)
+}
+
+func (s *span) Duration() time.Duration {
+	return time.Duration(s.jspan.duration) * time.Microsecond
+}
+
+// Endpoint returns the process that recorded the span, Jaeger spans do not
+// carry the service on their annotations.
+func (s *span) Endpoint() codec.Endpoint {
+	return s.endpoint
+}
+
+func formatID(id int64) string {
+	return strconv.FormatUint(uint64(id), 16)
+}
@@ -0,0 +1,274 @@
+package jaeger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/codec"
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTag(p thrift.TProtocol, t *tag) {
+	p.WriteStructBegin("Tag")
+	p.WriteFieldBegin("key", thrift.STRING, 1)
+	p.WriteString(t.key)
+	p.WriteFieldEnd()
+	p.WriteFieldBegin("vType", thrift.I32, 2)
+	p.WriteI32(t.vType)
+	p.WriteFieldEnd()
+	switch t.vType {
+	case tagString:
+		p.WriteFieldBegin("vStr", thrift.STRING, 3)
+		p.WriteString(t.vStr)
+	case tagDouble:
+		p.WriteFieldBegin("vDouble", thrift.DOUBLE, 4)
+		p.WriteDouble(t.vDouble)
+	case tagBool:
+		p.WriteFieldBegin("vBool", thrift.BOOL, 5)
+		p.WriteBool(t.vBool)
+	case tagLong:
+		p.WriteFieldBegin("vLong", thrift.I64, 6)
+		p.WriteI64(t.vLong)
+	case tagBinary:
+		p.WriteFieldBegin("vBinary", thrift.STRING, 7)
+		p.WriteBinary(t.vBinary)
+	}
+	p.WriteFieldEnd()
+	p.WriteFieldStop()
+	p.WriteStructEnd()
+}
+
+func writeTags(p thrift.TProtocol, id int16, tags []*tag) {
+	p.WriteFieldBegin("tags", thrift.LIST, id)
+	p.WriteListBegin(thrift.STRUCT, len(tags))
+	for _, t := range tags {
+		writeTag(p, t)
+	}
+	p.WriteListEnd()
+	p.WriteFieldEnd()
+}
+
+func writeI64(p thrift.TProtocol, id int16, v int64) {
+	p.WriteFieldBegin("", thrift.I64, id)
+	p.WriteI64(v)
+	p.WriteFieldEnd()
+}
+
+func writeBatch(p thrift.TProtocol, b *batch) {
+	p.WriteStructBegin("Batch")
+
+	p.WriteFieldBegin("process", thrift.STRUCT, 1)
+	p.WriteStructBegin("Process")
+	p.WriteFieldBegin("serviceName", thrift.STRING, 1)
+	p.WriteString(b.process.serviceName)
+	p.WriteFieldEnd()
+	writeTags(p, 2, b.process.tags)
+	p.WriteFieldStop()
+	p.WriteStructEnd()
+	p.WriteFieldEnd()
+
+	p.WriteFieldBegin("spans", thrift.LIST, 2)
+	p.WriteListBegin(thrift.STRUCT, len(b.spans))
+	for _, s := range b.spans {
+		p.WriteStructBegin("Span")
+		writeI64(p, 1, s.traceIDLow)
+		writeI64(p, 2, s.traceIDHigh)
+		writeI64(p, 3, s.spanID)
+		writeI64(p, 4, s.parentSpanID)
+		p.WriteFieldBegin("operationName", thrift.STRING, 5)
+		p.WriteString(s.operationName)
+		p.WriteFieldEnd()
+		p.WriteFieldBegin("references", thrift.LIST, 6)
+		p.WriteListBegin(thrift.STRUCT, len(s.references))
+		for _, r := range s.references {
+			p.WriteStructBegin("SpanRef")
+			p.WriteFieldBegin("refType", thrift.I32, 1)
+			p.WriteI32(r.refType)
+			p.WriteFieldEnd()
+			writeI64(p, 2, r.traceIDLow)
+			writeI64(p, 3, r.traceIDHigh)
+			writeI64(p, 4, r.spanID)
+			p.WriteFieldStop()
+			p.WriteStructEnd()
+		}
+		p.WriteListEnd()
+		p.WriteFieldEnd()
+		// flags is not used by the conversion and skipped
+		p.WriteFieldBegin("flags", thrift.I32, 7)
+		p.WriteI32(1)
+		p.WriteFieldEnd()
+		writeI64(p, 8, s.startTime)
+		writeI64(p, 9, s.duration)
+		writeTags(p, 10, s.tags)
+		p.WriteFieldBegin("logs", thrift.LIST, 11)
+		p.WriteListBegin(thrift.STRUCT, len(s.logs))
+		for _, l := range s.logs {
+			p.WriteStructBegin("Log")
+			writeI64(p, 1, l.timestamp)
+			writeTags(p, 2, l.fields)
+			p.WriteFieldStop()
+			p.WriteStructEnd()
+		}
+		p.WriteListEnd()
+		p.WriteFieldEnd()
+		p.WriteFieldStop()
+		p.WriteStructEnd()
+	}
+	p.WriteListEnd()
+	p.WriteFieldEnd()
+
+	p.WriteFieldStop()
+	p.WriteStructEnd()
+}
+
+func testBatch() *batch {
+	return &batch{
+		process: &process{
+			serviceName: "frontend",
+			tags: []*tag{
+				{key: "hostname", vType: tagString, vStr: "web-1"},
+				{key: "ip", vType: tagLong, vLong: 0x0a000001},
+			},
+		},
+		spans: []*jspan{
+			{
+				traceIDLow:    0x1234,
+				spanID:        0x1234,
+				operationName: "HTTP GET /",
+				startTime:     1588099396432691,
+				duration:      1500,
+				tags: []*tag{
+					{key: "http.status_code", vType: tagLong, vLong: 500},
+					{key: "error", vType: tagBool, vBool: true},
+				},
+				logs: []*spanLog{
+					{
+						timestamp: 1588099396432700,
+						fields: []*tag{
+							{key: "event", vType: tagString, vStr: "error"},
+							{key: "message", vType: tagString, vStr: "timeout"},
+						},
+					},
+					{
+						timestamp: 1588099396432800,
+						fields: []*tag{
+							{key: "retries", vType: tagLong, vLong: 3},
+							{key: "backoff", vType: tagDouble, vDouble: 1.5},
+						},
+					},
+				},
+			},
+			{
+				traceIDLow:  -1,
+				traceIDHigh: 0x1,
+				spanID:      0x5678,
+				references: []*spanRef{
+					{refType: refChildOf, traceIDLow: 0x1234, spanID: 0x1234},
+				},
+				operationName: "SELECT",
+				startTime:     1588099396432691,
+				duration:      1000,
+			},
+		},
+	}
+}
+
+func expectedTrace() trace.Trace {
+	ts := time.Unix(0, 1588099396432691000).UTC()
+	return trace.Trace{
+		{
+			ID:          "1234",
+			TraceID:     "1234",
+			Name:        "HTTP GET /",
+			ParentID:    "1234",
+			ServiceName: "frontend",
+			Timestamp:   ts,
+			Duration:    1500 * time.Microsecond,
+			Annotations: []trace.Annotation{
+				{
+					Timestamp:   time.Unix(0, 1588099396432700000).UTC(),
+					Value:       "error",
+					Host:        "10.0.0.1",
+					ServiceName: "frontend",
+				},
+				{
+					Timestamp:   time.Unix(0, 1588099396432800000).UTC(),
+					Value:       "retries=3 backoff=1.5",
+					Host:        "10.0.0.1",
+					ServiceName: "frontend",
+				},
+			},
+			BinaryAnnotations: []trace.BinaryAnnotation{
+				{Key: "http.status_code", Value: "500", Host: "10.0.0.1", ServiceName: "frontend"},
+				{Key: "error", Value: "true", Host: "10.0.0.1", ServiceName: "frontend"},
+			},
+		},
+		{
+			ID:                "5678",
+			TraceID:           "1ffffffffffffffff",
+			Name:              "SELECT",
+			ParentID:          "1234",
+			ServiceName:       "frontend",
+			Timestamp:         ts,
+			Duration:          time.Millisecond,
+			Annotations:       []trace.Annotation{},
+			BinaryAnnotations: []trace.BinaryAnnotation{},
+		},
+	}
+}
+
+func TestThrift(t *testing.T) {
+	buffer := thrift.NewTMemoryBuffer()
+	writeBatch(thrift.NewTBinaryProtocolTransport(buffer), testBatch())
+
+	spans, err := (&Thrift{}).Decode(buffer.Bytes())
+	require.NoError(t, err)
+
+	tr, err := codec.NewTrace(spans)
+	require.NoError(t, err)
+	require.Equal(t, expectedTrace(), tr)
+}
+
+func TestCompact(t *testing.T) {
+	buffer := thrift.NewTMemoryBuffer()
+	p := thrift.NewTCompactProtocol(buffer)
+	p.WriteMessageBegin("emitBatch", thrift.ONEWAY, 1)
+	p.WriteStructBegin("emitBatch_args")
+	p.WriteFieldBegin("batch", thrift.STRUCT, 1)
+	writeBatch(p, testBatch())
+	p.WriteFieldEnd()
+	p.WriteFieldStop()
+	p.WriteStructEnd()
+	p.WriteMessageEnd()
+	p.Flush(context.Background())
+
+	spans, err := (&Compact{}).Decode(buffer.Bytes())
+	require.NoError(t, err)
+
+	tr, err := codec.NewTrace(spans)
+	require.NoError(t, err)
+	require.Equal(t, expectedTrace(), tr)
+}
+
+func TestCompactInvalid(t *testing.T) {
+	buffer := thrift.NewTMemoryBuffer()
+	p := thrift.NewTCompactProtocol(buffer)
+	p.WriteMessageBegin("emitZipkinBatch", thrift.ONEWAY, 1)
+	p.WriteStructBegin("emitZipkinBatch_args")
+	p.WriteFieldStop()
+	p.WriteStructEnd()
+	p.WriteMessageEnd()
+	p.Flush(context.Background())
+
+	_, err := (&Compact{}).Decode(buffer.Bytes())
+	require.Error(t, err)
+
+	_, err = (&Compact{}).Decode([]byte{0x82, 0x21})
+	require.Error(t, err)
+
+	_, err = (&Thrift{}).Decode([]byte{0x0c, 0x00})
+	require.Error(t, err)
+}
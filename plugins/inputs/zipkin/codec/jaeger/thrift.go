@@ -0,0 +1,290 @@
+package jaeger
+
+import (
+	"fmt"
+
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+// The structures below mirror the ones of jaeger.thrift, only the fields
+// used for the conversion are kept.  Unknown fields are skipped, so newer
+// clients remain compatible.
+
+// Tag value types of jaeger.thrift
+const (
+	tagString = 0
+	tagDouble = 1
+	tagBool   = 2
+	tagLong   = 3
+	tagBinary = 4
+)
+
+// Span reference types of jaeger.thrift
+const (
+	refChildOf = 0
+)
+
+type batch struct {
+	process *process
+	spans   []*jspan
+}
+
+type process struct {
+	serviceName string
+	tags        []*tag
+}
+
+type tag struct {
+	key     string
+	vType   int32
+	vStr    string
+	vDouble float64
+	vBool   bool
+	vLong   int64
+	vBinary []byte
+}
+
+type spanLog struct {
+	timestamp int64
+	fields    []*tag
+}
+
+type spanRef struct {
+	refType     int32
+	traceIDLow  int64
+	traceIDHigh int64
+	spanID      int64
+}
+
+type jspan struct {
+	traceIDLow    int64
+	traceIDHigh   int64
+	spanID        int64
+	parentSpanID  int64
+	operationName string
+	references    []*spanRef
+	startTime     int64
+	duration      int64
+	tags          []*tag
+	logs          []*spanLog
+}
+
+// readStruct reads a struct calling fn for each field; fn returns false for
+// the fields it does not handle, these are skipped.
+func readStruct(p thrift.TProtocol, fn func(id int16, typ thrift.TType) (bool, error)) error {
+	if _, err := p.ReadStructBegin(); err != nil {
+		return err
+	}
+	for {
+		_, typ, id, err := p.ReadFieldBegin()
+		if err != nil {
+			return err
+		}
+		if typ == thrift.STOP {
+			break
+		}
+
+		handled, err := fn(id, typ)
+		if err != nil {
+			return fmt.Errorf("field %d: %v", id, err)
+		}
+		if !handled {
+			if err := p.Skip(typ); err != nil {
+				return err
+			}
+		}
+
+		if err := p.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	return p.ReadStructEnd()
+}
+
+// readList reads a list of structs calling fn for each element.
+func readList(p thrift.TProtocol, fn func() error) error {
+	elemType, size, err := p.ReadListBegin()
+	if err != nil {
+		return err
+	}
+	if elemType != thrift.STRUCT {
+		return fmt.Errorf("unexpected list element type %v", elemType)
+	}
+	for i := 0; i < size; i++ {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return p.ReadListEnd()
+}
+
+func readBatch(p thrift.TProtocol) (*batch, error) {
+	b := &batch{}
+	err := readStruct(p, func(id int16, typ thrift.TType) (bool, error) {
+		switch {
+		case id == 1 && typ == thrift.STRUCT:
+			proc, err := readProcess(p)
+			b.process = proc
+			return true, err
+		case id == 2 && typ == thrift.LIST:
+			return true, readList(p, func() error {
+				s, err := readSpan(p)
+				if err != nil {
+					return err
+				}
+				b.spans = append(b.spans, s)
+				return nil
+			})
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if b.process == nil {
+		return nil, fmt.Errorf("batch without process")
+	}
+	return b, nil
+}
+
+func readProcess(p thrift.TProtocol) (*process, error) {
+	proc := &process{}
+	err := readStruct(p, func(id int16, typ thrift.TType) (bool, error) {
+		var err error
+		switch {
+		case id == 1 && typ == thrift.STRING:
+			proc.serviceName, err = p.ReadString()
+			return true, err
+		case id == 2 && typ == thrift.LIST:
+			proc.tags, err = readTags(p)
+			return true, err
+		}
+		return false, nil
+	})
+	return proc, err
+}
+
+func readTags(p thrift.TProtocol) ([]*tag, error) {
+	var tags []*tag
+	err := readList(p, func() error {
+		t, err := readTag(p)
+		if err != nil {
+			return err
+		}
+		tags = append(tags, t)
+		return nil
+	})
+	return tags, err
+}
+
+func readTag(p thrift.TProtocol) (*tag, error) {
+	t := &tag{}
+	err := readStruct(p, func(id int16, typ thrift.TType) (bool, error) {
+		var err error
+		switch {
+		case id == 1 && typ == thrift.STRING:
+			t.key, err = p.ReadString()
+		case id == 2 && typ == thrift.I32:
+			t.vType, err = p.ReadI32()
+		case id == 3 && typ == thrift.STRING:
+			t.vStr, err = p.ReadString()
+		case id == 4 && typ == thrift.DOUBLE:
+			t.vDouble, err = p.ReadDouble()
+		case id == 5 && typ == thrift.BOOL:
+			t.vBool, err = p.ReadBool()
+		case id == 6 && typ == thrift.I64:
+			t.vLong, err = p.ReadI64()
+		case id == 7 && typ == thrift.STRING:
+			t.vBinary, err = p.ReadBinary()
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	return t, err
+}
+
+func readLog(p thrift.TProtocol) (*spanLog, error) {
+	l := &spanLog{}
+	err := readStruct(p, func(id int16, typ thrift.TType) (bool, error) {
+		var err error
+		switch {
+		case id == 1 && typ == thrift.I64:
+			l.timestamp, err = p.ReadI64()
+		case id == 2 && typ == thrift.LIST:
+			l.fields, err = readTags(p)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	return l, err
+}
+
+func readSpanRef(p thrift.TProtocol) (*spanRef, error) {
+	r := &spanRef{}
+	err := readStruct(p, func(id int16, typ thrift.TType) (bool, error) {
+		var err error
+		switch {
+		case id == 1 && typ == thrift.I32:
+			r.refType, err = p.ReadI32()
+		case id == 2 && typ == thrift.I64:
+			r.traceIDLow, err = p.ReadI64()
+		case id == 3 && typ == thrift.I64:
+			r.traceIDHigh, err = p.ReadI64()
+		case id == 4 && typ == thrift.I64:
+			r.spanID, err = p.ReadI64()
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	return r, err
+}
+
+func readSpan(p thrift.TProtocol) (*jspan, error) {
+	s := &jspan{}
+	err := readStruct(p, func(id int16, typ thrift.TType) (bool, error) {
+		var err error
+		switch {
+		case id == 1 && typ == thrift.I64:
+			s.traceIDLow, err = p.ReadI64()
+		case id == 2 && typ == thrift.I64:
+			s.traceIDHigh, err = p.ReadI64()
+		case id == 3 && typ == thrift.I64:
+			s.spanID, err = p.ReadI64()
+		case id == 4 && typ == thrift.I64:
+			s.parentSpanID, err = p.ReadI64()
+		case id == 5 && typ == thrift.STRING:
+			s.operationName, err = p.ReadString()
+		case id == 6 && typ == thrift.LIST:
+			err = readList(p, func() error {
+				r, err := readSpanRef(p)
+				if err != nil {
+					return err
+				}
+				s.references = append(s.references, r)
+				return nil
+			})
+		case id == 8 && typ == thrift.I64:
+			s.startTime, err = p.ReadI64()
+		case id == 9 && typ == thrift.I64:
+			s.duration, err = p.ReadI64()
+		case id == 10 && typ == thrift.LIST:
+			s.tags, err = readTags(p)
+		case id == 11 && typ == thrift.LIST:
+			err = readList(p, func() error {
+				l, err := readLog(p)
+				if err != nil {
+					return err
+				}
+				s.logs = append(s.logs, l)
+				return nil
+			})
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	return s, err
+}
@@ -69,18 +69,6 @@ func (s *SpanHandler) Register(router *mux.Router, recorder Recorder) error {
 // Spans handles zipkin thrift spans
 func (s *SpanHandler) Spans(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
-	body := r.Body
-	var err error
-	// Handle gzip decoding of the body
-	if r.Header.Get("Content-Encoding") == "gzip" {
-		body, err = gzip.NewReader(r.Body)
-		if err != nil {
-			s.recorder.Error(err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		defer body.Close()
-	}
 
 	decoder, err := ContentDecoder(r)
 	if err != nil {
@@ -88,7 +76,7 @@ func (s *SpanHandler) Spans(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusUnsupportedMediaType)
 	}
 
-	octets, err := ioutil.ReadAll(body)
+	octets, err := readBody(r)
 	if err != nil {
 		s.recorder.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -118,6 +106,20 @@ func (s *SpanHandler) Spans(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// readBody reads the body of the request, handling gzip decoding.
+func readBody(r *http.Request) ([]byte, error) {
+	body := r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		body = gz
+	}
+	return ioutil.ReadAll(body)
+}
+
 // ContentDecoer returns a Decoder that is able to produce Traces from bytes.
 // Failure should yield an HTTP 415 (`http.StatusUnsupportedMediaType`)
 // If a Content-Type is not set, zipkin assumes application/json
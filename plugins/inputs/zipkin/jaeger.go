@@ -0,0 +1,110 @@
+package zipkin
+
+import (
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/codec"
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/codec/jaeger"
+)
+
+// maxDatagramSize is the maximum size of the UDP packets of the Jaeger
+// clients.
+const maxDatagramSize = 65000
+
+// JaegerHandler is an implementation of a Handler which accepts Jaeger
+// batches in thrift binary format, like the HTTP endpoint of the Jaeger
+// collector, and sends them to the recorder
+type JaegerHandler struct {
+	Path     string
+	recorder Recorder
+}
+
+// NewJaegerHandler returns a new handler instance given path to handle
+func NewJaegerHandler(path string) *JaegerHandler {
+	return &JaegerHandler{
+		Path: path,
+	}
+}
+
+// Register accepts Jaeger thrift batches POSTed to the path of the mux router
+func (j *JaegerHandler) Register(router *mux.Router, recorder Recorder) error {
+	router.HandleFunc(j.Path, j.Batches).Methods("POST")
+	j.recorder = recorder
+	return nil
+}
+
+// Batches handles Jaeger thrift batches
+func (j *JaegerHandler) Batches(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	t, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (t != "application/x-thrift" && t != "application/vnd.apache.thrift.binary") {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	octets, err := readBody(r)
+	if err != nil {
+		j.recorder.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	spans, err := (&jaeger.Thrift{}).Decode(octets)
+	if err != nil {
+		j.recorder.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	trace, err := codec.NewTrace(spans)
+	if err != nil {
+		j.recorder.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err = j.recorder.Record(trace); err != nil {
+		j.recorder.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// listenJaegerUDP reads the emitBatch messages of the Jaeger clients, in
+// thrift compact format, until the connection is closed.
+func listenJaegerUDP(conn net.PacketConn, recorder Recorder) {
+	decoder := &jaeger.Compact{}
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if !strings.HasSuffix(err.Error(), ": use of closed network connection") {
+				recorder.Error(err)
+			}
+			return
+		}
+
+		spans, err := decoder.Decode(buf[:n])
+		if err != nil {
+			recorder.Error(err)
+			continue
+		}
+
+		trace, err := codec.NewTrace(spans)
+		if err != nil {
+			recorder.Error(err)
+			continue
+		}
+
+		if err := recorder.Record(trace); err != nil {
+			recorder.Error(err)
+		}
+	}
+}
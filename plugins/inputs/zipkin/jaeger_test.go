@@ -0,0 +1,169 @@
+package zipkin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// writeJaegerBatch writes a batch of a single span of the given service and
+// operation.
+func writeJaegerBatch(p thrift.TProtocol, service, operation string) {
+	p.WriteStructBegin("Batch")
+	p.WriteFieldBegin("process", thrift.STRUCT, 1)
+	p.WriteStructBegin("Process")
+	p.WriteFieldBegin("serviceName", thrift.STRING, 1)
+	p.WriteString(service)
+	p.WriteFieldEnd()
+	p.WriteFieldStop()
+	p.WriteStructEnd()
+	p.WriteFieldEnd()
+
+	p.WriteFieldBegin("spans", thrift.LIST, 2)
+	p.WriteListBegin(thrift.STRUCT, 1)
+	p.WriteStructBegin("Span")
+	for id, v := range map[int16]int64{1: 0x1234, 2: 0, 3: 0x1234, 4: 0, 8: 1588099396432691, 9: 1500} {
+		p.WriteFieldBegin("", thrift.I64, id)
+		p.WriteI64(v)
+		p.WriteFieldEnd()
+	}
+	p.WriteFieldBegin("operationName", thrift.STRING, 5)
+	p.WriteString(operation)
+	p.WriteFieldEnd()
+	p.WriteFieldStop()
+	p.WriteStructEnd()
+	p.WriteListEnd()
+	p.WriteFieldEnd()
+
+	p.WriteFieldStop()
+	p.WriteStructEnd()
+}
+
+func TestJaegerHTTP(t *testing.T) {
+	DefaultNetwork = "tcp4"
+
+	z := &Zipkin{
+		Log:            testutil.Logger{},
+		Path:           DefaultRoute,
+		JaegerHTTPPath: "/api/traces",
+	}
+	require.NoError(t, z.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, z.Start(&acc))
+	defer z.Stop()
+
+	buffer := thrift.NewTMemoryBuffer()
+	writeJaegerBatch(thrift.NewTBinaryProtocolTransport(buffer), "Frontend", "GET")
+
+	url := fmt.Sprintf("http://%s/api/traces", z.address)
+	resp, err := http.Post(url, "application/x-thrift", bytes.NewReader(buffer.Bytes()))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	resp, err = http.Post(url, "application/json", bytes.NewReader(buffer.Bytes()))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+
+	acc.Wait(1)
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"zipkin",
+			map[string]string{
+				"id":           "1234",
+				"parent_id":    "1234",
+				"trace_id":     "1234",
+				"name":         "get",
+				"service_name": "frontend",
+			},
+			map[string]interface{}{
+				"duration_ns": int64(1500 * time.Microsecond),
+			},
+			time.Unix(0, 1588099396432691000),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestJaegerUDPRED(t *testing.T) {
+	DefaultNetwork = "tcp4"
+
+	z := &Zipkin{
+		Log:                testutil.Logger{},
+		Path:               DefaultRoute,
+		Mode:               "red",
+		REDDurationBuckets: []float64{1, 10},
+		JaegerUDPAddress:   "127.0.0.1:0",
+	}
+	require.NoError(t, z.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, z.Start(&acc))
+	defer z.Stop()
+
+	buffer := thrift.NewTMemoryBuffer()
+	p := thrift.NewTCompactProtocol(buffer)
+	p.WriteMessageBegin("emitBatch", thrift.ONEWAY, 1)
+	p.WriteStructBegin("emitBatch_args")
+	p.WriteFieldBegin("batch", thrift.STRUCT, 1)
+	writeJaegerBatch(p, "frontend", "GET")
+	p.WriteFieldEnd()
+	p.WriteFieldStop()
+	p.WriteStructEnd()
+	p.WriteMessageEnd()
+	p.Flush(context.Background())
+
+	conn, err := net.Dial("udp", z.udpAddress)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write(buffer.Bytes())
+	require.NoError(t, err)
+
+	// Spans are aggregated until the next gather.
+	require.Eventually(t, func() bool {
+		acc.ClearMetrics()
+		require.NoError(t, z.Gather(&acc))
+		return acc.NMetrics() > 0
+	}, time.Second, 10*time.Millisecond)
+
+	tags := map[string]string{"service_name": "frontend", "name": "get", "status": "ok"}
+	bucketTags := func(le string) map[string]string {
+		t := map[string]string{"le": le}
+		for k, v := range tags {
+			t[k] = v
+		}
+		return t
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric("zipkin_red", tags, map[string]interface{}{
+			"requests":        int64(1),
+			"duration_sum_ns": int64(1500 * time.Microsecond),
+			"duration_min_ns": int64(1500 * time.Microsecond),
+			"duration_max_ns": int64(1500 * time.Microsecond),
+		}, time.Unix(0, 0)),
+		testutil.MustMetric("zipkin_red", bucketTags("1"), map[string]interface{}{"duration_bucket": int64(0)}, time.Unix(0, 0)),
+		testutil.MustMetric("zipkin_red", bucketTags("10"), map[string]interface{}{"duration_bucket": int64(1)}, time.Unix(0, 0)),
+		testutil.MustMetric("zipkin_red", bucketTags("+Inf"), map[string]interface{}{"duration_bucket": int64(1)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	require.Empty(t, acc.Errors)
+}
+
+func TestInitInvalid(t *testing.T) {
+	z := &Zipkin{Mode: "traces"}
+	require.Error(t, z.Init())
+
+	z = &Zipkin{Mode: "red", REDDurationBuckets: []float64{10, 1}}
+	require.Error(t, z.Init())
+}
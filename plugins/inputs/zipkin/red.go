@@ -0,0 +1,146 @@
+package zipkin
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/trace"
+)
+
+// DefaultDurationBuckets are the upper bounds, in milliseconds, of the
+// duration histogram of the red mode.
+var DefaultDurationBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+type redKey struct {
+	service string
+	name    string
+	status  string
+}
+
+type redSeries struct {
+	requests int64
+	sum      time.Duration
+	min      time.Duration
+	max      time.Duration
+	// counts holds the number of spans of each bucket, with the spans
+	// longer than the largest bound last.
+	counts []int64
+}
+
+// REDRecorder implements the Recorder interface; it aggregates the spans
+// into request rate, error and duration metrics per service, operation and
+// status, rather than storing every span.
+type REDRecorder struct {
+	acc     telegraf.Accumulator
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[redKey]*redSeries
+}
+
+// NewREDRecorder returns a REDRecorder with the given duration buckets in
+// milliseconds, which must be sorted.
+func NewREDRecorder(acc telegraf.Accumulator, buckets []float64) *REDRecorder {
+	return &REDRecorder{
+		acc:     acc,
+		buckets: buckets,
+		series:  make(map[redKey]*redSeries),
+	}
+}
+
+// Record adds the spans of the trace to the aggregation.
+func (r *REDRecorder) Record(t trace.Trace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range t {
+		key := redKey{
+			service: formatName(s.ServiceName),
+			name:    formatName(s.Name),
+			status:  spanStatus(s),
+		}
+		series, ok := r.series[key]
+		if !ok {
+			series = &redSeries{
+				min:    s.Duration,
+				counts: make([]int64, len(r.buckets)+1),
+			}
+			r.series[key] = series
+		}
+
+		series.requests++
+		series.sum += s.Duration
+		if s.Duration < series.min {
+			series.min = s.Duration
+		}
+		if s.Duration > series.max {
+			series.max = s.Duration
+		}
+
+		ms := float64(s.Duration) / float64(time.Millisecond)
+		series.counts[sort.SearchFloat64s(r.buckets, ms)]++
+	}
+	return nil
+}
+
+func (r *REDRecorder) Error(err error) {
+	r.acc.AddError(err)
+}
+
+// Flush adds the metrics of the spans recorded since the last flush to the
+// accumulator and resets the aggregation.
+func (r *REDRecorder) Flush(acc telegraf.Accumulator) {
+	r.mu.Lock()
+	series := r.series
+	r.series = make(map[redKey]*redSeries)
+	r.mu.Unlock()
+
+	now := time.Now()
+	for key, s := range series {
+		tags := map[string]string{
+			"service_name": key.service,
+			"name":         key.name,
+			"status":       key.status,
+		}
+		acc.AddFields("zipkin_red", map[string]interface{}{
+			"requests":        s.requests,
+			"duration_sum_ns": s.sum.Nanoseconds(),
+			"duration_min_ns": s.min.Nanoseconds(),
+			"duration_max_ns": s.max.Nanoseconds(),
+		}, tags, now)
+
+		// The buckets are cumulative like the ones of the histogram
+		// aggregator.
+		var count int64
+		for i, c := range s.counts {
+			count += c
+			le := "+Inf"
+			if i < len(r.buckets) {
+				le = strconv.FormatFloat(r.buckets[i], 'f', -1, 64)
+			}
+
+			bucketTags := make(map[string]string, len(tags)+1)
+			for k, v := range tags {
+				bucketTags[k] = v
+			}
+			bucketTags["le"] = le
+			acc.AddFields("zipkin_red", map[string]interface{}{
+				"duration_bucket": count,
+			}, bucketTags, now)
+		}
+	}
+}
+
+// spanStatus returns "error" for spans having an error tag, as set by both
+// Zipkin and Jaeger instrumentations, and "ok" otherwise.
+func spanStatus(s trace.Span) string {
+	for _, b := range s.BinaryAnnotations {
+		if b.Key == "error" && b.Value != "false" {
+			return "error"
+		}
+	}
+	return "ok"
+}
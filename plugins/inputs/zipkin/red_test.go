@@ -0,0 +1,62 @@
+package zipkin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs/zipkin/trace"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestREDRecorder(t *testing.T) {
+	var acc testutil.Accumulator
+	r := NewREDRecorder(&acc, []float64{10, 100})
+
+	err := r.Record(trace.Trace{
+		{ServiceName: "Frontend", Name: "GET", Duration: 5 * time.Millisecond},
+		{ServiceName: "frontend", Name: "GET", Duration: 50 * time.Millisecond},
+		{ServiceName: "frontend", Name: "GET", Duration: 2 * time.Second,
+			BinaryAnnotations: []trace.BinaryAnnotation{{Key: "error", Value: "timeout"}}},
+		{ServiceName: "frontend", Name: "GET", Duration: 10 * time.Millisecond,
+			BinaryAnnotations: []trace.BinaryAnnotation{{Key: "error", Value: "false"}}},
+	})
+	require.NoError(t, err)
+
+	r.Flush(&acc)
+
+	tags := func(status, le string) map[string]string {
+		tags := map[string]string{"service_name": "frontend", "name": "get", "status": status}
+		if le != "" {
+			tags["le"] = le
+		}
+		return tags
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric("zipkin_red", tags("ok", ""), map[string]interface{}{
+			"requests":        int64(3),
+			"duration_sum_ns": int64(65 * time.Millisecond),
+			"duration_min_ns": int64(5 * time.Millisecond),
+			"duration_max_ns": int64(50 * time.Millisecond),
+		}, time.Unix(0, 0)),
+		testutil.MustMetric("zipkin_red", tags("ok", "10"), map[string]interface{}{"duration_bucket": int64(2)}, time.Unix(0, 0)),
+		testutil.MustMetric("zipkin_red", tags("ok", "100"), map[string]interface{}{"duration_bucket": int64(3)}, time.Unix(0, 0)),
+		testutil.MustMetric("zipkin_red", tags("ok", "+Inf"), map[string]interface{}{"duration_bucket": int64(3)}, time.Unix(0, 0)),
+		testutil.MustMetric("zipkin_red", tags("error", ""), map[string]interface{}{
+			"requests":        int64(1),
+			"duration_sum_ns": int64(2 * time.Second),
+			"duration_min_ns": int64(2 * time.Second),
+			"duration_max_ns": int64(2 * time.Second),
+		}, time.Unix(0, 0)),
+		testutil.MustMetric("zipkin_red", tags("error", "10"), map[string]interface{}{"duration_bucket": int64(0)}, time.Unix(0, 0)),
+		testutil.MustMetric("zipkin_red", tags("error", "100"), map[string]interface{}{"duration_bucket": int64(0)}, time.Unix(0, 0)),
+		testutil.MustMetric("zipkin_red", tags("error", "+Inf"), map[string]interface{}{"duration_bucket": int64(1)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+
+	// The aggregation is reset on every flush.
+	acc.ClearMetrics()
+	r.Flush(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"sort"
 	"strconv"
 	"sync"
 
@@ -49,6 +50,24 @@ type Handler interface {
 const sampleConfig = `
   # path = "/api/v1/spans" # URL path for span data
   # port = 9411            # Port on which Telegraf listens
+
+  ## How spans are turned into metrics:
+  ##   spans - a metric for every span and annotation
+  ##   red   - request count, status and duration histogram per service,
+  ##           operation and status, emitted every interval
+  # mode = "spans"
+
+  ## Upper bounds of the duration histogram buckets of the red mode, in
+  ## milliseconds.
+  # red_duration_buckets_ms = [5.0, 10.0, 25.0, 50.0, 100.0, 250.0, 500.0, 1000.0, 2500.0, 5000.0, 10000.0]
+
+  ## URL path accepting Jaeger batches in thrift binary format, like the
+  ## HTTP endpoint of the Jaeger collector; served on the same port.
+  # jaeger_http_path = "/api/traces"
+
+  ## Address of the UDP listener accepting Jaeger batches in thrift compact
+  ## format, like the Jaeger agent.
+  # jaeger_udp_address = ":6831"
 `
 
 // Zipkin is a telegraf configuration structure for the zipkin input plugin,
@@ -59,12 +78,20 @@ type Zipkin struct {
 	Port           int
 	Path           string
 
+	Mode               string    `toml:"mode"`
+	REDDurationBuckets []float64 `toml:"red_duration_buckets_ms"`
+	JaegerHTTPPath     string    `toml:"jaeger_http_path"`
+	JaegerUDPAddress   string    `toml:"jaeger_udp_address"`
+
 	Log telegraf.Logger
 
-	address   string
-	handler   Handler
-	server    *http.Server
-	waitGroup *sync.WaitGroup
+	address    string
+	udpAddress string
+	handler    Handler
+	server     *http.Server
+	udpConn    net.PacketConn
+	red        *REDRecorder
+	waitGroup  *sync.WaitGroup
 }
 
 // Description is a necessary method implementation from telegraf.ServiceInput
@@ -77,9 +104,33 @@ func (z Zipkin) SampleConfig() string {
 	return sampleConfig
 }
 
-// Gather is empty for the zipkin plugin; all gathering is done through
-// the separate goroutine launched in (*Zipkin).Start()
-func (z *Zipkin) Gather(acc telegraf.Accumulator) error { return nil }
+// Init validates the configuration
+func (z *Zipkin) Init() error {
+	switch z.Mode {
+	case "":
+		z.Mode = "spans"
+	case "spans", "red":
+	default:
+		return fmt.Errorf("invalid mode %q", z.Mode)
+	}
+
+	if len(z.REDDurationBuckets) == 0 {
+		z.REDDurationBuckets = DefaultDurationBuckets
+	}
+	if !sort.Float64sAreSorted(z.REDDurationBuckets) {
+		return fmt.Errorf("red_duration_buckets_ms must be sorted")
+	}
+	return nil
+}
+
+// Gather adds the aggregated metrics in red mode; all gathering of spans is
+// done through the separate goroutines launched in (*Zipkin).Start()
+func (z *Zipkin) Gather(acc telegraf.Accumulator) error {
+	if z.red != nil {
+		z.red.Flush(acc)
+	}
+	return nil
+}
 
 // Start launches a separate goroutine for collecting zipkin client http requests,
 // passing in a telegraf.Accumulator such that data can be collected.
@@ -89,11 +140,23 @@ func (z *Zipkin) Start(acc telegraf.Accumulator) error {
 	var wg sync.WaitGroup
 	z.waitGroup = &wg
 
+	var recorder Recorder
+	if z.Mode == "red" {
+		z.red = NewREDRecorder(acc, z.REDDurationBuckets)
+		recorder = z.red
+	} else {
+		recorder = NewLineProtocolConverter(acc)
+	}
+
 	router := mux.NewRouter()
-	converter := NewLineProtocolConverter(acc)
-	if err := z.handler.Register(router, converter); err != nil {
+	if err := z.handler.Register(router, recorder); err != nil {
 		return err
 	}
+	if z.JaegerHTTPPath != "" {
+		if err := NewJaegerHandler(z.JaegerHTTPPath).Register(router, recorder); err != nil {
+			return err
+		}
+	}
 
 	z.server = &http.Server{
 		Handler: router,
@@ -108,13 +171,30 @@ func (z *Zipkin) Start(acc telegraf.Accumulator) error {
 	z.address = ln.Addr().String()
 	z.Log.Infof("Started the zipkin listener on %s", z.address)
 
+	wg.Add(1)
 	go func() {
-		wg.Add(1)
 		defer wg.Done()
 
 		z.Listen(ln, acc)
 	}()
 
+	if z.JaegerUDPAddress != "" {
+		conn, err := net.ListenPacket("udp", z.JaegerUDPAddress)
+		if err != nil {
+			z.server.Close()
+			return err
+		}
+		z.udpConn = conn
+		z.udpAddress = conn.LocalAddr().String()
+		z.Log.Infof("Started the jaeger UDP listener on %s", z.udpAddress)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			listenJaegerUDP(conn, recorder)
+		}()
+	}
+
 	return nil
 }
 
@@ -126,6 +206,9 @@ func (z *Zipkin) Stop() {
 	defer cancel()
 
 	z.server.Shutdown(ctx)
+	if z.udpConn != nil {
+		z.udpConn.Close()
+	}
 }
 
 // Listen creates an http server on the zipkin instance it is called with, and
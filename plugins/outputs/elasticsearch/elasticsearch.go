@@ -283,16 +283,39 @@ func (a *Elasticsearch) Write(metrics []telegraf.Metric) error {
 	}
 
 	if res.Errors {
-		for id, err := range res.Failed() {
-			log.Printf("E! Elasticsearch indexing failure, id: %d, error: %s, caused by: %s, %s", id, err.Error.Reason, err.Error.CausedBy["reason"], err.Error.CausedBy["type"])
-		}
-		return fmt.Errorf("W! Elasticsearch failed to index %d metrics", len(res.Failed()))
+		return bulkError(res)
 	}
 
 	return nil
 
 }
 
+// bulkError reports the metrics which failed to be indexed; items rejected
+// because of the document, such as mapping conflicts, are never retried.
+func bulkError(res *elastic.BulkResponse) error {
+	perr := &telegraf.PartialWriteError{}
+	for i, item := range res.Items {
+		for _, result := range item {
+			if result.Status >= 200 && result.Status <= 299 {
+				continue
+			}
+
+			if result.Error != nil {
+				log.Printf("E! Elasticsearch indexing failure, id: %d, error: %s, caused by: %s, %s", i, result.Error.Reason, result.Error.CausedBy["reason"], result.Error.CausedBy["type"])
+			}
+
+			if result.Status == http.StatusTooManyRequests || result.Status >= 500 {
+				perr.MetricsRetry = append(perr.MetricsRetry, i)
+			} else {
+				perr.MetricsReject = append(perr.MetricsReject, i)
+			}
+		}
+	}
+	perr.Err = fmt.Errorf("Elasticsearch failed to index %d metrics, %d will be retried",
+		len(perr.MetricsRetry)+len(perr.MetricsReject), len(perr.MetricsRetry))
+	return perr
+}
+
 func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 	if a.TemplateName == "" {
 		return fmt.Errorf("Elasticsearch template_name configuration not defined")
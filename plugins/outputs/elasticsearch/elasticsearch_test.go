@@ -6,9 +6,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/olivere/elastic.v5"
 )
 
 func TestConnectAndWrite(t *testing.T) {
@@ -257,3 +259,21 @@ func TestGetIndexName(t *testing.T) {
 		}
 	}
 }
+
+func TestBulkError(t *testing.T) {
+	res := &elastic.BulkResponse{
+		Errors: true,
+		Items: []map[string]*elastic.BulkResponseItem{
+			{"index": {Status: 201}},
+			{"index": {Status: 400, Error: &elastic.ErrorDetails{Reason: "failed to parse"}}},
+			{"index": {Status: 429, Error: &elastic.ErrorDetails{Reason: "rejected execution"}}},
+			{"index": {Status: 503}},
+		},
+	}
+
+	err := bulkError(res)
+	perr, ok := err.(*telegraf.PartialWriteError)
+	require.True(t, ok)
+	require.Equal(t, []int{1}, perr.MetricsReject)
+	require.Equal(t, []int{2, 3}, perr.MetricsRetry)
+}
@@ -108,6 +108,7 @@ func (f *File) Write(metrics []telegraf.Metric) error {
 		octets, err := f.serializer.SerializeBatch(metrics)
 		if err != nil {
 			f.Log.Errorf("Could not serialize metric: %v", err)
+			return nil
 		}
 
 		_, err = f.writer.Write(octets)
@@ -118,7 +119,8 @@ func (f *File) Write(metrics []telegraf.Metric) error {
 		for _, metric := range metrics {
 			b, err := f.serializer.Serialize(metric)
 			if err != nil {
-				f.Log.Debugf("Could not serialize metric: %v", err)
+				f.Log.Errorf("Could not serialize metric: %v", err)
+				continue
 			}
 
 			_, err = f.writer.Write(b)
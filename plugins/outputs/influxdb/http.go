@@ -11,6 +11,9 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -36,6 +39,9 @@ var (
 		`\`, `\\`,
 		`"`, `\"`,
 	)
+
+	// The number of points dropped by a partial write.
+	droppedRe = regexp.MustCompile(`dropped=(\d+)`)
 )
 
 // APIError is a general error reported by the InfluxDB server
@@ -57,6 +63,14 @@ type DatabaseNotFoundError struct {
 	Database string
 }
 
+// partialWriteError is returned when the server dropped some of the points of
+// a request, for example because of a field type conflict.
+type partialWriteError struct {
+	APIError
+	// Dropped is the number of points dropped, -1 if unknown.
+	Dropped int
+}
+
 // QueryResponse is the response body from the /query endpoint
 type QueryResponse struct {
 	Results []QueryResult `json:"results"`
@@ -263,7 +277,8 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 	}
 
 	batches := make(map[dbrp][]telegraf.Metric)
-	for _, metric := range metrics {
+	indexes := make(map[dbrp][]int)
+	for n, metric := range metrics {
 		db, ok := metric.GetTag(c.config.DatabaseTag)
 		if !ok {
 			db = c.config.Database
@@ -292,8 +307,13 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 		}
 
 		batches[dbrp] = append(batches[dbrp], metric)
+		indexes[dbrp] = append(indexes[dbrp], n)
 	}
 
+	// Each database and retention policy is written separately, so when a
+	// request fails only its metrics need to be dropped or retried.
+	var lastErr error
+	var reject, retry []int
 	for dbrp, batch := range batches {
 		if !c.config.SkipDatabaseCreation && !c.createDatabaseExecuted[dbrp.Database] {
 			err := c.CreateDatabase(ctx, dbrp.Database)
@@ -304,14 +324,134 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 		}
 
 		err := c.writeBatch(ctx, dbrp.Database, dbrp.RetentionPolicy, batch)
-		if err != nil {
+		if err == nil {
+			continue
+		}
+
+		lastErr = err
+		switch err := err.(type) {
+		case *telegraf.PartialWriteError:
+			lastErr = err.Err
+			reject = append(reject, selectIndexes(indexes[dbrp], err.MetricsReject)...)
+			retry = append(retry, selectIndexes(indexes[dbrp], err.MetricsRetry)...)
+		case *telegraf.PermanentError:
+			reject = append(reject, indexes[dbrp]...)
+		default:
+			retry = append(retry, indexes[dbrp]...)
+		}
+	}
+
+	return batchError(lastErr, len(metrics), reject, retry)
+}
+
+// batchError returns the error of a batch written in several requests: the
+// error itself when all metrics failed alike, otherwise a
+// telegraf.PartialWriteError.
+func batchError(err error, n int, reject, retry []int) error {
+	switch {
+	case err == nil, len(retry) == n:
+		return err
+	case len(reject) == n:
+		if _, ok := err.(*telegraf.PermanentError); ok {
 			return err
 		}
+		return &telegraf.PermanentError{Err: err}
+	}
+
+	sort.Ints(reject)
+	sort.Ints(retry)
+	return &telegraf.PartialWriteError{
+		Err:           err,
+		MetricsReject: reject,
+		MetricsRetry:  retry,
 	}
-	return nil
 }
 
+// selectIndexes returns the indexes at the given positions.
+func selectIndexes(indexes []int, positions []int) []int {
+	selected := make([]int, 0, len(positions))
+	for _, n := range positions {
+		if n >= 0 && n < len(indexes) {
+			selected = append(selected, indexes[n])
+		}
+	}
+	return selected
+}
+
+// writeBatch writes the metrics in a single request.  When the server drops
+// some of the points, the batch is split to find them and a
+// telegraf.PartialWriteError rejecting only their metrics is returned.
 func (c *httpClient) writeBatch(ctx context.Context, db, rp string, metrics []telegraf.Metric) error {
+	err := c.writeRequest(ctx, db, rp, metrics)
+	perr, ok := err.(*partialWriteError)
+	if !ok {
+		return err
+	}
+
+	result := &telegraf.PartialWriteError{Err: perr}
+	c.findDropped(ctx, db, rp, metrics, 0, perr.Dropped, result)
+	if len(result.MetricsReject) == 0 && len(result.MetricsRetry) == 0 {
+		return nil
+	}
+	return result
+}
+
+// findDropped searches partially written metrics for the dropped ones by
+// writing each half again, recording the index of every metric dropped or to
+// be retried, and returns the number of metrics found dropped.  Points written
+// twice overwrite themselves; once as many points as the server reported
+// dropped are found the rest are known to be written.
+func (c *httpClient) findDropped(
+	ctx context.Context,
+	db, rp string,
+	metrics []telegraf.Metric,
+	offset int,
+	dropped int,
+	result *telegraf.PartialWriteError,
+) int {
+	if len(metrics) == 1 {
+		result.MetricsReject = append(result.MetricsReject, offset)
+		return 1
+	}
+
+	var found int
+	var firstWritten bool
+	mid := len(metrics) / 2
+	for n, part := range [][]telegraf.Metric{metrics[:mid], metrics[mid:]} {
+		if dropped >= 0 && found >= dropped {
+			break
+		}
+
+		var err error
+		if n == 1 && firstWritten {
+			// The first half was written, so the dropped points are in the
+			// second half.
+			err = &partialWriteError{Dropped: dropped}
+		} else {
+			err = c.writeRequest(ctx, db, rp, part)
+			firstWritten = err == nil
+		}
+
+		switch err := err.(type) {
+		case nil:
+		case *partialWriteError:
+			found += c.findDropped(ctx, db, rp, part, offset, err.Dropped, result)
+		case *telegraf.PermanentError:
+			for i := range part {
+				result.MetricsReject = append(result.MetricsReject, offset+i)
+			}
+			found += len(part)
+		default:
+			for i := range part {
+				result.MetricsRetry = append(result.MetricsRetry, offset+i)
+			}
+		}
+		offset += len(part)
+	}
+	return found
+}
+
+func (c *httpClient) writeRequest(ctx context.Context, db, rp string, metrics []telegraf.Metric) error {
 	loc, err := makeWriteURL(c.config.URL, db, rp, c.config.Consistency)
 	if err != nil {
 		return err
@@ -374,21 +514,29 @@ func (c *httpClient) writeBatch(ctx context.Context, db, rp string, metrics []te
 		return nil
 	}
 
-	// Other partial write errors, such as "field type conflict", are not
-	// correctable at this point and so the point is dropped instead of
-	// retrying.
+	// Other partial write errors, such as "field type conflict", drop only
+	// the offending points, which are not correctable at this point.
 	if strings.Contains(desc, errStringPartialWrite) {
-		c.log.Errorf("When writing to [%s]: received error %v; discarding points",
-			c.URL(), desc)
-		return nil
+		return &partialWriteError{
+			APIError: APIError{
+				StatusCode:  resp.StatusCode,
+				Title:       resp.Status,
+				Description: desc,
+			},
+			Dropped: droppedPoints(desc),
+		}
 	}
 
 	// This error indicates a bug in either Telegraf line protocol
 	// serialization, retries would not be successful.
 	if strings.Contains(desc, errStringUnableToParse) {
-		c.log.Errorf("When writing to [%s]: received error %v; discarding points",
-			c.URL(), desc)
-		return nil
+		return &telegraf.PermanentError{
+			Err: &APIError{
+				StatusCode:  resp.StatusCode,
+				Title:       resp.Status,
+				Description: desc,
+			},
+		}
 	}
 
 	return &APIError{
@@ -398,6 +546,21 @@ func (c *httpClient) writeBatch(ctx context.Context, db, rp string, metrics []te
 	}
 }
 
+// droppedPoints returns the number of points dropped by a partial write, or
+// -1 if the error does not tell.
+func droppedPoints(desc string) int {
+	match := droppedRe.FindStringSubmatch(desc)
+	if match == nil {
+		return -1
+	}
+
+	n, err := strconv.Atoi(match[1])
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
 func (c *httpClient) makeQueryRequest(query string) (*http.Request, error) {
 	queryURL, err := makeQueryURL(c.config.URL)
 	if err != nil {
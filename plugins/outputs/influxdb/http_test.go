@@ -381,7 +381,7 @@ func TestHTTP_Write(t *testing.T) {
 			},
 		},
 		{
-			name: "partial write errors are logged no error",
+			name: "partial write errors reject the dropped metrics",
 			config: influxdb.HTTPConfig{
 				URL:      u,
 				Database: "telegraf",
@@ -391,12 +391,15 @@ func TestHTTP_Write(t *testing.T) {
 				w.WriteHeader(http.StatusBadRequest)
 				w.Write([]byte(`{"error": "partial write: field type conflict:"}`))
 			},
-			logFunc: func(t *testing.T, str string) {
-				require.Contains(t, str, "partial write")
+			errFunc: func(t *testing.T, err error) {
+				perr, ok := err.(*telegraf.PartialWriteError)
+				require.True(t, ok)
+				require.Equal(t, []int{0}, perr.MetricsReject)
+				require.Contains(t, err.Error(), "partial write")
 			},
 		},
 		{
-			name: "parse errors are logged no error",
+			name: "parse errors are permanent",
 			config: influxdb.HTTPConfig{
 				URL:      u,
 				Database: "telegraf",
@@ -406,8 +409,9 @@ func TestHTTP_Write(t *testing.T) {
 				w.WriteHeader(http.StatusBadRequest)
 				w.Write([]byte(`{"error": "unable to parse 'cpu value': invalid field format"}`))
 			},
-			logFunc: func(t *testing.T, str string) {
-				require.Contains(t, str, "unable to parse")
+			errFunc: func(t *testing.T, err error) {
+				require.IsType(t, &telegraf.PermanentError{}, err)
+				require.Contains(t, err.Error(), "unable to parse")
 			},
 		},
 		{
@@ -1135,3 +1139,108 @@ func TestDBRPTagsCreateDatabaseCalledOnDatabaseNotFound(t *testing.T) {
 
 	require.True(t, handlers.Done(), "all handlers not called")
 }
+
+func TestDBRPTagsPartialWrite(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.FormValue("db") {
+		case "foo":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(fmt.Sprintf("http://%s", ts.Listener.Addr().String()))
+	require.NoError(t, err)
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"database": "foo"},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"database": "bar"},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"database": "foo"},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0),
+		),
+	}
+
+	client, err := influxdb.NewHTTPClient(influxdb.HTTPConfig{
+		URL:                  u,
+		DatabaseTag:          "database",
+		SkipDatabaseCreation: true,
+		Log:                  testutil.Logger{},
+	})
+	require.NoError(t, err)
+
+	err = client.Write(context.Background(), metrics)
+	perr, ok := err.(*telegraf.PartialWriteError)
+	require.True(t, ok)
+	require.Equal(t, []int{1}, perr.MetricsRetry)
+	require.Empty(t, perr.MetricsReject)
+}
+
+func TestHTTP_WritePartialWriteFindsDroppedMetrics(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var dropped int
+		for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+			if strings.Contains(line, "value=\"") {
+				dropped++
+			}
+		}
+
+		if dropped == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"error": "partial write: field type conflict: input field \"value\" on measurement \"cpu\" is type string, already exists as type float dropped=%d"}`, dropped)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(fmt.Sprintf("http://%s", ts.Listener.Addr().String()))
+	require.NoError(t, err)
+
+	var metrics []telegraf.Metric
+	for i := 0; i < 8; i++ {
+		var value interface{} = 42.0
+		if i == 2 || i == 5 {
+			value = "42"
+		}
+		metrics = append(metrics, testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value": value},
+			time.Unix(int64(i), 0),
+		))
+	}
+
+	client, err := influxdb.NewHTTPClient(influxdb.HTTPConfig{
+		URL:      u,
+		Database: "telegraf",
+		Log:      testutil.Logger{},
+	})
+	require.NoError(t, err)
+
+	err = client.Write(context.Background(), metrics)
+	perr, ok := err.(*telegraf.PartialWriteError)
+	require.True(t, ok)
+	require.Equal(t, []int{2, 5}, perr.MetricsReject)
+	require.Empty(t, perr.MetricsRetry)
+	require.Less(t, requests, len(metrics))
+}
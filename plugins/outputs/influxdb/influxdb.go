@@ -13,6 +13,15 @@ import (
 	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+const (
+	writeModeFailover       = "failover"
+	writeModeAll            = "all"
+	writeModeConsistentHash = "consistent_hash"
+
+	defaultMaxPendingMetrics = 10000
 )
 
 var (
@@ -50,11 +59,20 @@ type InfluxDB struct {
 	ContentEncoding           string            `toml:"content_encoding"`
 	SkipDatabaseCreation      bool              `toml:"skip_database_creation"`
 	InfluxUintSupport         bool              `toml:"influx_uint_support"`
+	WriteMode                 string            `toml:"write_mode"`
+	WriteQuorum               int               `toml:"write_quorum"`
+	MaxPendingMetrics         int               `toml:"max_pending_metrics"`
 	tls.ClientConfig
 
 	Precision string // precision deprecated in 1.0; value is ignored
 
 	clients []Client
+	servers []*server
+	ring    *hashRing
+
+	// distributed holds the metrics of writes that returned an error, the
+	// servers already hold them either as written or as pending.
+	distributed *metricSet
 
 	CreateHTTPClientF func(config *HTTPConfig) (Client, error)
 	CreateUDPClientF  func(config *UDPConfig) (Client, error)
@@ -65,8 +83,8 @@ type InfluxDB struct {
 var sampleConfig = `
   ## The full HTTP or UDP URL for your InfluxDB instance.
   ##
-  ## Multiple URLs can be specified for a single cluster, only ONE of the
-  ## urls will be written to each interval.
+  ## Multiple URLs can be specified for a single cluster, by default only ONE
+  ## of the urls will be written to each interval; see write_mode.
   # urls = ["unix:///var/run/influxdb.sock"]
   # urls = ["udp://127.0.0.1:8089"]
   # urls = ["http://127.0.0.1:8086"]
@@ -138,6 +156,23 @@ var sampleConfig = `
   ## integer values.  Enabling this option will result in field type errors if
   ## existing data has been written.
   # influx_uint_support = false
+
+  ## How metrics are distributed when multiple urls are configured:
+  ##   failover:        write each batch to one url, trying the next url on
+  ##                    failure.
+  ##   all:             write each batch to every url.
+  ##   consistent_hash: shard the series across the urls by their series key.
+  ## In the "all" and "consistent_hash" modes metrics rejected by a server are
+  ## kept and retried against that server only.
+  # write_mode = "failover"
+
+  ## Number of servers that must accept a batch in the "all" write mode for
+  ## the write to succeed; 0 requires every server.
+  # write_quorum = 0
+
+  ## Maximum number of rejected metrics kept per server for retry in the "all"
+  ## and "consistent_hash" write modes; the oldest metrics are dropped first.
+  # max_pending_metrics = 10000
 `
 
 func (i *InfluxDB) Connect() error {
@@ -153,6 +188,22 @@ func (i *InfluxDB) Connect() error {
 		urls = append(urls, defaultURL)
 	}
 
+	switch i.WriteMode {
+	case "":
+		i.WriteMode = writeModeFailover
+	case writeModeFailover, writeModeAll, writeModeConsistentHash:
+	default:
+		return fmt.Errorf("unknown write_mode %q", i.WriteMode)
+	}
+
+	if i.WriteQuorum < 0 || i.WriteQuorum > len(urls) {
+		return fmt.Errorf("write_quorum must be between 0 and the number of urls")
+	}
+
+	if i.MaxPendingMetrics == 0 {
+		i.MaxPendingMetrics = defaultMaxPendingMetrics
+	}
+
 	for _, u := range urls {
 		parts, err := url.Parse(u)
 		if err != nil {
@@ -187,6 +238,25 @@ func (i *InfluxDB) Connect() error {
 		}
 	}
 
+	if i.WriteMode == writeModeFailover {
+		return nil
+	}
+
+	for n, c := range i.clients {
+		tags := map[string]string{"url": urls[n]}
+		i.servers = append(i.servers, &server{
+			client:        c,
+			writeErrors:   selfstat.Register("influxdb", "write_errors", tags),
+			pending:       selfstat.Register("influxdb", "pending_metrics", tags),
+			droppedWrites: selfstat.Register("influxdb", "dropped_metrics", tags),
+		})
+	}
+
+	if i.WriteMode == writeModeConsistentHash {
+		i.ring = newHashRing(urls)
+	}
+	i.distributed = newMetricSet(i.MaxPendingMetrics)
+
 	return nil
 }
 
@@ -205,9 +275,19 @@ func (i *InfluxDB) SampleConfig() string {
 	return sampleConfig
 }
 
-// Write sends metrics to one of the configured servers, logging each
-// unsuccessful. If all servers fail, return an error.
+// Write sends metrics to the configured servers according to the write mode.
 func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
+	switch i.WriteMode {
+	case writeModeAll, writeModeConsistentHash:
+		return i.writeDistributed(metrics)
+	default:
+		return i.writeFailover(metrics)
+	}
+}
+
+// writeFailover sends metrics to one of the configured servers, logging each
+// unsuccessful. If all servers fail, return an error.
+func (i *InfluxDB) writeFailover(metrics []telegraf.Metric) error {
 	ctx := context.Background()
 
 	var err error
@@ -219,23 +299,40 @@ func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
 			return nil
 		}
 
-		switch apiError := err.(type) {
-		case *DatabaseNotFoundError:
-			if !i.SkipDatabaseCreation {
-				err := client.CreateDatabase(ctx, apiError.Database)
-				if err != nil {
-					i.Log.Errorf("When writing to [%s]: database %q not found and failed to recreate",
-						client.URL(), apiError.Database)
-				}
-			}
+		// The rest of the batch was written, so it must not be sent to
+		// another server.
+		if perr, ok := err.(*telegraf.PartialWriteError); ok {
+			i.handleWriteError(ctx, client, perr.Err)
+			return perr
+		}
+
+		// No server would accept the batch either.
+		if _, ok := err.(*telegraf.PermanentError); ok {
+			i.handleWriteError(ctx, client, err)
+			return err
 		}
 
-		i.Log.Errorf("When writing to [%s]: %v", client.URL(), err)
+		i.handleWriteError(ctx, client, err)
 	}
 
 	return errors.New("could not write any address")
 }
 
+func (i *InfluxDB) handleWriteError(ctx context.Context, client Client, err error) {
+	switch apiError := err.(type) {
+	case *DatabaseNotFoundError:
+		if !i.SkipDatabaseCreation {
+			err := client.CreateDatabase(ctx, apiError.Database)
+			if err != nil {
+				i.Log.Errorf("When writing to [%s]: database %q not found and failed to recreate",
+					client.URL(), apiError.Database)
+			}
+		}
+	}
+
+	i.Log.Errorf("When writing to [%s]: %v", client.URL(), err)
+}
+
 func (i *InfluxDB) udpClient(url *url.URL) (Client, error) {
 	config := &UDPConfig{
 		URL:            url,
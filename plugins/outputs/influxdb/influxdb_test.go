@@ -2,6 +2,7 @@ package influxdb_test
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"testing"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/outputs/influxdb"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
@@ -215,3 +217,171 @@ func TestWriteRecreateDatabaseIfDatabaseNotFound(t *testing.T) {
 	// We only have one URL, so we expect an error
 	require.Error(t, err)
 }
+
+func newWriteModeOutput(mode string, quorum int, writeF map[string]func([]telegraf.Metric) error) *influxdb.InfluxDB {
+	output := &influxdb.InfluxDB{
+		URLs:                 []string{"http://a:8086", "http://b:8086", "http://c:8086"},
+		WriteMode:            mode,
+		WriteQuorum:          quorum,
+		SkipDatabaseCreation: true,
+		CreateHTTPClientF: func(config *influxdb.HTTPConfig) (influxdb.Client, error) {
+			url := config.URL.String()
+			return &MockClient{
+				URLF: func() string {
+					return url
+				},
+				WriteF: func(ctx context.Context, metrics []telegraf.Metric) error {
+					return writeF[url](metrics)
+				},
+			}, nil
+		},
+		Log: testutil.Logger{},
+	}
+	return output
+}
+
+func writeModeMetrics(names ...string) []telegraf.Metric {
+	var metrics []telegraf.Metric
+	for _, name := range names {
+		metrics = append(metrics, testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": name},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0),
+		))
+	}
+	return metrics
+}
+
+func TestWriteModeAll(t *testing.T) {
+	written := make(map[string][]telegraf.Metric)
+	failing := map[string]bool{"http://b:8086": true}
+	writeF := make(map[string]func([]telegraf.Metric) error)
+	for _, url := range []string{"http://a:8086", "http://b:8086", "http://c:8086"} {
+		url := url
+		writeF[url] = func(metrics []telegraf.Metric) error {
+			if failing[url] {
+				return errors.New("write failed")
+			}
+			written[url] = append(written[url], metrics...)
+			return nil
+		}
+	}
+
+	output := newWriteModeOutput("all", 0, writeF)
+	require.NoError(t, output.Connect())
+
+	batch := writeModeMetrics("a", "b")
+	require.Error(t, output.Write(batch))
+	require.Len(t, written["http://a:8086"], 2)
+	require.Len(t, written["http://c:8086"], 2)
+
+	// The batch is offered again after a new metric; only the server which
+	// rejected it receives the old metrics.
+	failing["http://b:8086"] = false
+	batch = append(writeModeMetrics("c"), batch...)
+	require.NoError(t, output.Write(batch))
+	require.Len(t, written["http://a:8086"], 3)
+	require.Len(t, written["http://b:8086"], 3)
+	require.Len(t, written["http://c:8086"], 3)
+}
+
+func TestWriteModeAllRunningOutput(t *testing.T) {
+	written := make(map[string][]telegraf.Metric)
+	failing := map[string]bool{"http://b:8086": true}
+	writeF := make(map[string]func([]telegraf.Metric) error)
+	for _, url := range []string{"http://a:8086", "http://b:8086", "http://c:8086"} {
+		url := url
+		writeF[url] = func(metrics []telegraf.Metric) error {
+			if failing[url] {
+				return errors.New("write failed")
+			}
+			written[url] = append(written[url], metrics...)
+			return nil
+		}
+	}
+
+	output := newWriteModeOutput("all", 0, writeF)
+	require.NoError(t, output.Connect())
+	ro := models.NewRunningOutput("influxdb", output, &models.OutputConfig{Name: "influxdb"}, 2, 100)
+
+	for _, m := range writeModeMetrics("a", "b") {
+		ro.AddMetric(m)
+	}
+	require.Error(t, ro.Write())
+
+	// The buffer returns the rejected metrics after the new ones, split over
+	// two batches.
+	failing["http://b:8086"] = false
+	for _, m := range writeModeMetrics("c", "d") {
+		ro.AddMetric(m)
+	}
+	require.NoError(t, ro.Write())
+	require.NoError(t, ro.Write())
+
+	for url, metrics := range written {
+		hosts := make(map[string]int)
+		for _, m := range metrics {
+			host, _ := m.GetTag("host")
+			hosts[host]++
+		}
+		require.Equal(t, map[string]int{"a": 1, "b": 1, "c": 1, "d": 1}, hosts, url)
+	}
+	require.Len(t, written, 3)
+}
+
+func TestWriteModeAllQuorum(t *testing.T) {
+	var retried []telegraf.Metric
+	failing := true
+	writeF := map[string]func([]telegraf.Metric) error{
+		"http://a:8086": func([]telegraf.Metric) error { return nil },
+		"http://b:8086": func([]telegraf.Metric) error { return nil },
+		"http://c:8086": func(metrics []telegraf.Metric) error {
+			if failing {
+				return errors.New("write failed")
+			}
+			retried = metrics
+			return nil
+		},
+	}
+
+	output := newWriteModeOutput("all", 2, writeF)
+	require.NoError(t, output.Connect())
+
+	require.NoError(t, output.Write(writeModeMetrics("a")))
+
+	failing = false
+	require.NoError(t, output.Write(writeModeMetrics("b")))
+	require.Len(t, retried, 2)
+}
+
+func TestWriteModeConsistentHash(t *testing.T) {
+	owners := make(map[string]string)
+	writeF := make(map[string]func([]telegraf.Metric) error)
+	for _, url := range []string{"http://a:8086", "http://b:8086", "http://c:8086"} {
+		url := url
+		writeF[url] = func(metrics []telegraf.Metric) error {
+			for _, m := range metrics {
+				host, _ := m.GetTag("host")
+				if owner, ok := owners[host]; ok {
+					require.Equal(t, owner, url)
+				}
+				owners[host] = url
+			}
+			return nil
+		}
+	}
+
+	output := newWriteModeOutput("consistent_hash", 0, writeF)
+	require.NoError(t, output.Connect())
+
+	hosts := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	require.NoError(t, output.Write(writeModeMetrics(hosts...)))
+	require.NoError(t, output.Write(writeModeMetrics(hosts...)))
+	require.Len(t, owners, len(hosts))
+}
+
+func TestWriteModeUnknown(t *testing.T) {
+	output := newWriteModeOutput("random", 0, nil)
+	require.Error(t, output.Connect())
+}
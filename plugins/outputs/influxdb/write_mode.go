@@ -0,0 +1,233 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// server tracks the metrics a client has rejected so that they are retried
+// against that client only.
+type server struct {
+	client  Client
+	backlog []telegraf.Metric
+
+	writeErrors   selfstat.Stat
+	pending       selfstat.Stat
+	droppedWrites selfstat.Stat
+}
+
+// writeDistributed writes the metrics assigned to each server along with the
+// metrics the server previously rejected.
+//
+// When the write fails, its metrics are remembered: every server has either
+// accepted them or holds them in its backlog, so when the same metrics are
+// offered again, in any order and batch, only the other metrics are
+// distributed.
+func (i *InfluxDB) writeDistributed(metrics []telegraf.Metric) error {
+	ctx := context.Background()
+
+	fresh := make([]telegraf.Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		if !i.distributed.contains(metric) {
+			fresh = append(fresh, metric)
+		}
+	}
+
+	var assigned [][]telegraf.Metric
+	if i.WriteMode == writeModeConsistentHash {
+		assigned = i.ring.partition(fresh)
+	}
+
+	var succeeded, failed int
+	for n, s := range i.servers {
+		batch := fresh
+		if assigned != nil {
+			batch = assigned[n]
+		}
+
+		if len(s.backlog) > 0 {
+			batch = append(s.backlog[:len(s.backlog):len(s.backlog)], batch...)
+		}
+
+		if len(batch) == 0 {
+			succeeded++
+			continue
+		}
+
+		err := s.client.Write(ctx, batch)
+		if perr, ok := err.(*telegraf.PartialWriteError); ok {
+			// Only the metrics to be retried are kept in the backlog.
+			s.writeErrors.Incr(1)
+			i.handleWriteError(ctx, s.client, perr.Err)
+			batch = selectMetrics(batch, perr.MetricsRetry)
+			if len(batch) == 0 {
+				err = nil
+			}
+		} else if _, ok := err.(*telegraf.PermanentError); ok {
+			// The batch is dropped instead of kept in the backlog.
+			s.writeErrors.Incr(1)
+			i.handleWriteError(ctx, s.client, err)
+			err = nil
+		} else if err != nil {
+			s.writeErrors.Incr(1)
+			i.handleWriteError(ctx, s.client, err)
+		}
+
+		if err == nil {
+			s.backlog = nil
+			s.pending.Set(0)
+			succeeded++
+			continue
+		}
+
+		failed++
+
+		if len(batch) > i.MaxPendingMetrics {
+			dropped := len(batch) - i.MaxPendingMetrics
+			s.droppedWrites.Incr(int64(dropped))
+			i.Log.Errorf("Dropped %d metrics pending for [%s]", dropped, s.client.URL())
+			batch = batch[dropped:]
+		}
+		s.backlog = batch
+		s.pending.Set(int64(len(batch)))
+	}
+
+	required := len(i.servers)
+	if i.WriteMode == writeModeAll && i.WriteQuorum > 0 {
+		required = i.WriteQuorum
+	}
+	if succeeded >= required {
+		for _, metric := range metrics {
+			i.distributed.remove(metric)
+		}
+		return nil
+	}
+
+	for _, metric := range metrics {
+		i.distributed.add(metric)
+	}
+	return fmt.Errorf("%d of %d servers failed to write", failed, len(i.servers))
+}
+
+// selectMetrics returns the metrics at the given indexes.
+func selectMetrics(metrics []telegraf.Metric, indexes []int) []telegraf.Metric {
+	selected := make([]telegraf.Metric, 0, len(indexes))
+	for _, n := range indexes {
+		if n >= 0 && n < len(metrics) {
+			selected = append(selected, metrics[n])
+		}
+	}
+	return selected
+}
+
+// metricSet is a set of metrics by identity.  Beyond its limit the metrics
+// added first are forgotten.
+type metricSet struct {
+	limit   int
+	members map[telegraf.Metric]bool
+	order   []telegraf.Metric
+}
+
+func newMetricSet(limit int) *metricSet {
+	return &metricSet{
+		limit:   limit,
+		members: make(map[telegraf.Metric]bool),
+	}
+}
+
+func (s *metricSet) contains(metric telegraf.Metric) bool {
+	return s.members[metric]
+}
+
+func (s *metricSet) add(metric telegraf.Metric) {
+	if s.members[metric] {
+		return
+	}
+	s.members[metric] = true
+	s.order = append(s.order, metric)
+
+	for len(s.members) > s.limit {
+		delete(s.members, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+func (s *metricSet) remove(metric telegraf.Metric) {
+	delete(s.members, metric)
+
+	// Drop the removed metrics from the order once they make up most of it.
+	if len(s.order) > 2*len(s.members)+s.limit {
+		order := make([]telegraf.Metric, 0, len(s.members))
+		for _, m := range s.order {
+			if s.members[m] {
+				order = append(order, m)
+			}
+		}
+		s.order = order
+	}
+}
+
+const hashRingReplicas = 128
+
+// hashRing assigns each series to a server by consistent hashing, so that
+// adding or removing a url only moves a fraction of the series.
+type hashRing struct {
+	points  []uint64
+	servers map[uint64]int
+	count   int
+}
+
+func newHashRing(urls []string) *hashRing {
+	r := &hashRing{
+		servers: make(map[uint64]int),
+		count:   len(urls),
+	}
+	for n, url := range urls {
+		for replica := 0; replica < hashRingReplicas; replica++ {
+			h := fnv.New64a()
+			h.Write([]byte(url))
+			h.Write([]byte{0})
+			h.Write([]byte(strconv.Itoa(replica)))
+			point := h.Sum64()
+			r.points = append(r.points, point)
+			r.servers[point] = n
+		}
+	}
+	sort.Slice(r.points, func(a, b int) bool { return r.points[a] < r.points[b] })
+	return r
+}
+
+// server returns the index of the server owning the series key.
+func (r *hashRing) server(key uint64) int {
+	n := sort.Search(len(r.points), func(n int) bool { return r.points[n] >= key })
+	if n == len(r.points) {
+		n = 0
+	}
+	return r.servers[r.points[n]]
+}
+
+// partition splits the metrics by owning server, keeping their order.
+func (r *hashRing) partition(metrics []telegraf.Metric) [][]telegraf.Metric {
+	parts := make([][]telegraf.Metric, r.count)
+	for _, m := range metrics {
+		n := r.server(mix(m.HashID()))
+		parts[n] = append(parts[n], m)
+	}
+	return parts
+}
+
+// mix spreads the series hash over the ring.
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
@@ -8,12 +8,14 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"net"
 	"net/http"
 	"net/url"
 	"path"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -34,12 +36,24 @@ func (e APIError) Error() string {
 	return e.Title
 }
 
+// partialWriteError is returned when the server dropped some of the points of
+// a request, for example because of a field type conflict.
+type partialWriteError struct {
+	APIError
+	// Dropped is the number of points dropped, -1 if unknown.
+	Dropped int
+}
+
 const (
 	defaultRequestTimeout = time.Second * 5
 	defaultMaxWait        = 10 // seconds
 	defaultDatabase       = "telegraf"
+	errStringPartialWrite = "partial write"
 )
 
+// The number of points dropped by a partial write.
+var droppedRe = regexp.MustCompile(`dropped=(\d+)`)
+
 type HTTPConfig struct {
 	URL              *url.URL
 	Token            string
@@ -173,13 +187,14 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 	}
 
 	batches := make(map[string][]telegraf.Metric)
+	indexes := make(map[string][]int)
 	if c.BucketTag == "" {
 		err := c.writeBatch(ctx, c.Bucket, metrics)
 		if err != nil {
 			return err
 		}
 	} else {
-		for _, metric := range metrics {
+		for n, metric := range metrics {
 			bucket, ok := metric.GetTag(c.BucketTag)
 			if !ok {
 				bucket = c.Bucket
@@ -197,19 +212,145 @@ func (c *httpClient) Write(ctx context.Context, metrics []telegraf.Metric) error
 			}
 
 			batches[bucket] = append(batches[bucket], metric)
+			indexes[bucket] = append(indexes[bucket], n)
 		}
 
+		// Each bucket is written separately, so when a request fails only
+		// its metrics need to be dropped or retried.
+		var lastErr error
+		var reject, retry []int
 		for bucket, batch := range batches {
 			err := c.writeBatch(ctx, bucket, batch)
-			if err != nil {
-				return err
+			if err == nil {
+				continue
+			}
+
+			lastErr = err
+			switch err := err.(type) {
+			case *telegraf.PartialWriteError:
+				lastErr = err.Err
+				reject = append(reject, selectIndexes(indexes[bucket], err.MetricsReject)...)
+				retry = append(retry, selectIndexes(indexes[bucket], err.MetricsRetry)...)
+			case *telegraf.PermanentError:
+				reject = append(reject, indexes[bucket]...)
+			default:
+				retry = append(retry, indexes[bucket]...)
 			}
 		}
+
+		return batchError(lastErr, len(metrics), reject, retry)
 	}
 	return nil
 }
 
+// batchError returns the error of a batch written in several requests: the
+// error itself when all metrics failed alike, otherwise a
+// telegraf.PartialWriteError.
+func batchError(err error, n int, reject, retry []int) error {
+	switch {
+	case err == nil, len(retry) == n:
+		return err
+	case len(reject) == n:
+		if _, ok := err.(*telegraf.PermanentError); ok {
+			return err
+		}
+		return &telegraf.PermanentError{Err: err}
+	}
+
+	sort.Ints(reject)
+	sort.Ints(retry)
+	return &telegraf.PartialWriteError{
+		Err:           err,
+		MetricsReject: reject,
+		MetricsRetry:  retry,
+	}
+}
+
+// selectIndexes returns the indexes at the given positions.
+func selectIndexes(indexes []int, positions []int) []int {
+	selected := make([]int, 0, len(positions))
+	for _, n := range positions {
+		if n >= 0 && n < len(indexes) {
+			selected = append(selected, indexes[n])
+		}
+	}
+	return selected
+}
+
+// writeBatch writes the metrics in a single request.  When the server drops
+// some of the points, the batch is split to find them and a
+// telegraf.PartialWriteError rejecting only their metrics is returned.
 func (c *httpClient) writeBatch(ctx context.Context, bucket string, metrics []telegraf.Metric) error {
+	err := c.writeRequest(ctx, bucket, metrics)
+	perr, ok := err.(*partialWriteError)
+	if !ok {
+		return err
+	}
+
+	result := &telegraf.PartialWriteError{Err: perr}
+	c.findDropped(ctx, bucket, metrics, 0, perr.Dropped, result)
+	if len(result.MetricsReject) == 0 && len(result.MetricsRetry) == 0 {
+		return nil
+	}
+	return result
+}
+
+// findDropped searches partially written metrics for the dropped ones by
+// writing each half again, recording the index of every metric dropped or to
+// be retried, and returns the number of metrics found dropped.  Points written
+// twice overwrite themselves; once as many points as the server reported
+// dropped are found the rest are known to be written.
+func (c *httpClient) findDropped(
+	ctx context.Context,
+	bucket string,
+	metrics []telegraf.Metric,
+	offset int,
+	dropped int,
+	result *telegraf.PartialWriteError,
+) int {
+	if len(metrics) == 1 {
+		result.MetricsReject = append(result.MetricsReject, offset)
+		return 1
+	}
+
+	var found int
+	var firstWritten bool
+	mid := len(metrics) / 2
+	for n, part := range [][]telegraf.Metric{metrics[:mid], metrics[mid:]} {
+		if dropped >= 0 && found >= dropped {
+			break
+		}
+
+		var err error
+		if n == 1 && firstWritten {
+			// The first half was written, so the dropped points are in the
+			// second half.
+			err = &partialWriteError{Dropped: dropped}
+		} else {
+			err = c.writeRequest(ctx, bucket, part)
+			firstWritten = err == nil
+		}
+
+		switch err := err.(type) {
+		case nil:
+		case *partialWriteError:
+			found += c.findDropped(ctx, bucket, part, offset, err.Dropped, result)
+		case *telegraf.PermanentError:
+			for i := range part {
+				result.MetricsReject = append(result.MetricsReject, offset+i)
+			}
+			found += len(part)
+		default:
+			for i := range part {
+				result.MetricsRetry = append(result.MetricsRetry, offset+i)
+			}
+		}
+		offset += len(part)
+	}
+	return found
+}
+
+func (c *httpClient) writeRequest(ctx context.Context, bucket string, metrics []telegraf.Metric) error {
 	loc, err := makeWriteURL(*c.url, c.Organization, bucket)
 	if err != nil {
 		return err
@@ -246,8 +387,23 @@ func (c *httpClient) writeBatch(ctx context.Context, bucket string, metrics []te
 
 	switch resp.StatusCode {
 	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
-		log.Printf("E! [outputs.influxdb_v2] Failed to write metric: %s\n", desc)
-		return nil
+		// Partial writes, such as on a field type conflict, drop only the
+		// offending points.
+		if resp.StatusCode == http.StatusBadRequest && strings.Contains(desc, errStringPartialWrite) {
+			return &partialWriteError{
+				APIError: APIError{
+					StatusCode:  resp.StatusCode,
+					Title:       resp.Status,
+					Description: desc,
+				},
+				Dropped: droppedPoints(desc),
+			}
+		}
+
+		// Retrying the same batch would fail again.
+		return &telegraf.PermanentError{
+			Err: fmt.Errorf("failed to write metric: %s", desc),
+		}
 	case http.StatusUnauthorized, http.StatusForbidden:
 		return fmt.Errorf("failed to write metric: %s", desc)
 	case http.StatusTooManyRequests:
@@ -287,6 +443,21 @@ func (c *httpClient) writeBatch(ctx context.Context, bucket string, metrics []te
 	}
 }
 
+// droppedPoints returns the number of points dropped by a partial write, or
+// -1 if the error does not tell.
+func droppedPoints(desc string) int {
+	match := droppedRe.FindStringSubmatch(desc)
+	if match == nil {
+		return -1
+	}
+
+	n, err := strconv.Atoi(match[1])
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
 func (c *httpClient) makeWriteRequest(url string, body io.Reader) (*http.Request, error) {
 	var err error
 
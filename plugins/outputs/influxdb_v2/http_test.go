@@ -2,10 +2,12 @@ package influxdb_v2_test
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -111,3 +113,143 @@ func TestWriteBucketTagWorksOnRetry(t *testing.T) {
 	err = client.Write(ctx, metrics)
 	require.NoError(t, err)
 }
+
+func TestWriteBucketTagPartialWrite(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			if r.Form.Get("bucket") == "foo" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+		}),
+	)
+	defer ts.Close()
+
+	addr := &url.URL{
+		Scheme: "http",
+		Host:   ts.Listener.Addr().String(),
+	}
+
+	config := &influxdb.HTTPConfig{
+		URL:       addr,
+		Bucket:    "telegraf",
+		BucketTag: "bucket",
+	}
+
+	client, err := influxdb.NewHTTPClient(config)
+	require.NoError(t, err)
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"bucket": "bar"},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"bucket": "foo"},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0),
+		),
+	}
+
+	err = client.Write(context.Background(), metrics)
+	perr, ok := err.(*telegraf.PartialWriteError)
+	require.True(t, ok)
+	require.Equal(t, []int{0}, perr.MetricsRetry)
+}
+
+func TestWriteBadRequestIsPermanent(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code": "invalid", "message": "unable to parse 'cpu value': invalid field format"}`))
+		}),
+	)
+	defer ts.Close()
+
+	addr := &url.URL{
+		Scheme: "http",
+		Host:   ts.Listener.Addr().String(),
+	}
+
+	config := &influxdb.HTTPConfig{
+		URL:    addr,
+		Bucket: "telegraf",
+	}
+
+	client, err := influxdb.NewHTTPClient(config)
+	require.NoError(t, err)
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0),
+		),
+	}
+
+	err = client.Write(context.Background(), metrics)
+	require.IsType(t, &telegraf.PermanentError{}, err)
+}
+
+func TestWritePartialWriteFindsDroppedMetrics(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			var dropped int
+			for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+				if strings.Contains(line, `value="`) {
+					dropped++
+				}
+			}
+
+			if dropped == 0 {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"code": "invalid", "message": "partial write: field type conflict: input field \"value\" on measurement \"cpu\" is type string, already exists as type float dropped=%d"}`, dropped)
+		}),
+	)
+	defer ts.Close()
+
+	addr := &url.URL{
+		Scheme: "http",
+		Host:   ts.Listener.Addr().String(),
+	}
+
+	config := &influxdb.HTTPConfig{
+		URL:    addr,
+		Bucket: "telegraf",
+	}
+
+	client, err := influxdb.NewHTTPClient(config)
+	require.NoError(t, err)
+
+	var metrics []telegraf.Metric
+	for i := 0; i < 5; i++ {
+		var value interface{} = 42.0
+		if i == 3 {
+			value = "42"
+		}
+		metrics = append(metrics, testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value": value},
+			time.Unix(int64(i), 0),
+		))
+	}
+
+	err = client.Write(context.Background(), metrics)
+	perr, ok := err.(*telegraf.PartialWriteError)
+	require.True(t, ok)
+	require.Equal(t, []int{3}, perr.MetricsReject)
+	require.Empty(t, perr.MetricsRetry)
+}
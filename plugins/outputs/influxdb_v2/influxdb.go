@@ -166,6 +166,13 @@ func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
 		}
 
 		log.Printf("E! [outputs.influxdb_v2] when writing to [%s]: %v", client.URL(), err)
+
+		// The rest of the batch was written, or no server would accept it,
+		// so it must not be sent to another server.
+		switch err.(type) {
+		case *telegraf.PartialWriteError, *telegraf.PermanentError:
+			return err
+		}
 	}
 
 	return err
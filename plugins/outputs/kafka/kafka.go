@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/Shopify/sarama"
@@ -12,6 +13,7 @@ import (
 	"github.com/influxdata/telegraf"
 	tlsint "github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/common/kafka"
+	common "github.com/influxdata/telegraf/plugins/common/template"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
@@ -34,6 +36,10 @@ type (
 		TopicSuffix      TopicSuffix `toml:"topic_suffix"`
 		RoutingTag       string      `toml:"routing_tag"`
 		RoutingKey       string      `toml:"routing_key"`
+		RoutingTemplate  string      `toml:"routing_key_template"`
+		PartitionTags    []string    `toml:"partition_tags"`
+		HeaderTags       []string    `toml:"header_tags"`
+		IdempotentWrites bool        `toml:"idempotent_writes"`
 		CompressionCodec int         `toml:"compression_codec"`
 		RequiredAcks     int         `toml:"required_acks"`
 		MaxRetry         int         `toml:"max_retry"`
@@ -63,6 +69,8 @@ type (
 		producerFunc func(addrs []string, config *sarama.Config) (sarama.SyncProducer, error)
 		producer     sarama.SyncProducer
 
+		routingTemplate *template.Template
+
 		serializer serializers.Serializer
 	}
 	TopicSuffix struct {
@@ -160,6 +168,24 @@ var sampleConfig = `
   ##       routing_key = "telegraf"
   # routing_key = ""
 
+  ## Go template used to create the message key, it is used instead of the
+  ## routing_tag and routing_key options.  In order to ease TOML escaping
+  ## requirements, you may wish to use single quotes around the template.
+  ##   ex: routing_key_template = '{{ .Name }}.{{ .Tag "host" }}'
+  # routing_key_template = ""
+
+  ## Tag keys whose values select the partition of the message, metrics of
+  ## the same series are written in order to the same partition.  The message
+  ## key is not used for partitioning when set.
+  # partition_tags = []
+
+  ## Tag keys to add as message headers, requires version 0.11.0.0 or later.
+  # header_tags = []
+
+  ## Enable the idempotent producer so that retries do not write duplicate
+  ## messages.  Requires version 0.11.0.0 or later and required_acks = -1.
+  # idempotent_writes = false
+
   ## CompressionCodec represents the various compression codecs recognized by
   ## Kafka in messages.
   ##  0 : No compression
@@ -284,6 +310,31 @@ func (k *Kafka) Connect() error {
 		config.ClientID = "Telegraf"
 	}
 
+	if k.IdempotentWrites || len(k.HeaderTags) > 0 {
+		if k.Version == "" {
+			config.Version = sarama.V0_11_0_0
+		} else if !config.Version.IsAtLeast(sarama.V0_11_0_0) {
+			return fmt.Errorf("idempotent_writes and header_tags require version 0.11.0.0 or later")
+		}
+	}
+
+	if k.RoutingTemplate != "" {
+		tmpl, err := template.New("routing_key_template").Parse(k.RoutingTemplate)
+		if err != nil {
+			return fmt.Errorf("could not parse routing_key_template: %v", err)
+		}
+		k.routingTemplate = tmpl
+	}
+
+	if len(k.PartitionTags) > 0 {
+		config.Producer.Partitioner = newTagPartitioner
+	}
+
+	if k.IdempotentWrites {
+		config.Producer.Idempotent = true
+		config.Net.MaxOpenRequests = 1
+	}
+
 	config.Producer.RequiredAcks = sarama.RequiredAcks(k.RequiredAcks)
 	config.Producer.Compression = sarama.CompressionCodec(k.CompressionCodec)
 	config.Producer.Retry.Max = k.MaxRetry
@@ -353,6 +404,15 @@ func (k *Kafka) Description() string {
 }
 
 func (k *Kafka) routingKey(metric telegraf.Metric) (string, error) {
+	if k.routingTemplate != nil {
+		var b strings.Builder
+		err := k.routingTemplate.Execute(&b, common.NewTemplateMetric(metric))
+		if err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+
 	if k.RoutingTag != "" {
 		key, ok := metric.GetTag(k.RoutingTag)
 		if ok {
@@ -371,9 +431,57 @@ func (k *Kafka) routingKey(metric telegraf.Metric) (string, error) {
 	return k.RoutingKey, nil
 }
 
+// partitionKey is the message metadata selecting the partition when
+// partition_tags is set.
+type partitionKey string
+
+// tagPartitioner hashes the partition key of the message in the same way the
+// default partitioner hashes the message key.
+type tagPartitioner struct {
+	hash sarama.Partitioner
+}
+
+func newTagPartitioner(topic string) sarama.Partitioner {
+	return &tagPartitioner{hash: sarama.NewHashPartitioner(topic)}
+}
+
+func (p *tagPartitioner) Partition(msg *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	if key, ok := msg.Metadata.(partitionKey); ok {
+		msg = &sarama.ProducerMessage{Key: sarama.StringEncoder(key)}
+	}
+	return p.hash.Partition(msg, numPartitions)
+}
+
+func (p *tagPartitioner) RequiresConsistency() bool {
+	return true
+}
+
+func (k *Kafka) partitionKey(metric telegraf.Metric) partitionKey {
+	values := make([]string, 0, len(k.PartitionTags))
+	for _, key := range k.PartitionTags {
+		value, _ := metric.GetTag(key)
+		values = append(values, value)
+	}
+	return partitionKey(strings.Join(values, "\x00"))
+}
+
+func (k *Kafka) headers(metric telegraf.Metric) []sarama.RecordHeader {
+	headers := make([]sarama.RecordHeader, 0, len(k.HeaderTags))
+	for _, key := range k.HeaderTags {
+		if value, ok := metric.GetTag(key); ok {
+			headers = append(headers, sarama.RecordHeader{
+				Key:   []byte(key),
+				Value: []byte(value),
+			})
+		}
+	}
+	return headers
+}
+
 func (k *Kafka) Write(metrics []telegraf.Metric) error {
 	msgs := make([]*sarama.ProducerMessage, 0, len(metrics))
-	for _, metric := range metrics {
+	indexes := make(map[*sarama.ProducerMessage]int, len(metrics))
+	for n, metric := range metrics {
 		metric, topic := k.GetTopicName(metric)
 
 		buf, err := k.serializer.Serialize(metric)
@@ -400,24 +508,23 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 		if key != "" {
 			m.Key = sarama.StringEncoder(key)
 		}
+
+		if len(k.PartitionTags) > 0 {
+			m.Metadata = k.partitionKey(metric)
+		}
+
+		if len(k.HeaderTags) > 0 {
+			m.Headers = k.headers(metric)
+		}
+
 		msgs = append(msgs, m)
+		indexes[m] = n
 	}
 
 	err := k.producer.SendMessages(msgs)
 	if err != nil {
-		// We could have many errors, return only the first encountered.
 		if errs, ok := err.(sarama.ProducerErrors); ok {
-			for _, prodErr := range errs {
-				if prodErr.Err == sarama.ErrMessageSizeTooLarge {
-					k.Log.Error("Message too large, consider increasing `max_message_bytes`; dropping batch")
-					return nil
-				}
-				if prodErr.Err == sarama.ErrInvalidTimestamp {
-					k.Log.Error("The timestamp of the message is out of acceptable range, consider increasing broker `message.timestamp.difference.max.ms`; dropping batch")
-					return nil
-				}
-				return prodErr
-			}
+			return k.producerErrors(errs, indexes)
 		}
 		return err
 	}
@@ -425,6 +532,40 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
+// producerErrors reports the messages which failed to be sent; messages
+// rejected by the broker because of their content are dropped, the others
+// are retried.
+func (k *Kafka) producerErrors(errs sarama.ProducerErrors, indexes map[*sarama.ProducerMessage]int) error {
+	perr := &telegraf.PartialWriteError{}
+	for _, prodErr := range errs {
+		n, ok := indexes[prodErr.Msg]
+		if !ok {
+			continue
+		}
+
+		switch prodErr.Err {
+		case sarama.ErrMessageSizeTooLarge:
+			k.Log.Error("Message too large, consider increasing `max_message_bytes`; dropping metric")
+			perr.MetricsReject = append(perr.MetricsReject, n)
+		case sarama.ErrInvalidTimestamp:
+			k.Log.Error("The timestamp of the message is out of acceptable range, consider increasing broker `message.timestamp.difference.max.ms`; dropping metric")
+			perr.MetricsReject = append(perr.MetricsReject, n)
+		default:
+			perr.MetricsRetry = append(perr.MetricsRetry, n)
+		}
+
+		// We could have many errors, report only the first encountered.
+		if perr.Err == nil {
+			perr.Err = prodErr
+		}
+	}
+
+	if perr.Err == nil {
+		return errs
+	}
+	return perr
+}
+
 func init() {
 	sarama.Logger = &DebugLogger{}
 	outputs.Add("kafka", func() telegraf.Output {
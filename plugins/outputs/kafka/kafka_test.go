@@ -1,7 +1,9 @@
 package kafka
 
 import (
+	"errors"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/Shopify/sarama"
@@ -148,6 +150,30 @@ func TestRoutingKey(t *testing.T) {
 				require.Equal(t, 36, len(routingKey))
 			},
 		},
+		{
+			name: "routing key template",
+			kafka: &Kafka{
+				RoutingTag: "host",
+				routingTemplate: template.Must(
+					template.New("routing_key_template").Parse(`{{ .Name }}.{{ .Tag "host" }}`)),
+			},
+			metric: func() telegraf.Metric {
+				m, _ := metric.New(
+					"cpu",
+					map[string]string{
+						"host": "server01",
+					},
+					map[string]interface{}{
+						"value": 42.0,
+					},
+					time.Unix(0, 0),
+				)
+				return m
+			}(),
+			check: func(t *testing.T, routingKey string) {
+				require.Equal(t, "cpu.server01", routingKey)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -300,3 +326,131 @@ func TestTopicTag(t *testing.T) {
 		})
 	}
 }
+
+func TestPartitionTags(t *testing.T) {
+	s, err := serializers.NewInfluxSerializer()
+	require.NoError(t, err)
+
+	plugin := &Kafka{
+		Brokers:       []string{"127.0.0.1"},
+		Topic:         "telegraf",
+		RoutingKey:    "random",
+		PartitionTags: []string{"host"},
+		HeaderTags:    []string{"host", "region"},
+		producerFunc:  NewMockProducer,
+	}
+	plugin.SetSerializer(s)
+	require.NoError(t, plugin.Connect())
+
+	producer := &MockProducer{}
+	plugin.producer = producer
+
+	var input []telegraf.Metric
+	for i := 0; i < 10; i++ {
+		input = append(input, testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "server01"},
+			map[string]interface{}{"value": float64(i)},
+			time.Unix(0, 0),
+		))
+	}
+	require.NoError(t, plugin.Write(input))
+	require.Len(t, producer.sent, 10)
+
+	partitioner := newTagPartitioner("telegraf")
+	require.True(t, partitioner.RequiresConsistency())
+	expected, err := partitioner.Partition(producer.sent[0], 16)
+	require.NoError(t, err)
+	for _, msg := range producer.sent {
+		partition, err := partitioner.Partition(msg, 16)
+		require.NoError(t, err)
+		require.Equal(t, expected, partition)
+
+		require.Equal(t, []sarama.RecordHeader{
+			{Key: []byte("host"), Value: []byte("server01")},
+		}, msg.Headers)
+	}
+}
+
+func TestVersionRequired(t *testing.T) {
+	plugin := &Kafka{
+		Brokers:          []string{"127.0.0.1"},
+		Topic:            "telegraf",
+		Version:          "0.10.2.0",
+		IdempotentWrites: true,
+		producerFunc:     NewMockProducer,
+	}
+	require.Error(t, plugin.Connect())
+
+	var config *sarama.Config
+	plugin = &Kafka{
+		Brokers:          []string{"127.0.0.1"},
+		Topic:            "telegraf",
+		RequiredAcks:     -1,
+		MaxRetry:         3,
+		IdempotentWrites: true,
+		producerFunc: func(addrs []string, c *sarama.Config) (sarama.SyncProducer, error) {
+			config = c
+			return &MockProducer{}, nil
+		},
+	}
+	require.NoError(t, plugin.Connect())
+	require.True(t, config.Producer.Idempotent)
+	require.Equal(t, sarama.V0_11_0_0, config.Version)
+	require.NoError(t, config.Validate())
+}
+
+type FailingProducer struct {
+	MockProducer
+	failures map[int]error
+}
+
+func (p *FailingProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	var errs sarama.ProducerErrors
+	for i, msg := range msgs {
+		if err, ok := p.failures[i]; ok {
+			errs = append(errs, &sarama.ProducerError{Msg: msg, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func TestProducerErrors(t *testing.T) {
+	s, err := serializers.NewInfluxSerializer()
+	require.NoError(t, err)
+
+	plugin := &Kafka{
+		Brokers:      []string{"127.0.0.1"},
+		Topic:        "telegraf",
+		producerFunc: NewMockProducer,
+		Log:          testutil.Logger{},
+	}
+	plugin.SetSerializer(s)
+	require.NoError(t, plugin.Connect())
+
+	plugin.producer = &FailingProducer{
+		failures: map[int]error{
+			1: sarama.ErrMessageSizeTooLarge,
+			2: errors.New("leader not available"),
+		},
+	}
+
+	var input []telegraf.Metric
+	for i := 0; i < 3; i++ {
+		input = append(input, testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value": float64(i)},
+			time.Unix(0, 0),
+		))
+	}
+
+	err = plugin.Write(input)
+	perr, ok := err.(*telegraf.PartialWriteError)
+	require.True(t, ok)
+	require.Equal(t, []int{1}, perr.MetricsReject)
+	require.Equal(t, []int{2}, perr.MetricsRetry)
+}
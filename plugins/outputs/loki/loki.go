@@ -0,0 +1,331 @@
+package loki
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+const (
+	defaultURL     = "http://localhost:3100/loki/api/v1/push"
+	defaultTimeout = 5 * time.Second
+
+	// streamExpiry is how long the last timestamp of an idle stream is kept
+	// for the out of order check.
+	streamExpiry = time.Hour
+)
+
+var sampleConfig = `
+  ## URL of the Loki push API.
+  # url = "http://localhost:3100/loki/api/v1/push"
+
+  ## Timeout for HTTP requests.
+  # timeout = "5s"
+
+  ## Encoding of the push request, "json" or "protobuf".  Protobuf requests
+  ## are snappy compressed.
+  # format = "json"
+
+  ## Field sent as the log line.  Metrics without the field are sent with all
+  ## their fields formatted as logfmt.
+  # line_field = "message"
+
+  ## Label set to the metric name, set to empty to omit the label.  All tags
+  ## are sent as labels.
+  # name_label = "measurement"
+
+  ## Handling of entries older than the last entry sent to their stream:
+  ##   adjust: send the entry with the timestamp of the last entry.
+  ##   drop:   discard the entry.
+  # out_of_order = "adjust"
+
+  ## HTTP Basic Auth credentials.
+  # username = "username"
+  # password = "pa$$word"
+
+  ## Bearer token sent in the Authorization header.
+  # bearer_token = ""
+
+  ## Tenant ID sent in the X-Scope-OrgID header for multi-tenant Loki.
+  # tenant_id = ""
+
+  ## Additional HTTP headers.
+  # [outputs.loki.headers]
+  #   X-Custom-Header = "value"
+
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
+`
+
+type Loki struct {
+	URL         string            `toml:"url"`
+	Timeout     internal.Duration `toml:"timeout"`
+	Format      string            `toml:"format"`
+	LineField   string            `toml:"line_field"`
+	NameLabel   string            `toml:"name_label"`
+	OutOfOrder  string            `toml:"out_of_order"`
+	Username    string            `toml:"username"`
+	Password    string            `toml:"password"`
+	BearerToken string            `toml:"bearer_token"`
+	TenantID    string            `toml:"tenant_id"`
+	Headers     map[string]string `toml:"headers"`
+	tls.ClientConfig
+
+	Log telegraf.Logger `toml:"-"`
+
+	client *http.Client
+	// last holds the timestamp of the newest entry sent to each stream.
+	last map[string]lastEntry
+}
+
+type lastEntry struct {
+	timestamp time.Time
+	sent      time.Time
+}
+
+func (l *Loki) Description() string {
+	return "Send logs to Loki"
+}
+
+func (l *Loki) SampleConfig() string {
+	return sampleConfig
+}
+
+func (l *Loki) Connect() error {
+	switch l.Format {
+	case "":
+		l.Format = "json"
+	case "json", "protobuf":
+	default:
+		return fmt.Errorf("unknown format %q", l.Format)
+	}
+
+	switch l.OutOfOrder {
+	case "":
+		l.OutOfOrder = "adjust"
+	case "adjust", "drop":
+	default:
+		return fmt.Errorf("unknown out_of_order action %q", l.OutOfOrder)
+	}
+
+	if l.Timeout.Duration == 0 {
+		l.Timeout.Duration = defaultTimeout
+	}
+
+	tlsCfg, err := l.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	l.client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+			Proxy:           http.ProxyFromEnvironment,
+		},
+		Timeout: l.Timeout.Duration,
+	}
+	l.last = make(map[string]lastEntry)
+	return nil
+}
+
+func (l *Loki) Close() error {
+	return nil
+}
+
+func (l *Loki) Write(metrics []telegraf.Metric) error {
+	streams := l.streams(metrics)
+	if len(streams) == 0 {
+		return nil
+	}
+
+	var body []byte
+	var contentType string
+	switch l.Format {
+	case "protobuf":
+		body = snappy.Encode(nil, encodeProtobuf(streams))
+		contentType = "application/x-protobuf"
+	default:
+		var err error
+		body, err = encodeJSON(streams)
+		if err != nil {
+			return err
+		}
+		contentType = "application/json"
+	}
+
+	if err := l.push(body, contentType); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for key, last := range l.last {
+		if now.Sub(last.sent) > streamExpiry {
+			delete(l.last, key)
+		}
+	}
+	for _, s := range streams {
+		l.last[s.key] = lastEntry{
+			timestamp: s.entries[len(s.entries)-1].timestamp,
+			sent:      now,
+		}
+	}
+	return nil
+}
+
+// streams groups the metrics by their labels.  The entries of each stream are
+// sorted by time, since Loki rejects entries older than the newest entry of
+// their stream.
+func (l *Loki) streams(metrics []telegraf.Metric) []*stream {
+	var streams []*stream
+	byKey := make(map[string]*stream)
+	for _, m := range metrics {
+		labels := make(map[string]string, len(m.TagList())+1)
+		for _, tag := range m.TagList() {
+			labels[sanitizeLabel(tag.Key)] = tag.Value
+		}
+		if l.NameLabel != "" {
+			labels[l.NameLabel] = m.Name()
+		}
+
+		s := &stream{labels: labels}
+		s.key = s.labelString()
+		if existing, ok := byKey[s.key]; ok {
+			s = existing
+		} else {
+			byKey[s.key] = s
+			streams = append(streams, s)
+		}
+
+		s.entries = append(s.entries, entry{
+			timestamp: m.Time(),
+			line:      l.line(m),
+		})
+	}
+
+	result := streams[:0]
+	for _, s := range streams {
+		sort.SliceStable(s.entries, func(i, j int) bool {
+			return s.entries[i].timestamp.Before(s.entries[j].timestamp)
+		})
+
+		if last, ok := l.last[s.key]; ok {
+			s.entries = l.fixOutOfOrder(s.entries, last.timestamp)
+		}
+		if len(s.entries) > 0 {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// fixOutOfOrder handles the sorted entries older than the last entry sent to
+// the stream.
+func (l *Loki) fixOutOfOrder(entries []entry, last time.Time) []entry {
+	n := 0
+	for n < len(entries) && entries[n].timestamp.Before(last) {
+		n++
+	}
+	if n == 0 {
+		return entries
+	}
+
+	if l.OutOfOrder == "drop" {
+		l.Log.Debugf("Dropped %d out of order entries", n)
+		return entries[n:]
+	}
+
+	for i := 0; i < n; i++ {
+		entries[i].timestamp = last
+	}
+	return entries
+}
+
+// line returns the log line of the metric, without the line field the fields
+// are formatted as logfmt sorted by key.
+func (l *Loki) line(m telegraf.Metric) string {
+	if v, ok := m.GetField(l.LineField); ok {
+		return formatValue(v)
+	}
+
+	fields := make([]*telegraf.Field, len(m.FieldList()))
+	copy(fields, m.FieldList())
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Key < fields[j].Key
+	})
+
+	pairs := make([]string, 0, len(fields))
+	for _, field := range fields {
+		value := formatValue(field.Value)
+		if _, ok := field.Value.(string); ok {
+			value = strconv.Quote(value)
+		}
+		pairs = append(pairs, field.Key+"="+value)
+	}
+	return strings.Join(pairs, " ")
+}
+
+func (l *Loki) push(body []byte, contentType string) error {
+	req, err := http.NewRequest(http.MethodPost, l.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("User-Agent", internal.ProductToken())
+	req.Header.Set("Content-Type", contentType)
+	if l.Username != "" || l.Password != "" {
+		req.SetBasicAuth(l.Username, l.Password)
+	}
+	if l.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+l.BearerToken)
+	}
+	if l.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", l.TenantID)
+	}
+	for k, v := range l.Headers {
+		if strings.ToLower(k) == "host" {
+			req.Host = v
+		}
+		req.Header.Set(k, v)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("when writing to [%s] received status code %d: %s",
+			l.URL, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func init() {
+	outputs.Add("loki", func() telegraf.Output {
+		return &Loki{
+			URL:        defaultURL,
+			Timeout:    internal.Duration{Duration: defaultTimeout},
+			LineField:  "message",
+			NameLabel:  "measurement",
+			Format:     "json",
+			OutOfOrder: "adjust",
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package loki
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type pushRequest struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	} `json:"streams"`
+}
+
+func newLoki(url string) *Loki {
+	return &Loki{
+		URL:        url,
+		LineField:  "message",
+		NameLabel:  "measurement",
+		OutOfOrder: "adjust",
+		Log:        testutil.Logger{},
+	}
+}
+
+func logMetric(host string, message string, ts int64) telegraf.Metric {
+	return testutil.MustMetric(
+		"syslog",
+		map[string]string{"host": host},
+		map[string]interface{}{"message": message, "severity_code": int64(6)},
+		time.Unix(ts, 0),
+	)
+}
+
+func TestWriteJSON(t *testing.T) {
+	var request pushRequest
+	var header http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &request))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	l := newLoki(ts.URL)
+	l.Username = "user"
+	l.Password = "secret"
+	l.TenantID = "tenant1"
+	require.NoError(t, l.Connect())
+
+	err := l.Write([]telegraf.Metric{
+		logMetric("a", "second", 2),
+		logMetric("b", "other", 1),
+		logMetric("a", "first", 1),
+		testutil.MustMetric(
+			"event",
+			map[string]string{"host.name": "a"},
+			map[string]interface{}{"code": int64(3), "text": "x y"},
+			time.Unix(3, 0),
+		),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "application/json", header.Get("Content-Type"))
+	require.Equal(t, "tenant1", header.Get("X-Scope-OrgID"))
+	user, pass, ok := (&http.Request{Header: header}).BasicAuth()
+	require.True(t, ok)
+	require.Equal(t, "user", user)
+	require.Equal(t, "secret", pass)
+
+	require.Len(t, request.Streams, 3)
+	require.Equal(t, map[string]string{"host": "a", "measurement": "syslog"}, request.Streams[0].Stream)
+	require.Equal(t, [][2]string{{"1000000000", "first"}, {"2000000000", "second"}}, request.Streams[0].Values)
+	require.Equal(t, map[string]string{"host": "b", "measurement": "syslog"}, request.Streams[1].Stream)
+	require.Equal(t, map[string]string{"host_name": "a", "measurement": "event"}, request.Streams[2].Stream)
+	require.Equal(t, [][2]string{{"3000000000", `code=3 text="x y"`}}, request.Streams[2].Values)
+}
+
+func TestWriteOutOfOrder(t *testing.T) {
+	var request pushRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &request))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	l := newLoki(ts.URL)
+	require.NoError(t, l.Connect())
+
+	require.NoError(t, l.Write([]telegraf.Metric{logMetric("a", "new", 10)}))
+	require.NoError(t, l.Write([]telegraf.Metric{logMetric("a", "old", 5), logMetric("a", "newer", 11)}))
+	require.Equal(t, [][2]string{{"10000000000", "old"}, {"11000000000", "newer"}}, request.Streams[0].Values)
+
+	l.OutOfOrder = "drop"
+	require.NoError(t, l.Write([]telegraf.Metric{logMetric("a", "old", 5), logMetric("a", "newest", 12)}))
+	require.Equal(t, [][2]string{{"12000000000", "newest"}}, request.Streams[0].Values)
+}
+
+func TestWriteProtobuf(t *testing.T) {
+	var body []byte
+	var header http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		compressed, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		body, err = snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	l := newLoki(ts.URL)
+	l.Format = "protobuf"
+	l.BearerToken = "token"
+	require.NoError(t, l.Connect())
+
+	require.NoError(t, l.Write([]telegraf.Metric{logMetric("a", "hello", 1)}))
+	require.Equal(t, "application/x-protobuf", header.Get("Content-Type"))
+	require.Equal(t, "Bearer token", header.Get("Authorization"))
+
+	// PushRequest.streams
+	request := proto.NewBuffer(body)
+	tag, err := request.DecodeVarint()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1<<3|2), tag)
+	msg, err := request.DecodeRawBytes(false)
+	require.NoError(t, err)
+
+	// Stream.labels
+	s := proto.NewBuffer(msg)
+	tag, err = s.DecodeVarint()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1<<3|2), tag)
+	labels, err := s.DecodeStringBytes()
+	require.NoError(t, err)
+	require.Equal(t, `{host="a", measurement="syslog"}`, labels)
+
+	// Stream.entries
+	tag, err = s.DecodeVarint()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2<<3|2), tag)
+	entry, err := s.DecodeRawBytes(false)
+	require.NoError(t, err)
+
+	e := proto.NewBuffer(entry)
+	tag, err = e.DecodeVarint()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1<<3|2), tag)
+	timestamp, err := e.DecodeRawBytes(false)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1 << 3, 1}, timestamp)
+	tag, err = e.DecodeVarint()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2<<3|2), tag)
+	line, err := e.DecodeStringBytes()
+	require.NoError(t, err)
+	require.Equal(t, "hello", line)
+}
+
+func TestWriteError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "entry out of order", http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	l := newLoki(ts.URL)
+	require.NoError(t, l.Connect())
+
+	err := l.Write([]telegraf.Metric{logMetric("a", "hello", 1)})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "entry out of order")
+	require.Empty(t, l.last)
+}
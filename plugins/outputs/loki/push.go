@@ -0,0 +1,156 @@
+package loki
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// entry is a single log line of a stream.
+type entry struct {
+	timestamp time.Time
+	line      string
+}
+
+// stream is a set of entries sharing the same labels.
+type stream struct {
+	key     string
+	labels  map[string]string
+	entries []entry
+}
+
+// labelString formats the labels in the Prometheus selector format used by
+// the protobuf push request.
+func (s *stream) labelString() string {
+	keys := make([]string, 0, len(s.labels))
+	for k := range s.labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+strconv.Quote(s.labels[k]))
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// encodeJSON returns the JSON body of a push request.
+func encodeJSON(streams []*stream) ([]byte, error) {
+	type jsonStream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+
+	request := struct {
+		Streams []jsonStream `json:"streams"`
+	}{
+		Streams: make([]jsonStream, 0, len(streams)),
+	}
+	for _, s := range streams {
+		values := make([][2]string, 0, len(s.entries))
+		for _, e := range s.entries {
+			values = append(values, [2]string{strconv.FormatInt(e.timestamp.UnixNano(), 10), e.line})
+		}
+		request.Streams = append(request.Streams, jsonStream{
+			Stream: s.labels,
+			Values: values,
+		})
+	}
+	return json.Marshal(request)
+}
+
+// encodeProtobuf returns the logproto.PushRequest message for the streams:
+//
+//	message PushRequest { repeated Stream streams = 1; }
+//	message Stream { string labels = 1; repeated Entry entries = 2; }
+//	message Entry { google.protobuf.Timestamp timestamp = 1; string line = 2; }
+func encodeProtobuf(streams []*stream) []byte {
+	var request []byte
+	for _, s := range streams {
+		var msg []byte
+		msg = appendBytes(msg, 1, []byte(s.labelString()))
+		for _, e := range s.entries {
+			var ts []byte
+			if sec := e.timestamp.Unix(); sec != 0 {
+				ts = appendVarint(ts, 1, uint64(sec))
+			}
+			if nsec := e.timestamp.Nanosecond(); nsec != 0 {
+				ts = appendVarint(ts, 2, uint64(nsec))
+			}
+
+			var entry []byte
+			entry = appendBytes(entry, 1, ts)
+			entry = appendBytes(entry, 2, []byte(e.line))
+
+			msg = appendBytes(msg, 2, entry)
+		}
+		request = appendBytes(request, 1, msg)
+	}
+	return request
+}
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func appendTag(b []byte, field int, wireType int) []byte {
+	return appendUvarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+func appendVarint(b []byte, field int, v uint64) []byte {
+	b = appendTag(b, field, wireVarint)
+	return appendUvarint(b, v)
+}
+
+func appendBytes(b []byte, field int, v []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = appendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+// sanitizeLabel replaces the characters not allowed in label names.
+func sanitizeLabel(name string) string {
+	b := []byte(name)
+	for n, c := range b {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && n > 0:
+		default:
+			b[n] = '_'
+		}
+	}
+	return string(b)
+}
+
+// formatValue formats a field value for the log line.
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
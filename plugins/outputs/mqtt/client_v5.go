@@ -0,0 +1,163 @@
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	pahov5 "github.com/eclipse/paho.golang/paho"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	commonmqtt "github.com/influxdata/telegraf/plugins/common/mqtt"
+)
+
+// clientV5 publishes with the MQTT 5 client, it reconnects on the next
+// publish once the connection is lost.
+type clientV5 struct {
+	opts    *paho.ClientOptions
+	timeout time.Duration
+
+	sync.Mutex
+	client *pahov5.Client
+
+	// topic aliases of the connection, up to the maximum of the server
+	aliases      map[string]uint16
+	aliasMaximum uint16
+}
+
+func newClientV5(opts *paho.ClientOptions, timeout time.Duration) *clientV5 {
+	return &clientV5{
+		opts:    opts,
+		timeout: timeout,
+	}
+}
+
+func (c *clientV5) connect() error {
+	c.Lock()
+	defer c.Unlock()
+	if c.client != nil {
+		return nil
+	}
+
+	conn, err := commonmqtt.Dial(c.opts.Servers, c.opts.TLSConfig, c.timeout)
+	if err != nil {
+		return err
+	}
+
+	var client *pahov5.Client
+	client = pahov5.NewClient(pahov5.ClientConfig{
+		Conn: conn,
+		OnClientError: func(error) {
+			c.reset(client)
+		},
+		OnServerDisconnect: func(*pahov5.Disconnect) {
+			c.reset(client)
+		},
+	})
+
+	// The MQTT 5 client cannot disable the keep alive
+	keepAlive := c.opts.KeepAlive
+	if keepAlive == 0 {
+		keepAlive = 60
+	}
+
+	cp := &pahov5.Connect{
+		ClientID:   c.opts.ClientID,
+		KeepAlive:  uint16(keepAlive),
+		CleanStart: true,
+	}
+	if c.opts.Username != "" {
+		cp.Username = c.opts.Username
+		cp.UsernameFlag = true
+	}
+	if c.opts.Password != "" {
+		cp.Password = []byte(c.opts.Password)
+		cp.PasswordFlag = true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	ca, err := client.Connect(ctx, cp)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	c.client = client
+	c.aliases = make(map[string]uint16)
+	c.aliasMaximum = 0
+	if ca.Properties != nil && ca.Properties.TopicAliasMaximum != nil {
+		c.aliasMaximum = *ca.Properties.TopicAliasMaximum
+	}
+	return nil
+}
+
+// reset forgets the lost connection of the client, if still current.
+func (c *clientV5) reset(client *pahov5.Client) {
+	c.Lock()
+	defer c.Unlock()
+	if c.client == client {
+		c.client = nil
+	}
+}
+
+func (c *clientV5) publish(topic string, qos byte, retain bool, body []byte, properties map[string]string) error {
+	if err := c.connect(); err != nil {
+		return err
+	}
+
+	c.Lock()
+	client := c.client
+	p := &pahov5.Publish{
+		QoS:        qos,
+		Retain:     retain,
+		Topic:      topic,
+		Payload:    body,
+		Properties: &pahov5.PublishProperties{},
+	}
+
+	// The first message of a topic sets the alias, the next ones use it
+	if alias, ok := c.aliases[topic]; ok {
+		p.Topic = ""
+		p.Properties.TopicAlias = pahov5.Uint16(alias)
+	} else if len(c.aliases) < int(c.aliasMaximum) {
+		alias := uint16(len(c.aliases) + 1)
+		c.aliases[topic] = alias
+		p.Properties.TopicAlias = pahov5.Uint16(alias)
+	}
+	c.Unlock()
+
+	keys := make([]string, 0, len(properties))
+	for k := range properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p.Properties.User.Add(k, properties[k])
+	}
+
+	if client == nil {
+		return errors.New("connection lost")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	if _, err := client.Publish(ctx, p); err != nil {
+		c.close()
+		return err
+	}
+	return nil
+}
+
+func (c *clientV5) close() error {
+	c.Lock()
+	client := c.client
+	c.client = nil
+	c.Unlock()
+
+	if client == nil {
+		return nil
+	}
+	return client.Disconnect(&pahov5.Disconnect{ReasonCode: 0})
+}
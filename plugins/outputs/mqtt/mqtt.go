@@ -11,6 +11,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/tls"
+	commonmqtt "github.com/influxdata/telegraf/plugins/common/mqtt"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
@@ -54,6 +55,13 @@ var sampleConfig = `
   ## actually reads it
   # retain = false
 
+  ## MQTT protocol version, either "3.1.1" or "5".
+  # protocol_version = "3.1.1"
+
+  ## Tags of the metric to send as MQTT 5 user properties. With batch set,
+  ## only tags with the same value in all metrics of the message are sent.
+  # user_property_tags = []
+
   ## Data format to output.
   ## Each data format has its own unique set of configuration options, read
   ## more about them here:
@@ -74,8 +82,12 @@ type MQTT struct {
 	BatchMessage bool `toml:"batch"`
 	Retain       bool `toml:"retain"`
 
-	client paho.Client
-	opts   *paho.ClientOptions
+	ProtocolVersion  string   `toml:"protocol_version"`
+	UserPropertyTags []string `toml:"user_property_tags"`
+
+	client   paho.Client
+	clientV5 *clientV5
+	opts     *paho.ClientOptions
 
 	serializer serializers.Serializer
 
@@ -89,12 +101,23 @@ func (m *MQTT) Connect() error {
 	if m.QoS > 2 || m.QoS < 0 {
 		return fmt.Errorf("MQTT Output, invalid QoS value: %d", m.QoS)
 	}
+	if err := commonmqtt.ValidateProtocolVersion(m.ProtocolVersion); err != nil {
+		return err
+	}
+	if len(m.UserPropertyTags) > 0 && m.ProtocolVersion != commonmqtt.ProtocolV5 {
+		return fmt.Errorf("user_property_tags requires protocol_version %q", commonmqtt.ProtocolV5)
+	}
 
 	m.opts, err = m.createOpts()
 	if err != nil {
 		return err
 	}
 
+	if m.ProtocolVersion == commonmqtt.ProtocolV5 {
+		m.clientV5 = newClientV5(m.opts, m.Timeout.Duration)
+		return m.clientV5.connect()
+	}
+
 	m.client = paho.NewClient(m.opts)
 	if token := m.client.Connect(); token.Wait() && token.Error() != nil {
 		return token.Error()
@@ -108,6 +131,9 @@ func (m *MQTT) SetSerializer(serializer serializers.Serializer) {
 }
 
 func (m *MQTT) Close() error {
+	if m.clientV5 != nil {
+		return m.clientV5.close()
+	}
 	if m.client.IsConnected() {
 		m.client.Disconnect(20)
 	}
@@ -156,7 +182,7 @@ func (m *MQTT) Write(metrics []telegraf.Metric) error {
 				continue
 			}
 
-			err = m.publish(topic, buf)
+			err = m.publish(topic, buf, m.userProperties(metric))
 			if err != nil {
 				return fmt.Errorf("Could not write to MQTT server, %s", err)
 			}
@@ -169,7 +195,7 @@ func (m *MQTT) Write(metrics []telegraf.Metric) error {
 		if err != nil {
 			return err
 		}
-		publisherr := m.publish(key, buf)
+		publisherr := m.publish(key, buf, m.userProperties(metricsmap[key]...))
 		if publisherr != nil {
 			return fmt.Errorf("Could not write to MQTT server, %s", publisherr)
 		}
@@ -178,7 +204,36 @@ func (m *MQTT) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
-func (m *MQTT) publish(topic string, body []byte) error {
+// userProperties returns the user property tags shared by all metrics.
+func (m *MQTT) userProperties(metrics ...telegraf.Metric) map[string]string {
+	if len(m.UserPropertyTags) == 0 {
+		return nil
+	}
+
+	properties := make(map[string]string)
+	for _, key := range m.UserPropertyTags {
+		value, ok := metrics[0].GetTag(key)
+		if !ok {
+			continue
+		}
+		for _, metric := range metrics[1:] {
+			if v, _ := metric.GetTag(key); v != value {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			properties[key] = value
+		}
+	}
+	return properties
+}
+
+func (m *MQTT) publish(topic string, body []byte, properties map[string]string) error {
+	if m.clientV5 != nil {
+		return m.clientV5.publish(topic, byte(m.QoS), m.Retain, body, properties)
+	}
+
 	token := m.client.Publish(topic, byte(m.QoS), m.Retain, body)
 	token.WaitTimeout(m.Timeout.Duration)
 	if token.Error() != nil {
@@ -1,8 +1,13 @@
 package mqtt
 
 import (
+	"net"
 	"testing"
+	"time"
 
+	"github.com/eclipse/paho.golang/packets"
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/testutil"
 
@@ -29,3 +34,83 @@ func TestConnectAndWrite(t *testing.T) {
 	err = m.Write(testutil.MockMetrics())
 	require.NoError(t, err)
 }
+
+func TestConnectAndWriteV5(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	connects := make(chan *packets.Connect, 1)
+	publishes := make(chan *packets.Publish, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			p, err := packets.ReadPacket(conn)
+			if err != nil {
+				return
+			}
+			switch c := p.Content.(type) {
+			case *packets.Connect:
+				connects <- c
+				connack := &packets.Connack{
+					Properties: &packets.Properties{
+						TopicAliasMaximum: paho.Uint16(10),
+					},
+				}
+				connack.WriteTo(conn)
+			case *packets.Publish:
+				publishes <- c
+			}
+		}
+	}()
+
+	s, _ := serializers.NewInfluxSerializer()
+	m := &MQTT{
+		Servers:          []string{listener.Addr().String()},
+		TopicPrefix:      "telegraf",
+		ProtocolVersion:  "5",
+		UserPropertyTags: []string{"region"},
+		serializer:       s,
+	}
+	require.NoError(t, m.Connect())
+	defer m.Close()
+
+	metric := testutil.MustMetric(
+		"cpu",
+		map[string]string{
+			"host":   "host01",
+			"region": "eu",
+		},
+		map[string]interface{}{
+			"time_idle": 42,
+		},
+		time.Unix(0, 0),
+	)
+	require.NoError(t, m.Write([]telegraf.Metric{metric, metric}))
+
+	connect := <-connects
+	require.Equal(t, byte(5), connect.ProtocolVersion)
+
+	// The second message refers to the topic by its alias
+	first := <-publishes
+	require.Equal(t, "telegraf/host01/cpu", first.Topic)
+	require.Equal(t, uint16(1), *first.Properties.TopicAlias)
+	require.Equal(t, []packets.User{{Key: "region", Value: "eu"}}, first.Properties.User)
+
+	second := <-publishes
+	require.Equal(t, "", second.Topic)
+	require.Equal(t, uint16(1), *second.Properties.TopicAlias)
+}
+
+func TestUserPropertyTagsRequireV5(t *testing.T) {
+	m := &MQTT{
+		Servers:          []string{"localhost:1883"},
+		UserPropertyTags: []string{"region"},
+	}
+	require.Error(t, m.Connect())
+}
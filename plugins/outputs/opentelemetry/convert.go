@@ -0,0 +1,347 @@
+package opentelemetry
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// builder converts metrics into an export request, grouping the data points
+// by resource and metric.
+type builder struct {
+	resourceTags map[string]bool
+	attributes   []*commonpb.KeyValue
+	scope        *commonpb.InstrumentationScope
+
+	request   *colmetricpb.ExportMetricsServiceRequest
+	resources map[string]*resource
+}
+
+type resource struct {
+	scope   *metricpb.ScopeMetrics
+	metrics map[string]*metricpb.Metric
+	// points holds the histogram and summary points being assembled from
+	// multiple fields and metrics.
+	points map[string]*point
+}
+
+// point is a histogram or summary data point with its cumulative buckets.
+type point struct {
+	histogram *metricpb.HistogramDataPoint
+	summary   *metricpb.SummaryDataPoint
+	buckets   map[float64]uint64
+	// hasCount is set when a count field was seen, otherwise the total is
+	// taken from the infinite bucket.
+	hasCount bool
+}
+
+func newBuilder(resourceTags []string, attributes map[string]string, scope *commonpb.InstrumentationScope) *builder {
+	b := &builder{
+		resourceTags: make(map[string]bool, len(resourceTags)),
+		scope:        scope,
+		request:      &colmetricpb.ExportMetricsServiceRequest{},
+		resources:    make(map[string]*resource),
+	}
+	for _, tag := range resourceTags {
+		b.resourceTags[tag] = true
+	}
+	for k, v := range attributes {
+		b.attributes = append(b.attributes, stringAttribute(k, v))
+	}
+	sortAttributes(b.attributes)
+	return b
+}
+
+// add converts the fields of the metric to data points.  Histograms and
+// summaries use the layout of the prometheus serializer: buckets are
+// "<name>_bucket" fields with an "le" tag, quantiles are fields with a
+// "quantile" tag, and "<name>_sum" and "<name>_count" hold the totals.
+func (b *builder) add(m telegraf.Metric) {
+	var resourceAttrs, pointAttrs []*commonpb.KeyValue
+	resourceAttrs = append(resourceAttrs, b.attributes...)
+	for _, tag := range m.TagList() {
+		switch {
+		case m.Type() == telegraf.Histogram && tag.Key == "le",
+			m.Type() == telegraf.Summary && tag.Key == "quantile":
+			continue
+		case b.resourceTags[tag.Key]:
+			resourceAttrs = append(resourceAttrs, stringAttribute(tag.Key, tag.Value))
+		default:
+			pointAttrs = append(pointAttrs, stringAttribute(tag.Key, tag.Value))
+		}
+	}
+	sortAttributes(resourceAttrs)
+
+	r := b.resource(resourceAttrs)
+	ts := uint64(m.Time().UnixNano())
+
+	for _, field := range m.FieldList() {
+		name := prometheus.MetricName(m.Name(), field.Key, m.Type())
+
+		switch m.Type() {
+		case telegraf.Histogram, telegraf.Summary:
+			b.addDistribution(r, m, name, field, pointAttrs, ts)
+		default:
+			dp := numberPoint(field.Value)
+			if dp == nil {
+				continue
+			}
+			dp.Attributes = pointAttrs
+			dp.TimeUnixNano = ts
+
+			switch data := r.metric(name, m.Type()).Data.(type) {
+			case *metricpb.Metric_Sum:
+				data.Sum.DataPoints = append(data.Sum.DataPoints, dp)
+			case *metricpb.Metric_Gauge:
+				data.Gauge.DataPoints = append(data.Gauge.DataPoints, dp)
+			}
+		}
+	}
+}
+
+func (b *builder) addDistribution(r *resource, m telegraf.Metric, name string, field *telegraf.Field, attrs []*commonpb.KeyValue, ts uint64) {
+	metric := r.metric(name, m.Type())
+	key := name + "\x00" + strconv.FormatUint(ts, 10) + "\x00" + attributesKey(attrs)
+	p, ok := r.points[key]
+	if !ok {
+		p = &point{buckets: make(map[float64]uint64)}
+		switch data := metric.Data.(type) {
+		case *metricpb.Metric_Histogram:
+			p.histogram = &metricpb.HistogramDataPoint{
+				Attributes:   attrs,
+				TimeUnixNano: ts,
+			}
+			data.Histogram.DataPoints = append(data.Histogram.DataPoints, p.histogram)
+		case *metricpb.Metric_Summary:
+			p.summary = &metricpb.SummaryDataPoint{
+				Attributes:   attrs,
+				TimeUnixNano: ts,
+			}
+			data.Summary.DataPoints = append(data.Summary.DataPoints, p.summary)
+		}
+		r.points[key] = p
+	}
+
+	switch {
+	case strings.HasSuffix(field.Key, "_sum"):
+		if sum, ok := prometheus.SampleSum(field.Value); ok {
+			p.setSum(sum)
+		}
+	case strings.HasSuffix(field.Key, "_count"):
+		if count, ok := prometheus.SampleCount(field.Value); ok {
+			p.setCount(count)
+			p.hasCount = true
+		}
+	case p.histogram != nil && strings.HasSuffix(field.Key, "_bucket"):
+		bound, ok := floatTag(m, "le")
+		if !ok {
+			return
+		}
+		if count, ok := prometheus.SampleCount(field.Value); ok {
+			p.buckets[bound] = count
+		}
+	case p.summary != nil:
+		quantile, ok := floatTag(m, "quantile")
+		if !ok {
+			return
+		}
+		if value, ok := prometheus.SampleValue(field.Value); ok {
+			p.summary.QuantileValues = append(p.summary.QuantileValues, &metricpb.SummaryDataPoint_ValueAtQuantile{
+				Quantile: quantile,
+				Value:    value,
+			})
+		}
+	}
+}
+
+func (b *builder) resource(attrs []*commonpb.KeyValue) *resource {
+	key := attributesKey(attrs)
+	r, ok := b.resources[key]
+	if ok {
+		return r
+	}
+
+	r = &resource{
+		scope:   &metricpb.ScopeMetrics{Scope: b.scope},
+		metrics: make(map[string]*metricpb.Metric),
+		points:  make(map[string]*point),
+	}
+	b.resources[key] = r
+	b.request.ResourceMetrics = append(b.request.ResourceMetrics, &metricpb.ResourceMetrics{
+		Resource:     &resourcepb.Resource{Attributes: attrs},
+		ScopeMetrics: []*metricpb.ScopeMetrics{r.scope},
+	})
+	return r
+}
+
+func (r *resource) metric(name string, valueType telegraf.ValueType) *metricpb.Metric {
+	key := name + "\x00" + strconv.Itoa(int(valueType))
+	m, ok := r.metrics[key]
+	if ok {
+		return m
+	}
+
+	m = &metricpb.Metric{Name: name}
+	switch valueType {
+	case telegraf.Counter:
+		m.Data = &metricpb.Metric_Sum{Sum: &metricpb.Sum{
+			AggregationTemporality: metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			IsMonotonic:            true,
+		}}
+	case telegraf.Histogram:
+		m.Data = &metricpb.Metric_Histogram{Histogram: &metricpb.Histogram{
+			AggregationTemporality: metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		}}
+	case telegraf.Summary:
+		m.Data = &metricpb.Metric_Summary{Summary: &metricpb.Summary{}}
+	default:
+		m.Data = &metricpb.Metric_Gauge{Gauge: &metricpb.Gauge{}}
+	}
+	r.metrics[key] = m
+	r.scope.Metrics = append(r.scope.Metrics, m)
+	return m
+}
+
+// finish completes the histogram and summary points and returns the request.
+func (b *builder) finish() *colmetricpb.ExportMetricsServiceRequest {
+	for _, r := range b.resources {
+		for _, p := range r.points {
+			p.finish()
+		}
+	}
+	return b.request
+}
+
+func (p *point) setSum(sum float64) {
+	if p.histogram != nil {
+		p.histogram.Sum = &sum
+	} else {
+		p.summary.Sum = sum
+	}
+}
+
+func (p *point) setCount(count uint64) {
+	if p.histogram != nil {
+		p.histogram.Count = count
+	} else {
+		p.summary.Count = count
+	}
+}
+
+// finish converts the cumulative prometheus buckets to the per bucket counts
+// of OTLP; the counts above the last finite bound go to the overflow bucket.
+func (p *point) finish() {
+	if p.summary != nil {
+		sort.Slice(p.summary.QuantileValues, func(i, j int) bool {
+			return p.summary.QuantileValues[i].Quantile < p.summary.QuantileValues[j].Quantile
+		})
+		return
+	}
+
+	if len(p.buckets) == 0 {
+		return
+	}
+
+	bounds := make([]float64, 0, len(p.buckets))
+	for bound := range p.buckets {
+		if !math.IsInf(bound, 1) {
+			bounds = append(bounds, bound)
+		}
+	}
+	sort.Float64s(bounds)
+
+	dp := p.histogram
+	total := dp.Count
+	if inf, ok := p.buckets[math.Inf(1)]; ok && !p.hasCount {
+		total = inf
+		dp.Count = inf
+	}
+
+	var previous uint64
+	dp.ExplicitBounds = bounds
+	dp.BucketCounts = make([]uint64, 0, len(bounds)+1)
+	for _, bound := range bounds {
+		cumulative := p.buckets[bound]
+		if cumulative < previous {
+			cumulative = previous
+		}
+		dp.BucketCounts = append(dp.BucketCounts, cumulative-previous)
+		previous = cumulative
+	}
+	if total < previous {
+		total = previous
+	}
+	dp.BucketCounts = append(dp.BucketCounts, total-previous)
+}
+
+// numberPoint returns the data point for a gauge or counter value, or nil
+// for unsupported types.
+func numberPoint(value interface{}) *metricpb.NumberDataPoint {
+	switch v := value.(type) {
+	case int64:
+		return intPoint(v)
+	case uint64:
+		if v <= math.MaxInt64 {
+			return intPoint(int64(v))
+		}
+		return doublePoint(float64(v))
+	case bool:
+		if v {
+			return intPoint(1)
+		}
+		return intPoint(0)
+	case float64:
+		return doublePoint(v)
+	default:
+		return nil
+	}
+}
+
+func intPoint(v int64) *metricpb.NumberDataPoint {
+	return &metricpb.NumberDataPoint{Value: &metricpb.NumberDataPoint_AsInt{AsInt: v}}
+}
+
+func doublePoint(v float64) *metricpb.NumberDataPoint {
+	return &metricpb.NumberDataPoint{Value: &metricpb.NumberDataPoint_AsDouble{AsDouble: v}}
+}
+
+func floatTag(m telegraf.Metric, key string) (float64, bool) {
+	tag, ok := m.GetTag(key)
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(tag, 64)
+	return v, err == nil
+}
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+func sortAttributes(attrs []*commonpb.KeyValue) {
+	sort.SliceStable(attrs, func(i, j int) bool {
+		return attrs[i].Key < attrs[j].Key
+	})
+}
+
+func attributesKey(attrs []*commonpb.KeyValue) string {
+	var b strings.Builder
+	for _, kv := range attrs {
+		b.WriteString(kv.Key)
+		b.WriteByte(0)
+		b.WriteString(kv.GetValue().GetStringValue())
+		b.WriteByte(0)
+	}
+	return b.String()
+}
@@ -0,0 +1,251 @@
+package opentelemetry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip" // register gzip compressor
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultGRPCEndpoint = "localhost:4317"
+	defaultHTTPEndpoint = "http://localhost:4318/v1/metrics"
+	defaultTimeout      = 5 * time.Second
+
+	contentType = "application/x-protobuf"
+)
+
+var sampleConfig = `
+  ## Protocol used to export the metrics, "grpc" or "http".
+  # protocol = "grpc"
+
+  ## Address of the OTLP receiver; "host:port" for gRPC, a URL for HTTP.
+  ## Defaults to "localhost:4317" for gRPC and
+  ## "http://localhost:4318/v1/metrics" for HTTP.
+  # endpoint = "localhost:4317"
+
+  ## Timeout for each export request.
+  # timeout = "5s"
+
+  ## Compression of the requests, "gzip" or "none".
+  # compression = "none"
+
+  ## Tags sent as resource attributes, the other tags are sent as attributes
+  ## of the data points.
+  # resource_tags = ["host"]
+
+  ## Additional resource attributes.
+  # [outputs.opentelemetry.attributes]
+  #   "service.name" = "telegraf"
+
+  ## Additional headers, sent as gRPC metadata with the gRPC protocol.
+  # [outputs.opentelemetry.headers]
+  #   key1 = "value1"
+
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
+`
+
+type OpenTelemetry struct {
+	Protocol     string            `toml:"protocol"`
+	Endpoint     string            `toml:"endpoint"`
+	Timeout      internal.Duration `toml:"timeout"`
+	Compression  string            `toml:"compression"`
+	ResourceTags []string          `toml:"resource_tags"`
+	Attributes   map[string]string `toml:"attributes"`
+	Headers      map[string]string `toml:"headers"`
+	tls.ClientConfig
+
+	Log telegraf.Logger `toml:"-"`
+
+	conn   *grpc.ClientConn
+	client *http.Client
+}
+
+func (o *OpenTelemetry) Description() string {
+	return "Send metrics to an OpenTelemetry receiver using OTLP"
+}
+
+func (o *OpenTelemetry) SampleConfig() string {
+	return sampleConfig
+}
+
+func (o *OpenTelemetry) Connect() error {
+	switch o.Compression {
+	case "", "none":
+		o.Compression = "none"
+	case "gzip":
+	default:
+		return fmt.Errorf("unsupported compression %q", o.Compression)
+	}
+
+	if o.Timeout.Duration == 0 {
+		o.Timeout.Duration = defaultTimeout
+	}
+
+	tlsCfg, err := o.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	switch o.Protocol {
+	case "", "grpc":
+		o.Protocol = "grpc"
+		if o.Endpoint == "" {
+			o.Endpoint = defaultGRPCEndpoint
+		}
+
+		opt := grpc.WithInsecure()
+		if tlsCfg != nil {
+			opt = grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg))
+		}
+
+		// The connection is established in the background and when writing.
+		conn, err := grpc.Dial(o.Endpoint, opt)
+		if err != nil {
+			return fmt.Errorf("failed to dial: %v", err)
+		}
+		o.conn = conn
+	case "http":
+		if o.Endpoint == "" {
+			o.Endpoint = defaultHTTPEndpoint
+		}
+
+		o.client = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: tlsCfg,
+				Proxy:           http.ProxyFromEnvironment,
+			},
+			Timeout: o.Timeout.Duration,
+		}
+	default:
+		return fmt.Errorf("unsupported protocol %q", o.Protocol)
+	}
+
+	return nil
+}
+
+func (o *OpenTelemetry) Close() error {
+	if o.conn != nil {
+		err := o.conn.Close()
+		o.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (o *OpenTelemetry) Write(metrics []telegraf.Metric) error {
+	b := newBuilder(o.ResourceTags, o.Attributes, &commonpb.InstrumentationScope{
+		Name:    "telegraf",
+		Version: internal.Version(),
+	})
+	for _, m := range metrics {
+		b.add(m)
+	}
+	request := b.finish()
+	if len(request.ResourceMetrics) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.Timeout.Duration)
+	defer cancel()
+
+	if o.Protocol == "http" {
+		return o.writeHTTP(ctx, request)
+	}
+	return o.writeGRPC(ctx, request)
+}
+
+func (o *OpenTelemetry) writeGRPC(ctx context.Context, request *colmetricpb.ExportMetricsServiceRequest) error {
+	if len(o.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(o.Headers))
+	}
+
+	var opts []grpc.CallOption
+	if o.Compression == "gzip" {
+		opts = append(opts, grpc.UseCompressor("gzip"))
+	}
+
+	client := colmetricpb.NewMetricsServiceClient(o.conn)
+	if _, err := client.Export(ctx, request, opts...); err != nil {
+		return fmt.Errorf("exporting to [%s] failed: %v", o.Endpoint, err)
+	}
+	return nil
+}
+
+func (o *OpenTelemetry) writeHTTP(ctx context.Context, request *colmetricpb.ExportMetricsServiceRequest) error {
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader = bytes.NewReader(body)
+	if o.Compression == "gzip" {
+		rc, err := internal.CompressWithGzip(reader)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		reader = rc
+	}
+
+	req, err := http.NewRequest(http.MethodPost, o.Endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("User-Agent", internal.ProductToken())
+	req.Header.Set("Content-Type", contentType)
+	if o.Compression == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range o.Headers {
+		if strings.ToLower(k) == "host" {
+			req.Host = v
+		}
+		req.Header.Set(k, v)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("when writing to [%s] received status code %d: %s",
+			o.Endpoint, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func init() {
+	outputs.Add("opentelemetry", func() telegraf.Output {
+		return &OpenTelemetry{
+			Protocol: "grpc",
+			Timeout:  internal.Duration{Duration: defaultTimeout},
+		}
+	})
+}
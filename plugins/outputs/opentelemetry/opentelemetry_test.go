@@ -0,0 +1,328 @@
+package opentelemetry
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+var scope = &commonpb.InstrumentationScope{Name: "telegraf", Version: "test"}
+
+func TestBuildGaugeAndCounter(t *testing.T) {
+	b := newBuilder([]string{"host"}, map[string]string{"service.name": "telegraf"}, scope)
+	b.add(testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": "a", "cpu": "cpu0"},
+		map[string]interface{}{"usage_idle": 98.5},
+		time.Unix(0, 1000),
+	))
+	b.add(testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": "a", "cpu": "cpu0"},
+		map[string]interface{}{"online": true, "name": "ignored"},
+		time.Unix(0, 1000),
+	))
+	b.add(testutil.MustMetric(
+		"net",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"bytes_recv": int64(42)},
+		time.Unix(0, 1000),
+		telegraf.Counter,
+	))
+	b.add(testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": "b", "cpu": "cpu0"},
+		map[string]interface{}{"usage_idle": 50.0},
+		time.Unix(0, 2000),
+	))
+
+	request := b.finish()
+	require.Equal(t, []*metricpb.ResourceMetrics{
+		{
+			Resource: &resourcepb.Resource{
+				Attributes: []*commonpb.KeyValue{
+					stringAttribute("host", "a"),
+					stringAttribute("service.name", "telegraf"),
+				},
+			},
+			ScopeMetrics: []*metricpb.ScopeMetrics{
+				{
+					Scope: scope,
+					Metrics: []*metricpb.Metric{
+						{
+							Name: "cpu_usage_idle",
+							Data: &metricpb.Metric_Gauge{Gauge: &metricpb.Gauge{
+								DataPoints: []*metricpb.NumberDataPoint{
+									{
+										Attributes:   []*commonpb.KeyValue{stringAttribute("cpu", "cpu0")},
+										TimeUnixNano: 1000,
+										Value:        &metricpb.NumberDataPoint_AsDouble{AsDouble: 98.5},
+									},
+								},
+							}},
+						},
+						{
+							Name: "cpu_online",
+							Data: &metricpb.Metric_Gauge{Gauge: &metricpb.Gauge{
+								DataPoints: []*metricpb.NumberDataPoint{
+									{
+										Attributes:   []*commonpb.KeyValue{stringAttribute("cpu", "cpu0")},
+										TimeUnixNano: 1000,
+										Value:        &metricpb.NumberDataPoint_AsInt{AsInt: 1},
+									},
+								},
+							}},
+						},
+						{
+							Name: "net_bytes_recv",
+							Data: &metricpb.Metric_Sum{Sum: &metricpb.Sum{
+								AggregationTemporality: metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+								IsMonotonic:            true,
+								DataPoints: []*metricpb.NumberDataPoint{
+									{
+										TimeUnixNano: 1000,
+										Value:        &metricpb.NumberDataPoint_AsInt{AsInt: 42},
+									},
+								},
+							}},
+						},
+					},
+				},
+			},
+		},
+		{
+			Resource: &resourcepb.Resource{
+				Attributes: []*commonpb.KeyValue{
+					stringAttribute("host", "b"),
+					stringAttribute("service.name", "telegraf"),
+				},
+			},
+			ScopeMetrics: []*metricpb.ScopeMetrics{
+				{
+					Scope: scope,
+					Metrics: []*metricpb.Metric{
+						{
+							Name: "cpu_usage_idle",
+							Data: &metricpb.Metric_Gauge{Gauge: &metricpb.Gauge{
+								DataPoints: []*metricpb.NumberDataPoint{
+									{
+										Attributes:   []*commonpb.KeyValue{stringAttribute("cpu", "cpu0")},
+										TimeUnixNano: 2000,
+										Value:        &metricpb.NumberDataPoint_AsDouble{AsDouble: 50.0},
+									},
+								},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}, request.ResourceMetrics)
+}
+
+func TestBuildHistogram(t *testing.T) {
+	b := newBuilder(nil, nil, scope)
+	for _, bucket := range []struct {
+		le    string
+		count int64
+	}{{"0.5", 1}, {"1", 3}, {"+Inf", 6}} {
+		b.add(testutil.MustMetric(
+			"prometheus",
+			map[string]string{"path": "/", "le": bucket.le},
+			map[string]interface{}{"http_latency_bucket": bucket.count},
+			time.Unix(0, 1000),
+			telegraf.Histogram,
+		))
+	}
+	b.add(testutil.MustMetric(
+		"prometheus",
+		map[string]string{"path": "/"},
+		map[string]interface{}{"http_latency_sum": 4.5, "http_latency_count": int64(6)},
+		time.Unix(0, 1000),
+		telegraf.Histogram,
+	))
+
+	request := b.finish()
+	metrics := request.ResourceMetrics[0].ScopeMetrics[0].Metrics
+	sum := 4.5
+	require.Equal(t, []*metricpb.Metric{
+		{
+			Name: "http_latency",
+			Data: &metricpb.Metric_Histogram{Histogram: &metricpb.Histogram{
+				AggregationTemporality: metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				DataPoints: []*metricpb.HistogramDataPoint{
+					{
+						Attributes:     []*commonpb.KeyValue{stringAttribute("path", "/")},
+						TimeUnixNano:   1000,
+						Count:          6,
+						Sum:            &sum,
+						BucketCounts:   []uint64{1, 2, 3},
+						ExplicitBounds: []float64{0.5, 1},
+					},
+				},
+			}},
+		},
+	}, metrics)
+}
+
+func TestBuildSummary(t *testing.T) {
+	b := newBuilder(nil, nil, scope)
+	for _, q := range []struct {
+		quantile string
+		value    float64
+	}{{"0.99", 3}, {"0.5", 1}} {
+		b.add(testutil.MustMetric(
+			"prometheus",
+			map[string]string{"quantile": q.quantile},
+			map[string]interface{}{"rpc_duration": q.value},
+			time.Unix(0, 1000),
+			telegraf.Summary,
+		))
+	}
+	b.add(testutil.MustMetric(
+		"prometheus",
+		map[string]string{},
+		map[string]interface{}{"rpc_duration_sum": 10.0, "rpc_duration_count": uint64(4)},
+		time.Unix(0, 1000),
+		telegraf.Summary,
+	))
+
+	request := b.finish()
+	metrics := request.ResourceMetrics[0].ScopeMetrics[0].Metrics
+	require.Equal(t, []*metricpb.Metric{
+		{
+			Name: "rpc_duration",
+			Data: &metricpb.Metric_Summary{Summary: &metricpb.Summary{
+				DataPoints: []*metricpb.SummaryDataPoint{
+					{
+						TimeUnixNano: 1000,
+						Count:        4,
+						Sum:          10,
+						QuantileValues: []*metricpb.SummaryDataPoint_ValueAtQuantile{
+							{Quantile: 0.5, Value: 1},
+							{Quantile: 0.99, Value: 3},
+						},
+					},
+				},
+			}},
+		},
+	}, metrics)
+}
+
+func TestHistogramInfiniteBucketOnly(t *testing.T) {
+	p := &point{
+		histogram: &metricpb.HistogramDataPoint{},
+		buckets:   map[float64]uint64{1: 2, math.Inf(1): 5},
+	}
+	p.finish()
+	require.Equal(t, uint64(5), p.histogram.Count)
+	require.Equal(t, []uint64{2, 3}, p.histogram.BucketCounts)
+	require.Equal(t, []float64{1}, p.histogram.ExplicitBounds)
+}
+
+func testMetrics() []telegraf.Metric {
+	return []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 98.5},
+			time.Unix(0, 1000),
+		),
+	}
+}
+
+func TestWriteHTTP(t *testing.T) {
+	var request colmetricpb.ExportMetricsServiceRequest
+	var header http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, proto.Unmarshal(body, &request))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	o := &OpenTelemetry{
+		Protocol:     "http",
+		Endpoint:     ts.URL,
+		ResourceTags: []string{"host"},
+		Headers:      map[string]string{"Authorization": "Bearer token"},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, o.Connect())
+	require.NoError(t, o.Write(testMetrics()))
+
+	require.Equal(t, "application/x-protobuf", header.Get("Content-Type"))
+	require.Equal(t, "Bearer token", header.Get("Authorization"))
+	require.Len(t, request.ResourceMetrics, 1)
+	attrs := request.ResourceMetrics[0].GetResource().GetAttributes()
+	require.Len(t, attrs, 1)
+	require.True(t, proto.Equal(stringAttribute("host", "a"), attrs[0]))
+}
+
+type exportServer struct {
+	colmetricpb.UnimplementedMetricsServiceServer
+
+	requests chan *colmetricpb.ExportMetricsServiceRequest
+	metadata chan metadata.MD
+}
+
+func (s *exportServer) Export(ctx context.Context, request *colmetricpb.ExportMetricsServiceRequest) (*colmetricpb.ExportMetricsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.metadata <- md
+	s.requests <- request
+	return &colmetricpb.ExportMetricsServiceResponse{}, nil
+}
+
+func TestWriteGRPC(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &exportServer{
+		requests: make(chan *colmetricpb.ExportMetricsServiceRequest, 1),
+		metadata: make(chan metadata.MD, 1),
+	}
+	server := grpc.NewServer()
+	colmetricpb.RegisterMetricsServiceServer(server, s)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	o := &OpenTelemetry{
+		Protocol:    "grpc",
+		Endpoint:    listener.Addr().String(),
+		Compression: "gzip",
+		Headers:     map[string]string{"x-tenant": "a"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, o.Connect())
+	defer o.Close()
+	require.NoError(t, o.Write(testMetrics()))
+
+	md := <-s.metadata
+	require.Equal(t, []string{"a"}, md.Get("x-tenant"))
+
+	request := <-s.requests
+	require.Len(t, request.ResourceMetrics, 1)
+	metrics := request.ResourceMetrics[0].ScopeMetrics[0].Metrics
+	require.Equal(t, "cpu_usage_idle", metrics[0].Name)
+	attrs := metrics[0].GetGauge().GetDataPoints()[0].GetAttributes()
+	require.Len(t, attrs, 1)
+	require.True(t, proto.Equal(stringAttribute("host", "a"), attrs[0]))
+}
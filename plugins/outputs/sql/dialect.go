@@ -0,0 +1,199 @@
+package sql
+
+import (
+	gosql "database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx"
+)
+
+// dialect holds the statements that differ between the supported databases.
+type dialect interface {
+	// driverName is the name of the registered database/sql driver.
+	driverName() string
+	// placeholder returns the query parameter for the n-th argument, counting
+	// from 1.
+	placeholder(n int) string
+	// columnType returns the column type used to store the value.
+	columnType(value interface{}) string
+	timestampType() string
+	tagType() string
+	// columns returns the columns of the table, the result is empty when the
+	// table does not exist.
+	columns(db *gosql.DB, table string) (map[string]bool, error)
+	// convert returns the value as passed to the driver.
+	convert(value interface{}) interface{}
+	// maxParameters is the number of parameters allowed in a single statement.
+	maxParameters() int
+	// rejected reports whether the database refused the values of the
+	// statement, such as a field type conflicting with its column, so that
+	// retrying the statement fails again.
+	rejected(err error) bool
+}
+
+func newDialect(driver string) (dialect, error) {
+	switch driver {
+	case "postgres", "pgx":
+		return &postgres{}, nil
+	case "sqlite", "sqlite3":
+		return &sqlite{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported driver %q", driver)
+	}
+}
+
+type postgres struct{}
+
+func (*postgres) driverName() string {
+	return "pgx"
+}
+
+func (*postgres) placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (*postgres) columnType(value interface{}) string {
+	switch value.(type) {
+	case int64:
+		return "BIGINT"
+	case uint64:
+		return "NUMERIC(20)"
+	case float64:
+		return "DOUBLE PRECISION"
+	case bool:
+		return "BOOLEAN"
+	default:
+		return "TEXT"
+	}
+}
+
+func (*postgres) timestampType() string {
+	return "TIMESTAMPTZ"
+}
+
+func (*postgres) tagType() string {
+	return "TEXT"
+}
+
+func (*postgres) columns(db *gosql.DB, table string) (map[string]bool, error) {
+	return queryColumns(db, `SELECT column_name FROM information_schema.columns
+WHERE table_schema = current_schema() AND table_name = $1`, table)
+}
+
+func (*postgres) convert(value interface{}) interface{} {
+	// database/sql does not accept unsigned integers above the int64 range,
+	// the text representation is cast to the numeric column.
+	if v, ok := value.(uint64); ok {
+		return strconv.FormatUint(v, 10)
+	}
+	return value
+}
+
+func (*postgres) maxParameters() int {
+	return 65535
+}
+
+func (*postgres) rejected(err error) bool {
+	pgErr, ok := err.(pgx.PgError)
+	if !ok {
+		return false
+	}
+	// Class 22 are data exceptions, class 23 integrity constraint violations
+	// and 42804 is a datatype mismatch.
+	return strings.HasPrefix(pgErr.Code, "22") ||
+		strings.HasPrefix(pgErr.Code, "23") ||
+		pgErr.Code == "42804"
+}
+
+type sqlite struct{}
+
+func (*sqlite) driverName() string {
+	return "sqlite"
+}
+
+func (*sqlite) placeholder(n int) string {
+	return "?"
+}
+
+func (*sqlite) columnType(value interface{}) string {
+	switch value.(type) {
+	case int64, uint64, bool:
+		return "INTEGER"
+	case float64:
+		return "REAL"
+	default:
+		return "TEXT"
+	}
+}
+
+func (*sqlite) timestampType() string {
+	return "TIMESTAMP"
+}
+
+func (*sqlite) tagType() string {
+	return "TEXT"
+}
+
+func (*sqlite) columns(db *gosql.DB, table string) (map[string]bool, error) {
+	return queryColumns(db, "SELECT name FROM pragma_table_info(?)", table)
+}
+
+func (*sqlite) convert(value interface{}) interface{} {
+	// SQLite integers are signed 64-bit, larger values are clamped.
+	if v, ok := value.(uint64); ok {
+		if v > math.MaxInt64 {
+			return int64(math.MaxInt64)
+		}
+		return int64(v)
+	}
+	return value
+}
+
+func (*sqlite) maxParameters() int {
+	return 999
+}
+
+func (*sqlite) rejected(err error) bool {
+	sqliteErr, ok := err.(interface{ Code() int })
+	if !ok {
+		return false
+	}
+	// The primary result code is in the lower byte: SQLITE_TOOBIG,
+	// SQLITE_CONSTRAINT and SQLITE_MISMATCH.
+	switch sqliteErr.Code() & 0xff {
+	case 18, 19, 20:
+		return true
+	}
+	return false
+}
+
+func queryColumns(db *gosql.DB, query string, table string) (map[string]bool, error) {
+	rows, err := db.Query(query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
+
+// quoteIdentifier quotes a table or column name.
+func quoteIdentifier(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// quoteLiteral quotes a string constant.
+func quoteLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -0,0 +1,22 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimescaleDBRequiresPostgres(t *testing.T) {
+	s := &SQL{
+		Driver:      "sqlite",
+		TimescaleDB: true,
+	}
+	require.Error(t, s.Connect())
+}
+
+func TestUnknownDriver(t *testing.T) {
+	s := &SQL{
+		Driver: "oracle",
+	}
+	require.Error(t, s.Connect())
+}
@@ -0,0 +1,7 @@
+// +build darwin,amd64 darwin,arm64 freebsd,386 freebsd,amd64 linux,386 linux,amd64 linux,arm linux,arm64 linux,ppc64le linux,riscv64 linux,s390x netbsd,amd64 openbsd,amd64 windows,386 windows,amd64 windows,arm64
+
+package sql
+
+// The pure Go SQLite driver is only available on the platforms it has been
+// translated for.
+import _ "modernc.org/sqlite"
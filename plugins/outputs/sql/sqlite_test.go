@@ -0,0 +1,226 @@
+// +build darwin,amd64 darwin,arm64 freebsd,386 freebsd,amd64 linux,386 linux,amd64 linux,arm linux,arm64 linux,ppc64le linux,riscv64 linux,s390x netbsd,amd64 openbsd,amd64 windows,386 windows,amd64 windows,arm64
+
+package sql
+
+import (
+	gosql "database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLite(t *testing.T) (*SQL, string, func()) {
+	dir, err := ioutil.TempDir("", "telegraf-sql")
+	require.NoError(t, err)
+
+	dsn := filepath.Join(dir, "metrics.db")
+	s := &SQL{
+		Driver:          "sqlite",
+		DataSourceName:  dsn,
+		TimestampColumn: "timestamp",
+		BatchSize:       2,
+		Timeout:         internal.Duration{Duration: 5 * time.Second},
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, s.Connect())
+
+	return s, dsn, func() {
+		s.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func query(t *testing.T, dsn string, q string) [][]interface{} {
+	db, err := gosql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows, err := db.Query(q)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	require.NoError(t, err)
+
+	var result [][]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for n := range values {
+			ptrs[n] = &values[n]
+		}
+		require.NoError(t, rows.Scan(ptrs...))
+		result = append(result, values)
+	}
+	require.NoError(t, rows.Err())
+	return result
+}
+
+func TestWriteCreatesTable(t *testing.T) {
+	s, dsn, cleanup := newSQLite(t)
+	defer cleanup()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{
+				"usage":  42.5,
+				"count":  int64(3),
+				"total":  uint64(7),
+				"active": true,
+				"state":  "ok",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	require.NoError(t, s.Write(metrics))
+
+	columns := query(t, dsn, "SELECT name, type FROM pragma_table_info('cpu') ORDER BY name")
+	require.Equal(t, [][]interface{}{
+		{"active", "INTEGER"},
+		{"count", "INTEGER"},
+		{"host", "TEXT"},
+		{"state", "TEXT"},
+		{"timestamp", "TIMESTAMP"},
+		{"total", "INTEGER"},
+		{"usage", "REAL"},
+	}, columns)
+
+	rows := query(t, dsn, `SELECT host, usage, count, total, active, state FROM cpu`)
+	require.Equal(t, [][]interface{}{
+		{"a", 42.5, int64(3), int64(7), int64(1), "ok"},
+	}, rows)
+}
+
+func TestWriteAddsColumns(t *testing.T) {
+	s, dsn, cleanup := newSQLite(t)
+	defer cleanup()
+
+	require.NoError(t, s.Write([]telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage": 1.0},
+			time.Unix(0, 0),
+		),
+	}))
+
+	require.NoError(t, s.Write([]telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "b", "cpu": "cpu0"},
+			map[string]interface{}{"usage": 2.0, "idle": 98.0},
+			time.Unix(1, 0),
+		),
+	}))
+
+	rows := query(t, dsn, `SELECT host, cpu, usage, idle FROM cpu ORDER BY timestamp`)
+	require.Equal(t, [][]interface{}{
+		{"a", nil, 1.0, nil},
+		{"b", "cpu0", 2.0, 98.0},
+	}, rows)
+}
+
+func TestWriteBatches(t *testing.T) {
+	s, dsn, cleanup := newSQLite(t)
+	defer cleanup()
+
+	var metrics []telegraf.Metric
+	for n := 0; n < 5; n++ {
+		metrics = append(metrics, testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value": int64(n)},
+			time.Unix(int64(n), 0),
+		))
+		metrics = append(metrics, testutil.MustMetric(
+			"mem",
+			map[string]string{},
+			map[string]interface{}{"value": int64(n)},
+			time.Unix(int64(n), 0),
+		))
+	}
+	require.NoError(t, s.Write(metrics))
+
+	require.Equal(t, [][]interface{}{{int64(5), int64(10)}},
+		query(t, dsn, `SELECT COUNT(*), SUM(value) FROM cpu`))
+	require.Equal(t, [][]interface{}{{int64(5), int64(10)}},
+		query(t, dsn, `SELECT COUNT(*), SUM(value) FROM mem`))
+}
+
+func TestWriteRejectsMetrics(t *testing.T) {
+	s, dsn, cleanup := newSQLite(t)
+	defer cleanup()
+
+	db, err := gosql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE "cpu" ("timestamp" TIMESTAMP NOT NULL, "value" REAL CHECK (typeof("value") = 'real'))`)
+	require.NoError(t, err)
+	db.Close()
+
+	var metrics []telegraf.Metric
+	for n, value := range []interface{}{1.5, "invalid", 2.5} {
+		metrics = append(metrics, testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value": value},
+			time.Unix(int64(n), 0),
+		))
+	}
+
+	err = s.Write(metrics)
+	require.IsType(t, &telegraf.PartialWriteError{}, err)
+	perr := err.(*telegraf.PartialWriteError)
+	require.Equal(t, []int{1}, perr.MetricsReject)
+	require.Empty(t, perr.MetricsRetry)
+
+	require.Equal(t, [][]interface{}{{1.5}, {2.5}},
+		query(t, dsn, `SELECT value FROM cpu ORDER BY timestamp`))
+}
+
+func TestWriteRejectsWideMetrics(t *testing.T) {
+	s, dsn, cleanup := newSQLite(t)
+	defer cleanup()
+
+	fields := make(map[string]interface{})
+	for n := 0; n < 1000; n++ {
+		fields[fmt.Sprintf("value%d", n)] = int64(n)
+	}
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value0": int64(1)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric("cpu", map[string]string{}, fields, time.Unix(1, 0)),
+	}
+
+	err := s.Write(metrics)
+	require.IsType(t, &telegraf.PartialWriteError{}, err)
+	perr := err.(*telegraf.PartialWriteError)
+	require.Equal(t, []int{1}, perr.MetricsReject)
+	require.Empty(t, perr.MetricsRetry)
+
+	require.Equal(t, [][]interface{}{{int64(1)}},
+		query(t, dsn, `SELECT value0 FROM cpu`))
+}
+
+func TestConnectDefaultTimeout(t *testing.T) {
+	s, _, cleanup := newSQLite(t)
+	defer cleanup()
+	s.Close()
+
+	s.Timeout.Duration = 0
+	require.NoError(t, s.Connect())
+	require.Equal(t, 5*time.Second, s.Timeout.Duration)
+}
@@ -0,0 +1,252 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers"
+	"golang.org/x/net/websocket"
+)
+
+var sampleConfig = `
+  ## URL is the address to send metrics to. Make sure ws or wss scheme is used.
+  url = "ws://127.0.0.1:8080/telegraf"
+
+  ## Timeouts for establishing the connection and writing a message.
+  # connect_timeout = "30s"
+  # write_timeout = "30s"
+
+  ## Value of the Origin header sent in the opening handshake, by default the
+  ## url with the http or https scheme.
+  # origin = ""
+
+  ## Send metrics in text frames instead of binary frames.
+  # use_text_frames = false
+
+  ## Delay before reconnecting after the connection was lost or could not be
+  ## established.  The delay doubles after each failed attempt up to
+  ## max_reconnect_backoff.
+  # reconnect_backoff = "1s"
+  # max_reconnect_backoff = "1m"
+
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
+
+  ## Data format to output.
+  ## Each data format has it's own unique set of configuration options, read
+  ## more about them here:
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_OUTPUT.md
+  # data_format = "influx"
+
+  ## Additional HTTP Upgrade headers
+  # [outputs.websocket.headers]
+  #   Authorization = "Bearer <TOKEN>"
+`
+
+const (
+	defaultConnectTimeout      = 30 * time.Second
+	defaultWriteTimeout        = 30 * time.Second
+	defaultReconnectBackoff    = time.Second
+	defaultMaxReconnectBackoff = time.Minute
+)
+
+type WebSocket struct {
+	URL                 string            `toml:"url"`
+	ConnectTimeout      internal.Duration `toml:"connect_timeout"`
+	WriteTimeout        internal.Duration `toml:"write_timeout"`
+	Origin              string            `toml:"origin"`
+	UseTextFrames       bool              `toml:"use_text_frames"`
+	ReconnectBackoff    internal.Duration `toml:"reconnect_backoff"`
+	MaxReconnectBackoff internal.Duration `toml:"max_reconnect_backoff"`
+	Headers             map[string]string `toml:"headers"`
+	tls.ClientConfig
+
+	Log telegraf.Logger `toml:"-"`
+
+	config     *websocket.Config
+	serializer serializers.Serializer
+
+	sync.Mutex
+	conn        *websocket.Conn
+	failures    int
+	nextConnect time.Time
+}
+
+func (w *WebSocket) SetSerializer(serializer serializers.Serializer) {
+	w.serializer = serializer
+}
+
+func (w *WebSocket) Connect() error {
+	if w.ConnectTimeout.Duration == 0 {
+		w.ConnectTimeout.Duration = defaultConnectTimeout
+	}
+	if w.WriteTimeout.Duration == 0 {
+		w.WriteTimeout.Duration = defaultWriteTimeout
+	}
+	if w.ReconnectBackoff.Duration == 0 {
+		w.ReconnectBackoff.Duration = defaultReconnectBackoff
+	}
+	if w.MaxReconnectBackoff.Duration == 0 {
+		w.MaxReconnectBackoff.Duration = defaultMaxReconnectBackoff
+	}
+
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return fmt.Errorf("error parsing url: %v", err)
+	}
+
+	origin := w.Origin
+	switch u.Scheme {
+	case "ws":
+		if origin == "" {
+			origin = "http://" + u.Host
+		}
+	case "wss":
+		if origin == "" {
+			origin = "https://" + u.Host
+		}
+	default:
+		return fmt.Errorf("unsupported scheme %q: only ws and wss are supported", u.Scheme)
+	}
+
+	config, err := websocket.NewConfig(w.URL, origin)
+	if err != nil {
+		return err
+	}
+
+	config.TlsConfig, err = w.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	config.Header = make(http.Header)
+	for k, v := range w.Headers {
+		config.Header.Set(k, v)
+	}
+	config.Dialer = &net.Dialer{Timeout: w.ConnectTimeout.Duration}
+	w.config = config
+
+	w.Lock()
+	defer w.Unlock()
+	return w.connect()
+}
+
+// connect opens the connection, scheduling the next attempt with an
+// exponential backoff when it fails.
+func (w *WebSocket) connect() error {
+	conn, err := websocket.DialConfig(w.config)
+	if err != nil {
+		w.failures++
+		w.nextConnect = time.Now().Add(w.backoff())
+		return fmt.Errorf("error connecting to %s: %v", w.URL, err)
+	}
+
+	if w.UseTextFrames {
+		conn.PayloadType = websocket.TextFrame
+	} else {
+		conn.PayloadType = websocket.BinaryFrame
+	}
+
+	w.conn = conn
+	w.failures = 0
+	go w.read(conn)
+	return nil
+}
+
+func (w *WebSocket) backoff() time.Duration {
+	backoff := w.ReconnectBackoff.Duration
+	for i := 1; i < w.failures && backoff < w.MaxReconnectBackoff.Duration; i++ {
+		backoff *= 2
+	}
+	if backoff > w.MaxReconnectBackoff.Duration {
+		backoff = w.MaxReconnectBackoff.Duration
+	}
+	return backoff
+}
+
+// read discards the messages sent by the server, answering pings and
+// noticing when the server closes the connection.
+func (w *WebSocket) read(conn *websocket.Conn) {
+	var msg []byte
+	for {
+		if err := websocket.Message.Receive(conn, &msg); err != nil {
+			break
+		}
+	}
+
+	w.Lock()
+	defer w.Unlock()
+	if w.conn == conn {
+		w.Log.Debugf("Connection to %s closed by server", w.URL)
+		w.closeConn()
+	}
+}
+
+func (w *WebSocket) closeConn() {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+}
+
+func (w *WebSocket) Write(metrics []telegraf.Metric) error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.conn == nil {
+		if time.Now().Before(w.nextConnect) {
+			return errors.New("not connected, waiting to reconnect")
+		}
+		if err := w.connect(); err != nil {
+			return err
+		}
+		w.Log.Infof("Reconnected to %s", w.URL)
+	}
+
+	payload, err := w.serializer.SerializeBatch(metrics)
+	if err != nil {
+		return err
+	}
+
+	w.conn.SetWriteDeadline(time.Now().Add(w.WriteTimeout.Duration))
+	_, err = w.conn.Write(payload)
+	if err != nil {
+		w.closeConn()
+		return fmt.Errorf("error writing to %s: %v", w.URL, err)
+	}
+	return nil
+}
+
+func (w *WebSocket) Close() error {
+	w.Lock()
+	defer w.Unlock()
+	w.closeConn()
+	return nil
+}
+
+func (w *WebSocket) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WebSocket) Description() string {
+	return "Generic WebSocket output writer."
+}
+
+func init() {
+	outputs.Add("websocket", func() telegraf.Output {
+		return &WebSocket{}
+	})
+}
@@ -0,0 +1,155 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+type frame struct {
+	payloadType byte
+	data        string
+}
+
+// frameCodec receives a frame along with its type.
+var frameCodec = websocket.Codec{
+	Unmarshal: func(data []byte, payloadType byte, v interface{}) error {
+		*v.(*frame) = frame{payloadType: payloadType, data: string(data)}
+		return nil
+	},
+}
+
+func newTestServer(t *testing.T, frames chan<- frame, closeAfter int) *httptest.Server {
+	server := websocket.Server{
+		Handler: func(conn *websocket.Conn) {
+			for i := 0; closeAfter == 0 || i < closeAfter; i++ {
+				var f frame
+				if err := frameCodec.Receive(conn, &f); err != nil {
+					return
+				}
+				frames <- f
+			}
+			conn.Close()
+		},
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		server.ServeHTTP(w, r)
+	}))
+}
+
+func newTestWebSocket(url string) *WebSocket {
+	w := &WebSocket{
+		URL:     "ws://" + strings.TrimPrefix(url, "http://"),
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Log:     testutil.Logger{},
+	}
+	w.SetSerializer(influx.NewSerializer())
+	return w
+}
+
+func testMetrics() []telegraf.Metric {
+	return []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0),
+		),
+	}
+}
+
+func TestWriteFrames(t *testing.T) {
+	tests := []struct {
+		name          string
+		useTextFrames bool
+		payloadType   byte
+	}{
+		{
+			name:        "binary frames",
+			payloadType: websocket.BinaryFrame,
+		},
+		{
+			name:          "text frames",
+			useTextFrames: true,
+			payloadType:   websocket.TextFrame,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames := make(chan frame, 1)
+			ts := newTestServer(t, frames, 0)
+			defer ts.Close()
+
+			w := newTestWebSocket(ts.URL)
+			w.UseTextFrames = tt.useTextFrames
+			require.NoError(t, w.Connect())
+			defer w.Close()
+
+			require.NoError(t, w.Write(testMetrics()))
+			f := <-frames
+			require.Equal(t, tt.payloadType, f.payloadType)
+			require.Equal(t, "cpu value=42 0\n", f.data)
+		})
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	frames := make(chan frame, 2)
+	ts := newTestServer(t, frames, 1)
+	defer ts.Close()
+
+	w := newTestWebSocket(ts.URL)
+	require.NoError(t, w.Connect())
+	defer w.Close()
+
+	require.NoError(t, w.Write(testMetrics()))
+	<-frames
+
+	// Wait for the server closing the connection to be noticed.
+	for i := 0; i < 100; i++ {
+		w.Lock()
+		closed := w.conn == nil
+		w.Unlock()
+		if closed {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	require.NoError(t, w.Write(testMetrics()))
+	<-frames
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	w := &WebSocket{
+		ReconnectBackoff:    internal.Duration{Duration: time.Second},
+		MaxReconnectBackoff: internal.Duration{Duration: 5 * time.Second},
+	}
+
+	var backoffs []time.Duration
+	for w.failures = 1; w.failures <= 5; w.failures++ {
+		backoffs = append(backoffs, w.backoff())
+	}
+	require.Equal(t, []time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		5 * time.Second,
+		5 * time.Second,
+	}, backoffs)
+}
+
+func TestUnsupportedScheme(t *testing.T) {
+	w := &WebSocket{URL: "http://127.0.0.1:8080/telegraf"}
+	require.Error(t, w.Connect())
+}
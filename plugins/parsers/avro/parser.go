@@ -0,0 +1,277 @@
+package avro
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/avro"
+)
+
+// Parser decodes Avro messages in the schema registry wire format, a magic
+// byte and 4 byte schema ID followed by the binary encoded record.
+type Parser struct {
+	// MetricName is used when neither Measurement nor the record name is set.
+	MetricName string
+	// Measurement overrides the record name as the measurement name.
+	Measurement string
+	// Tags lists the record fields to use as tags.
+	Tags []string
+	// Fields lists the record fields to use as fields; all fields that are
+	// not tags or the timestamp are used when empty.
+	Fields []string
+	// Timestamp is the record field holding the metric time.
+	Timestamp string
+	// TimestampFormat is one of "unix", "unix_ms", "unix_us" or "unix_ns".
+	TimestampFormat string
+	// FieldSeparator joins the names of nested record fields.
+	FieldSeparator string
+	DefaultTags    map[string]string
+
+	// Registry is used to look up schemas by the ID in each message.
+	Registry *avro.SchemaRegistry
+	// Schema, when set, is used for every message regardless of the ID.
+	Schema *avro.Schema
+
+	TimeFunc func() time.Time
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	id, payload, err := avro.DecodeWire(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := p.Schema
+	if schema == nil {
+		if p.Registry == nil {
+			return nil, fmt.Errorf("no schema registry or schema configured")
+		}
+		schema, err = p.Registry.SchemaByID(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	native, _, err := schema.Codec.NativeFromBinary(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	p.flatten(schema, schema.Definition, native, "", values)
+
+	tm := p.TimeFunc()
+	if p.Timestamp != "" {
+		v, ok := values[p.Timestamp]
+		if !ok {
+			return nil, fmt.Errorf("timestamp field %q not found", p.Timestamp)
+		}
+		tm, err = p.parseTimestamp(v)
+		if err != nil {
+			return nil, err
+		}
+		delete(values, p.Timestamp)
+	}
+
+	tags := make(map[string]string)
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	for _, key := range p.Tags {
+		v, ok := values[key]
+		if !ok {
+			continue
+		}
+		tags[key] = toString(v)
+		delete(values, key)
+	}
+
+	fields := make(map[string]interface{})
+	if len(p.Fields) > 0 {
+		for _, key := range p.Fields {
+			if v, ok := values[key]; ok {
+				fields[key] = toField(v)
+			}
+		}
+	} else {
+		for k, v := range values {
+			fields[k] = toField(v)
+		}
+	}
+
+	name := p.Measurement
+	if name == "" {
+		name = schema.Name()
+	}
+	if name == "" {
+		name = p.MetricName
+	}
+
+	m, err := metric.New(name, tags, fields, tm)
+	if err != nil {
+		return nil, err
+	}
+	return []telegraf.Metric{m}, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, fmt.Errorf("can not parse the line: %s, for data format: avro", line)
+	}
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+// flatten walks the schema alongside the decoded value, collecting leaf
+// values keyed by their path joined with the field separator.
+func (p *Parser) flatten(schema *avro.Schema, t interface{}, value interface{}, prefix string, out map[string]interface{}) {
+	if value == nil {
+		return
+	}
+	t = schema.Resolve(t)
+
+	switch typ := t.(type) {
+	case []interface{}:
+		// Union values are wrapped in a map keyed by the member type name.
+		wrapped, ok := value.(map[string]interface{})
+		if !ok || len(wrapped) != 1 {
+			return
+		}
+		for name, v := range wrapped {
+			for _, member := range avro.UnionMembers(typ) {
+				member = schema.Resolve(member)
+				memberName := avro.TypeName(member)
+				if name == memberName || strings.HasSuffix(name, "."+memberName) {
+					p.flatten(schema, member, v, prefix, out)
+					return
+				}
+			}
+		}
+	case map[string]interface{}:
+		switch typ["type"] {
+		case "record":
+			record, ok := value.(map[string]interface{})
+			if !ok {
+				return
+			}
+			fields, _ := typ["fields"].([]interface{})
+			for _, f := range fields {
+				field, ok := f.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				name, _ := field["name"].(string)
+				p.flatten(schema, field["type"], record[name], p.join(prefix, name), out)
+			}
+		case "array":
+			items, ok := value.([]interface{})
+			if !ok {
+				return
+			}
+			for i, item := range items {
+				p.flatten(schema, typ["items"], item, p.join(prefix, strconv.Itoa(i)), out)
+			}
+		case "map":
+			entries, ok := value.(map[string]interface{})
+			if !ok {
+				return
+			}
+			for k, v := range entries {
+				p.flatten(schema, typ["values"], v, p.join(prefix, k), out)
+			}
+		default:
+			out[prefix] = value
+		}
+	default:
+		out[prefix] = value
+	}
+}
+
+func (p *Parser) join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	sep := p.FieldSeparator
+	if sep == "" {
+		sep = "_"
+	}
+	return prefix + sep + name
+}
+
+func (p *Parser) parseTimestamp(v interface{}) (time.Time, error) {
+	if t, ok := v.(time.Time); ok {
+		return t, nil
+	}
+
+	var n int64
+	switch v := v.(type) {
+	case int32:
+		n = int64(v)
+	case int64:
+		n = v
+	case float32:
+		n = int64(v)
+	case float64:
+		n = int64(v)
+	case string:
+		var err error
+		n, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parsing timestamp %q: %v", v, err)
+		}
+	default:
+		return time.Time{}, fmt.Errorf("unsupported timestamp type %T", v)
+	}
+
+	switch p.TimestampFormat {
+	case "", "unix":
+		return time.Unix(n, 0), nil
+	case "unix_ms":
+		return time.Unix(0, n*int64(time.Millisecond)), nil
+	case "unix_us":
+		return time.Unix(0, n*int64(time.Microsecond)), nil
+	case "unix_ns":
+		return time.Unix(0, n), nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown timestamp format %q", p.TimestampFormat)
+	}
+}
+
+// toField converts decoded Avro values to the types supported by metrics.
+func toField(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int32:
+		return int64(v)
+	case float32:
+		return float64(v)
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UnixNano()
+	case time.Duration:
+		return int64(v)
+	}
+	return v
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprintf("%v", toField(v))
+}
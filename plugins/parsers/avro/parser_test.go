@@ -0,0 +1,183 @@
+package avro
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/avro"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+)
+
+const schemaDefinition = `
+{
+	"type": "record",
+	"name": "cpu",
+	"namespace": "com.example",
+	"fields": [
+		{"name": "host", "type": "string"},
+		{"name": "usage_idle", "type": "double"},
+		{"name": "count", "type": ["null", "int"], "default": null},
+		{"name": "ts", "type": "long"},
+		{"name": "meta", "type": {
+			"type": "record",
+			"name": "meta",
+			"fields": [
+				{"name": "region", "type": "string"},
+				{"name": "rack", "type": "int"}
+			]
+		}}
+	]
+}`
+
+func encode(t *testing.T, id int, record map[string]interface{}) []byte {
+	codec, err := goavro.NewCodec(schemaDefinition)
+	require.NoError(t, err)
+	payload, err := codec.BinaryFromNative(nil, record)
+	require.NoError(t, err)
+	return avro.EncodeWire(id, payload)
+}
+
+var record = map[string]interface{}{
+	"host":       "a",
+	"usage_idle": 91.5,
+	"count":      goavro.Union("int", int32(3)),
+	"ts":         int64(1525478795123),
+	"meta": map[string]interface{}{
+		"region": "eu",
+		"rack":   int32(7),
+	},
+}
+
+func TestParseWithSchema(t *testing.T) {
+	schema, err := avro.NewSchema(0, schemaDefinition)
+	require.NoError(t, err)
+
+	parser := &Parser{
+		Tags:            []string{"host", "meta_region"},
+		Timestamp:       "ts",
+		TimestampFormat: "unix_ms",
+		DefaultTags:     map[string]string{"source": "kafka"},
+		Schema:          schema,
+		TimeFunc:        time.Now,
+	}
+
+	metrics, err := parser.Parse(encode(t, 42, record))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{
+				"host":        "a",
+				"meta_region": "eu",
+				"source":      "kafka",
+			},
+			map[string]interface{}{
+				"usage_idle": 91.5,
+				"count":      int64(3),
+				"meta_rack":  int64(7),
+			},
+			time.Unix(0, 1525478795123*int64(time.Millisecond)),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestParseNullUnionAndFieldSelection(t *testing.T) {
+	schema, err := avro.NewSchema(0, schemaDefinition)
+	require.NoError(t, err)
+
+	parser := &Parser{
+		Measurement:    "usage",
+		Fields:         []string{"usage_idle", "count"},
+		FieldSeparator: ".",
+		Schema:         schema,
+		TimeFunc: func() time.Time {
+			return time.Unix(0, 0)
+		},
+	}
+
+	r := make(map[string]interface{})
+	for k, v := range record {
+		r[k] = v
+	}
+	r["count"] = nil
+
+	metrics, err := parser.Parse(encode(t, 1, r))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"usage",
+			map[string]string{},
+			map[string]interface{}{
+				"usage_idle": 91.5,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestParseWithRegistry(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/schemas/ids/42" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+		fmt.Fprintf(w, `{"schema": %q}`, schemaDefinition)
+	}))
+	defer ts.Close()
+
+	parser := &Parser{
+		Tags:     []string{"host"},
+		Fields:   []string{"usage_idle"},
+		Registry: avro.NewSchemaRegistry(ts.URL, 5*time.Second),
+		TimeFunc: func() time.Time {
+			return time.Unix(0, 0)
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		m, err := parser.ParseLine(string(encode(t, 42, record)))
+		require.NoError(t, err)
+		testutil.RequireMetricEqual(t,
+			testutil.MustMetric(
+				"cpu",
+				map[string]string{
+					"host": "a",
+				},
+				map[string]interface{}{
+					"usage_idle": 91.5,
+				},
+				time.Unix(0, 0),
+			),
+			m,
+		)
+	}
+	require.Equal(t, 1, requests, "schema should be cached")
+
+	_, err := parser.Parse(encode(t, 43, record))
+	require.Error(t, err)
+}
+
+func TestParseInvalidHeader(t *testing.T) {
+	schema, err := avro.NewSchema(0, schemaDefinition)
+	require.NoError(t, err)
+	parser := &Parser{Schema: schema, TimeFunc: time.Now}
+
+	_, err = parser.Parse([]byte{0x00, 0x01})
+	require.Error(t, err)
+
+	_, err = parser.Parse([]byte{0x01, 0x00, 0x00, 0x00, 0x01, 0x00})
+	require.Error(t, err)
+}
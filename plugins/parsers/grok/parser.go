@@ -90,6 +90,10 @@ type Parser struct {
 	// UniqueTimestamp when set to "disable", timestamp will not incremented if there is a duplicate.
 	UniqueTimestamp string
 
+	// Multiline when set, Parse matches the patterns against the whole buffer
+	// as a single event instead of line by line.
+	Multiline bool
+
 	// typeMap is a map of patterns -> capture name -> modifier,
 	//   ie, {
 	//          "%{TESTLOG}":
@@ -380,6 +384,18 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 
 	metrics := make([]telegraf.Metric, 0)
 
+	if p.Multiline {
+		event := strings.TrimRight(string(buf), "\r\n")
+		m, err := p.ParseLine(event)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+		return metrics, nil
+	}
+
 	scanner := bufio.NewScanner(bytes.NewReader(buf))
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -1115,3 +1115,25 @@ func TestTrimRegression(t *testing.T) {
 	)
 	require.Equal(t, expected, actual)
 }
+
+func TestMultiline(t *testing.T) {
+	p := &Parser{
+		Measurement: "log",
+		Patterns:    []string{`(?s)^%{LOGLEVEL:level:tag} %{GREEDYDATA:message}`},
+		Multiline:   true,
+	}
+	require.NoError(t, p.Compile())
+
+	metrics, err := p.Parse([]byte("ERROR failed\n  at a\n  at b\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]string{"level": "ERROR"}, metrics[0].Tags())
+	require.Equal(t, map[string]interface{}{"message": "failed\n  at a\n  at b"}, metrics[0].Fields())
+
+	// Without multiline each line is parsed on its own.
+	p.Multiline = false
+	metrics, err = p.Parse([]byte("ERROR failed\n  at a\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]interface{}{"message": "failed"}, metrics[0].Fields())
+}
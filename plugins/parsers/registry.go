@@ -2,9 +2,12 @@ package parsers
 
 import (
 	"fmt"
+	"io/ioutil"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	commonavro "github.com/influxdata/telegraf/plugins/common/avro"
+	"github.com/influxdata/telegraf/plugins/parsers/avro"
 	"github.com/influxdata/telegraf/plugins/parsers/collectd"
 	"github.com/influxdata/telegraf/plugins/parsers/csv"
 	"github.com/influxdata/telegraf/plugins/parsers/dropwizard"
@@ -131,6 +134,7 @@ type Config struct {
 	GrokCustomPatternFiles []string `toml:"grok_custom_pattern_files"`
 	GrokTimezone           string   `toml:"grok_timezone"`
 	GrokUniqueTimestamp    string   `toml:"grok_unique_timestamp"`
+	GrokMultiline          bool     `toml:"grok_multiline"`
 
 	//csv configuration
 	CSVColumnNames       []string `toml:"csv_column_names"`
@@ -148,6 +152,17 @@ type Config struct {
 
 	// FormData configuration
 	FormUrlencodedTagKeys []string `toml:"form_urlencoded_tag_keys"`
+
+	// Avro configuration
+	AvroSchemaRegistry  string   `toml:"avro_schema_registry"`
+	AvroSchema          string   `toml:"avro_schema"`
+	AvroSchemaFile      string   `toml:"avro_schema_file"`
+	AvroMeasurement     string   `toml:"avro_measurement"`
+	AvroTags            []string `toml:"avro_tags"`
+	AvroFields          []string `toml:"avro_fields"`
+	AvroTimestamp       string   `toml:"avro_timestamp"`
+	AvroTimestampFormat string   `toml:"avro_timestamp_format"`
+	AvroFieldSeparator  string   `toml:"avro_field_separator"`
 }
 
 type parserCreator func(config *Config)(Parser, error)
@@ -212,7 +227,8 @@ func NewParser(config *Config) (Parser, error) {
 			config.GrokCustomPatterns,
 			config.GrokCustomPatternFiles,
 			config.GrokTimezone,
-			config.GrokUniqueTimestamp)
+			config.GrokUniqueTimestamp,
+			config.GrokMultiline)
 	case "csv":
 		parser, err = newCSVParser(config.MetricName,
 			config.CSVHeaderRowCount,
@@ -236,6 +252,8 @@ func NewParser(config *Config) (Parser, error) {
 			config.DefaultTags,
 			config.FormUrlencodedTagKeys,
 		)
+	case "avro":
+		parser, err = newAvroParser(config)
 	default:
 		if parserCreater, ok:= parserRegistry[config.DataFormat]; ok {
 			return parserCreater(config)
@@ -303,10 +321,48 @@ func newCSVParser(metricName string,
 	return parser, nil
 }
 
+func newAvroParser(config *Config) (Parser, error) {
+	if config.AvroSchemaRegistry == "" && config.AvroSchema == "" && config.AvroSchemaFile == "" {
+		return nil, fmt.Errorf("one of avro_schema_registry, avro_schema or avro_schema_file must be set")
+	}
+
+	parser := &avro.Parser{
+		MetricName:      config.MetricName,
+		Measurement:     config.AvroMeasurement,
+		Tags:            config.AvroTags,
+		Fields:          config.AvroFields,
+		Timestamp:       config.AvroTimestamp,
+		TimestampFormat: config.AvroTimestampFormat,
+		FieldSeparator:  config.AvroFieldSeparator,
+		DefaultTags:     config.DefaultTags,
+		TimeFunc:        time.Now,
+	}
+
+	definition := config.AvroSchema
+	if config.AvroSchemaFile != "" {
+		octets, err := ioutil.ReadFile(config.AvroSchemaFile)
+		if err != nil {
+			return nil, err
+		}
+		definition = string(octets)
+	}
+
+	if definition != "" {
+		schema, err := commonavro.NewSchema(0, definition)
+		if err != nil {
+			return nil, fmt.Errorf("parsing avro schema: %v", err)
+		}
+		parser.Schema = schema
+	} else {
+		parser.Registry = commonavro.NewSchemaRegistry(config.AvroSchemaRegistry, 10*time.Second)
+	}
+	return parser, nil
+}
+
 func newGrokParser(metricName string,
 	patterns []string, nPatterns []string,
 	cPatterns string, cPatternFiles []string,
-	tZone string, uniqueTimestamp string) (Parser, error) {
+	tZone string, uniqueTimestamp string, multiline bool) (Parser, error) {
 	parser := grok.Parser{
 		Measurement:        metricName,
 		Patterns:           patterns,
@@ -315,6 +371,7 @@ func newGrokParser(metricName string,
 		CustomPatternFiles: cPatternFiles,
 		Timezone:           tZone,
 		UniqueTimestamp:    uniqueTimestamp,
+		Multiline:          multiline,
 	}
 
 	err := parser.Compile()
@@ -0,0 +1,261 @@
+package avro
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/avro"
+	"github.com/linkedin/goavro/v2"
+)
+
+type serializer struct {
+	// Schema is the record schema metrics are encoded with; when nil the
+	// latest schema of Subject is fetched from the registry on first use.
+	Schema *avro.Schema
+	// Registry is used to resolve the schema ID, may be nil.
+	Registry *avro.SchemaRegistry
+	Subject  string
+
+	// MeasurementField is the record field set to the metric name.
+	MeasurementField string
+	// TimestampField is the record field set to the metric time.
+	TimestampField string
+	// TimestampFormat is one of "unix", "unix_ms", "unix_us" or "unix_ns".
+	TimestampFormat string
+
+	registered bool
+}
+
+func NewSerializer(
+	schema *avro.Schema,
+	registry *avro.SchemaRegistry,
+	subject string,
+	measurementField string,
+	timestampField string,
+	timestampFormat string,
+) (*serializer, error) {
+	if schema == nil && (registry == nil || subject == "") {
+		return nil, fmt.Errorf("either a schema or a schema registry and subject are required")
+	}
+
+	switch timestampFormat {
+	case "":
+		timestampFormat = "unix"
+	case "unix", "unix_ms", "unix_us", "unix_ns":
+	default:
+		return nil, fmt.Errorf("unknown timestamp format %q", timestampFormat)
+	}
+
+	s := &serializer{
+		Schema:           schema,
+		Registry:         registry,
+		Subject:          subject,
+		MeasurementField: measurementField,
+		TimestampField:   timestampField,
+		TimestampFormat:  timestampFormat,
+	}
+	return s, nil
+}
+
+// Serialize encodes the metric as a single message in the schema registry
+// wire format.
+func (s *serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	if err := s.resolveSchema(); err != nil {
+		return nil, err
+	}
+
+	record := make(map[string]interface{})
+	for _, field := range s.Schema.Fields() {
+		var value interface{}
+		switch field.Name {
+		case s.MeasurementField:
+			value = metric.Name()
+		case s.TimestampField:
+			value = metric.Time()
+		default:
+			if v, ok := metric.GetTag(field.Name); ok {
+				value = v
+			} else if v, ok := metric.GetField(field.Name); ok {
+				value = v
+			} else {
+				// Missing values are encoded using the field default.
+				continue
+			}
+		}
+
+		v, err := s.convert(field.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %v", field.Name, err)
+		}
+		record[field.Name] = v
+	}
+
+	payload, err := s.Schema.Codec.BinaryFromNative(nil, record)
+	if err != nil {
+		return nil, err
+	}
+	return avro.EncodeWire(s.Schema.ID, payload), nil
+}
+
+// SerializeBatch concatenates the encoded messages.  The wire format is not
+// self delimiting so outputs should send each metric as a separate message.
+func (s *serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var batch bytes.Buffer
+	for _, metric := range metrics {
+		buf, err := s.Serialize(metric)
+		if err != nil {
+			return nil, err
+		}
+		batch.Write(buf)
+	}
+	return batch.Bytes(), nil
+}
+
+// resolveSchema fetches or registers the schema on first use so that an
+// unavailable registry does not prevent telegraf from starting.
+func (s *serializer) resolveSchema() error {
+	if s.registered || s.Registry == nil || s.Subject == "" {
+		return nil
+	}
+
+	if s.Schema == nil {
+		schema, err := s.Registry.LatestSchema(s.Subject)
+		if err != nil {
+			return err
+		}
+		s.Schema = schema
+	} else {
+		id, err := s.Registry.Register(s.Subject, s.Schema.Codec.Schema())
+		if err != nil {
+			return err
+		}
+		s.Schema.ID = id
+	}
+	s.registered = true
+	return nil
+}
+
+func (s *serializer) timestamp(t time.Time) int64 {
+	switch s.TimestampFormat {
+	case "unix_ms":
+		return t.UnixNano() / int64(time.Millisecond)
+	case "unix_us":
+		return t.UnixNano() / int64(time.Microsecond)
+	case "unix_ns":
+		return t.UnixNano()
+	default:
+		return t.Unix()
+	}
+}
+
+// convert coerces a metric value into the native type goavro expects for the
+// schema type, wrapping union values.
+func (s *serializer) convert(t interface{}, value interface{}) (interface{}, error) {
+	t = s.Schema.Resolve(t)
+	if !avro.IsUnion(t) {
+		return s.coerce(t, value)
+	}
+
+	var lastErr error
+	for _, member := range avro.UnionMembers(t) {
+		member = s.Schema.Resolve(member)
+		v, err := s.coerce(member, value)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return goavro.Union(avro.TypeName(member), v), nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no union member for value %v", value)
+	}
+	return nil, lastErr
+}
+
+func (s *serializer) coerce(t interface{}, value interface{}) (interface{}, error) {
+	typ := avro.TypeName(t)
+	var logical string
+	if m, ok := t.(map[string]interface{}); ok {
+		if base, ok := m["type"].(string); ok {
+			typ = base
+		}
+		logical, _ = m["logicalType"].(string)
+	}
+
+	// Timestamps are passed as time.Time to the timestamp logical types and
+	// converted using the timestamp format otherwise.
+	if tm, ok := value.(time.Time); ok {
+		if logical == "timestamp-millis" || logical == "timestamp-micros" {
+			return tm, nil
+		}
+		value = s.timestamp(tm)
+	}
+
+	switch typ {
+	case "string", "enum":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		default:
+			return fmt.Sprintf("%v", v), nil
+		}
+	case "bytes":
+		return []byte(fmt.Sprintf("%v", value)), nil
+	case "boolean":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			return strconv.ParseBool(v)
+		}
+	case "int":
+		n, err := toInt(value)
+		return int32(n), err
+	case "long":
+		return toInt(value)
+	case "float":
+		f, err := toFloat(value)
+		return float32(f), err
+	case "double":
+		return toFloat(value)
+	}
+	return nil, fmt.Errorf("cannot convert %T to %s", value, typ)
+}
+
+func toInt(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case uint64:
+		return int64(v), nil
+	case float64:
+		return int64(v), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	}
+	return 0, fmt.Errorf("cannot convert %T to integer", value)
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+	return 0, fmt.Errorf("cannot convert %T to float", value)
+}
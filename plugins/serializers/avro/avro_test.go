@@ -0,0 +1,182 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/plugins/common/avro"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+)
+
+const schemaDefinition = `
+{
+	"type": "record",
+	"name": "cpu",
+	"fields": [
+		{"name": "measurement", "type": "string"},
+		{"name": "time", "type": "long"},
+		{"name": "host", "type": "string"},
+		{"name": "usage_idle", "type": "float"},
+		{"name": "count", "type": ["null", "int"], "default": null},
+		{"name": "state", "type": "string", "default": "unknown"}
+	]
+}`
+
+func decode(t *testing.T, buf []byte) (int, map[string]interface{}) {
+	id, payload, err := avro.DecodeWire(buf)
+	require.NoError(t, err)
+
+	codec, err := goavro.NewCodec(schemaDefinition)
+	require.NoError(t, err)
+	native, rest, err := codec.NativeFromBinary(payload)
+	require.NoError(t, err)
+	require.Empty(t, rest)
+	return id, native.(map[string]interface{})
+}
+
+func TestSerialize(t *testing.T) {
+	schema, err := avro.NewSchema(7, schemaDefinition)
+	require.NoError(t, err)
+
+	s, err := NewSerializer(schema, nil, "", "measurement", "time", "unix_ms")
+	require.NoError(t, err)
+
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{
+			"host": "a",
+		},
+		map[string]interface{}{
+			"usage_idle": 91.5,
+			"count":      int64(3),
+		},
+		time.Unix(1525478795, 123456789),
+	)
+
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	id, record := decode(t, buf)
+	require.Equal(t, 7, id)
+	require.Equal(t, map[string]interface{}{
+		"measurement": "cpu",
+		"time":        int64(1525478795123),
+		"host":        "a",
+		"usage_idle":  float32(91.5),
+		"count":       map[string]interface{}{"int": int32(3)},
+		"state":       "unknown",
+	}, record)
+}
+
+func TestSerializeMissingNullable(t *testing.T) {
+	schema, err := avro.NewSchema(0, schemaDefinition)
+	require.NoError(t, err)
+
+	s, err := NewSerializer(schema, nil, "", "measurement", "time", "")
+	require.NoError(t, err)
+
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{
+			"host": "a",
+		},
+		map[string]interface{}{
+			"usage_idle": int64(90),
+		},
+		time.Unix(1525478795, 0),
+	)
+
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	_, record := decode(t, buf)
+	require.Nil(t, record["count"])
+	require.Equal(t, int64(1525478795), record["time"])
+	require.Equal(t, float32(90), record["usage_idle"])
+}
+
+func TestSerializeRequiredFieldMissing(t *testing.T) {
+	schema, err := avro.NewSchema(0, schemaDefinition)
+	require.NoError(t, err)
+
+	s, err := NewSerializer(schema, nil, "", "measurement", "time", "")
+	require.NoError(t, err)
+
+	_, err = s.Serialize(testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"usage_idle": 1.0,
+		},
+		time.Unix(0, 0),
+	))
+	require.Error(t, err)
+}
+
+func TestSerializeRegistry(t *testing.T) {
+	var registered string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/subjects/cpu-value/versions":
+			var body struct {
+				Schema string `json:"schema"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			registered = body.Schema
+			fmt.Fprint(w, `{"id": 21}`)
+		case r.Method == "GET" && r.URL.Path == "/subjects/cpu-value/versions/latest":
+			fmt.Fprintf(w, `{"subject": "cpu-value", "version": 1, "id": 22, "schema": %q}`, schemaDefinition)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{
+			"host": "a",
+		},
+		map[string]interface{}{
+			"usage_idle": 1.0,
+		},
+		time.Unix(0, 0),
+	)
+
+	registry := avro.NewSchemaRegistry(ts.URL, 5*time.Second)
+
+	// Register the local schema to get its ID.
+	schema, err := avro.NewSchema(0, schemaDefinition)
+	require.NoError(t, err)
+	s, err := NewSerializer(schema, registry, "cpu-value", "measurement", "time", "")
+	require.NoError(t, err)
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	id, _ := decode(t, buf)
+	require.Equal(t, 21, id)
+	require.NotEmpty(t, registered)
+
+	// Fetch the latest schema of the subject.
+	s, err = NewSerializer(nil, registry, "cpu-value", "measurement", "time", "")
+	require.NoError(t, err)
+	buf, err = s.Serialize(m)
+	require.NoError(t, err)
+	id, _ = decode(t, buf)
+	require.Equal(t, 22, id)
+}
+
+func TestNewSerializerErrors(t *testing.T) {
+	_, err := NewSerializer(nil, nil, "", "", "", "")
+	require.Error(t, err)
+
+	schema, err := avro.NewSchema(0, schemaDefinition)
+	require.NoError(t, err)
+	_, err = NewSerializer(schema, nil, "", "", "", "rfc3339")
+	require.Error(t, err)
+}
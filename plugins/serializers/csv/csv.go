@@ -0,0 +1,221 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/influxdata/telegraf"
+)
+
+type serializer struct {
+	TimestampFormat string
+	Separator       rune
+	Header          bool
+	Prefix          bool
+
+	// columns of the records written so far, sorted by key
+	tagColumns   []string
+	fieldColumns []string
+}
+
+// NewSerializer creates a CSV serializer.  The timestamp format may be one of
+// "unix", "unix_ms", "unix_us", "unix_ns" or a Go reference time layout; an
+// empty separator defaults to a comma.
+func NewSerializer(timestampFormat string, separator string, header bool, prefix bool) (*serializer, error) {
+	if timestampFormat == "" {
+		timestampFormat = "unix"
+	}
+
+	sep := ','
+	if separator != "" {
+		r, size := utf8.DecodeRuneInString(separator)
+		if size != len(separator) {
+			return nil, fmt.Errorf("invalid separator %q: must be a single character", separator)
+		}
+		switch r {
+		case '\r', '\n', '"', utf8.RuneError:
+			return nil, fmt.Errorf("invalid separator %q", separator)
+		}
+		sep = r
+	}
+
+	s := &serializer{
+		TimestampFormat: timestampFormat,
+		Separator:       sep,
+		Header:          header,
+		Prefix:          prefix,
+	}
+	return s, nil
+}
+
+func (s *serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.SerializeBatch([]telegraf.Metric{metric})
+}
+
+// SerializeBatch writes one row per metric.  When the header is enabled it is
+// written ahead of the first row produced by the serializer.
+//
+// Columns are ordered as timestamp, measurement, tags sorted by key and then
+// fields sorted by key.  The tag and field columns are taken from the first
+// metric serialized; later metrics leave the cells of missing tags and fields
+// empty.  A metric with a tag or field that has no column adds the missing
+// columns and, when the header is enabled, a new header row is written ahead
+// of its record.  The columns are only updated once the batch is serialized.
+func (s *serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = s.Separator
+
+	tagColumns, fieldColumns := s.tagColumns, s.fieldColumns
+	for _, metric := range metrics {
+		tags, fields := tagKeys(metric), fieldKeys(metric)
+		if tagColumns == nil || !hasColumns(tagColumns, tags) || !hasColumns(fieldColumns, fields) {
+			tagColumns = mergeColumns(tagColumns, tags)
+			fieldColumns = mergeColumns(fieldColumns, fields)
+			if s.Header {
+				if err := w.Write(s.header(tagColumns, fieldColumns)); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		record, err := s.record(metric, tagColumns, fieldColumns)
+		if err != nil {
+			return nil, err
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	s.tagColumns, s.fieldColumns = tagColumns, fieldColumns
+	return buf.Bytes(), nil
+}
+
+func tagKeys(metric telegraf.Metric) []string {
+	keys := make([]string, 0, len(metric.TagList()))
+	for _, tag := range metric.TagList() {
+		keys = append(keys, tag.Key)
+	}
+	return keys
+}
+
+func fieldKeys(metric telegraf.Metric) []string {
+	keys := make([]string, 0, len(metric.FieldList()))
+	for _, field := range metric.FieldList() {
+		keys = append(keys, field.Key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hasColumns reports if all keys have a column; both slices must be sorted.
+func hasColumns(columns, keys []string) bool {
+	for _, key := range keys {
+		i := sort.SearchStrings(columns, key)
+		if i == len(columns) || columns[i] != key {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeColumns returns the sorted union of the columns and keys as a new
+// slice, so the columns of earlier batches are never modified.
+func mergeColumns(columns, keys []string) []string {
+	merged := make([]string, 0, len(columns)+len(keys))
+	merged = append(merged, columns...)
+	for _, key := range keys {
+		if !hasColumns(columns, []string{key}) {
+			merged = append(merged, key)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+func (s *serializer) record(metric telegraf.Metric, tagColumns, fieldColumns []string) ([]string, error) {
+	record := make([]string, 0, 2+len(tagColumns)+len(fieldColumns))
+	record = append(record, s.formatTimestamp(metric.Time()))
+	record = append(record, metric.Name())
+	for _, key := range tagColumns {
+		value, _ := metric.GetTag(key)
+		record = append(record, value)
+	}
+	for _, key := range fieldColumns {
+		value, ok := metric.GetField(key)
+		if !ok {
+			record = append(record, "")
+			continue
+		}
+
+		v, err := formatField(value)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %v", key, err)
+		}
+		record = append(record, v)
+	}
+	return record, nil
+}
+
+func (s *serializer) header(tagColumns, fieldColumns []string) []string {
+	columns := make([]string, 0, 2+len(tagColumns)+len(fieldColumns))
+	columns = append(columns, "timestamp", "measurement")
+	for _, key := range tagColumns {
+		if s.Prefix {
+			columns = append(columns, "tag_"+key)
+		} else {
+			columns = append(columns, key)
+		}
+	}
+	for _, key := range fieldColumns {
+		if s.Prefix {
+			columns = append(columns, "field_"+key)
+		} else {
+			columns = append(columns, key)
+		}
+	}
+	return columns
+}
+
+func (s *serializer) formatTimestamp(t time.Time) string {
+	switch s.TimestampFormat {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unix_ms":
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+	case "unix_us":
+		return strconv.FormatInt(t.UnixNano()/int64(time.Microsecond), 10)
+	case "unix_ns":
+		return strconv.FormatInt(t.UnixNano(), 10)
+	default:
+		return t.UTC().Format(s.TimestampFormat)
+	}
+}
+
+func formatField(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("unsupported type %T", v)
+	}
+}
@@ -0,0 +1,141 @@
+package csv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialize(t *testing.T) {
+	tests := []struct {
+		name            string
+		timestampFormat string
+		separator       string
+		header          bool
+		prefix          bool
+		expected        string
+	}{
+		{
+			name:     "defaults",
+			expected: "1525478795,cpu,a,cpu0,10,91.5,true\n",
+		},
+		{
+			name:     "header",
+			header:   true,
+			expected: "timestamp,measurement,cpu,host,count,usage_idle,valid\n1525478795,cpu,a,cpu0,10,91.5,true\n",
+		},
+		{
+			name:     "header with prefix",
+			header:   true,
+			prefix:   true,
+			expected: "timestamp,measurement,tag_cpu,tag_host,field_count,field_usage_idle,field_valid\n1525478795,cpu,a,cpu0,10,91.5,true\n",
+		},
+		{
+			name:      "semicolon separator",
+			separator: ";",
+			expected:  "1525478795;cpu;a;cpu0;10;91.5;true\n",
+		},
+		{
+			name:            "unix_ms",
+			timestampFormat: "unix_ms",
+			expected:        "1525478795123,cpu,a,cpu0,10,91.5,true\n",
+		},
+		{
+			name:            "layout",
+			timestampFormat: time.RFC3339,
+			expected:        "2018-05-05T00:06:35Z,cpu,a,cpu0,10,91.5,true\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testutil.MustMetric(
+				"cpu",
+				map[string]string{
+					"host": "cpu0",
+					"cpu":  "a",
+				},
+				map[string]interface{}{
+					"valid":      true,
+					"usage_idle": 91.5,
+					"count":      int64(10),
+				},
+				time.Unix(1525478795, 123456789),
+			)
+
+			s, err := NewSerializer(tt.timestampFormat, tt.separator, tt.header, tt.prefix)
+			require.NoError(t, err)
+
+			buf, err := s.Serialize(m)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(buf))
+		})
+	}
+}
+
+func TestSerializeBatchHeaderOnce(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"value": "with,comma",
+		},
+		time.Unix(0, 0),
+	)
+
+	s, err := NewSerializer("", "", true, false)
+	require.NoError(t, err)
+
+	buf, err := s.SerializeBatch([]telegraf.Metric{m, m})
+	require.NoError(t, err)
+	require.Equal(t, "timestamp,measurement,value\n0,cpu,\"with,comma\"\n0,cpu,\"with,comma\"\n", string(buf))
+
+	buf, err = s.SerializeBatch([]telegraf.Metric{m})
+	require.NoError(t, err)
+	require.Equal(t, "0,cpu,\"with,comma\"\n", string(buf))
+}
+
+func TestSerializeNewColumns(t *testing.T) {
+	s, err := NewSerializer("", "", true, false)
+	require.NoError(t, err)
+
+	first := testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"idle": 1.5, "user": 2.5},
+		time.Unix(0, 0),
+	)
+	missing := testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{"user": 3.5},
+		time.Unix(0, 0),
+	)
+	buf, err := s.SerializeBatch([]telegraf.Metric{first, missing})
+	require.NoError(t, err)
+	require.Equal(t, "timestamp,measurement,host,idle,user\n0,cpu,a,1.5,2.5\n0,cpu,,,3.5\n", string(buf))
+
+	extra := testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"idle": 1.5, "system": 4.5},
+		time.Unix(0, 0),
+	)
+	buf, err = s.SerializeBatch([]telegraf.Metric{extra, missing})
+	require.NoError(t, err)
+	require.Equal(t, "timestamp,measurement,host,idle,system,user\n0,cpu,a,1.5,4.5,\n0,cpu,,,,3.5\n", string(buf))
+
+	buf, err = s.Serialize(first)
+	require.NoError(t, err)
+	require.Equal(t, "0,cpu,a,1.5,,2.5\n", string(buf))
+}
+
+func TestInvalidSeparator(t *testing.T) {
+	_, err := NewSerializer("", ";;", false, false)
+	require.Error(t, err)
+
+	_, err = NewSerializer("", "\n", false, false)
+	require.Error(t, err)
+}
@@ -1,20 +1,107 @@
 package json
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"math"
 	"time"
 
 	"github.com/influxdata/telegraf"
 )
 
-type serializer struct {
+// BatchFormat selects how SerializeBatch lays out multiple metrics.
+type BatchFormat string
+
+const (
+	// WrappedBatch places the metrics in an array under a single key.
+	WrappedBatch BatchFormat = "wrapped"
+	// ArrayBatch writes the metrics as a top level array.
+	ArrayBatch BatchFormat = "array"
+	// NDJSONBatch writes one object per line.
+	NDJSONBatch BatchFormat = "ndjson"
+)
+
+// FormatConfig controls the layout of the serialized metrics.  Empty key
+// names and formats are replaced with their defaults.
+type FormatConfig struct {
+	// TimestampUnits is the resolution of numeric timestamps when no
+	// TimestampFormat is set.
 	TimestampUnits time.Duration
+	// TimestampFormat is one of "unix", "unix_ms", "unix_us", "unix_ns" or a
+	// Go reference time layout; layouts produce string timestamps in UTC.
+	TimestampFormat string
+
+	NameKey      string
+	TimestampKey string
+	TagsKey      string
+	FieldsKey    string
+
+	// FlattenTags and FlattenFields move the tags and fields to the top level
+	// object instead of nesting them under TagsKey and FieldsKey.
+	FlattenTags   bool
+	FlattenFields bool
+
+	BatchFormat BatchFormat
+	BatchKey    string
+}
+
+type serializer struct {
+	TimestampUnits  time.Duration
+	TimestampFormat string
+
+	NameKey      string
+	TimestampKey string
+	TagsKey      string
+	FieldsKey    string
+
+	FlattenTags   bool
+	FlattenFields bool
+
+	BatchFormat BatchFormat
+	BatchKey    string
 }
 
 func NewSerializer(timestampUnits time.Duration) (*serializer, error) {
+	return NewSerializerConfig(FormatConfig{TimestampUnits: timestampUnits})
+}
+
+func NewSerializerConfig(config FormatConfig) (*serializer, error) {
 	s := &serializer{
-		TimestampUnits: truncateDuration(timestampUnits),
+		TimestampUnits:  truncateDuration(config.TimestampUnits),
+		TimestampFormat: config.TimestampFormat,
+		NameKey:         config.NameKey,
+		TimestampKey:    config.TimestampKey,
+		TagsKey:         config.TagsKey,
+		FieldsKey:       config.FieldsKey,
+		FlattenTags:     config.FlattenTags,
+		FlattenFields:   config.FlattenFields,
+		BatchFormat:     config.BatchFormat,
+		BatchKey:        config.BatchKey,
+	}
+
+	if s.NameKey == "" {
+		s.NameKey = "name"
+	}
+	if s.TimestampKey == "" {
+		s.TimestampKey = "timestamp"
+	}
+	if s.TagsKey == "" {
+		s.TagsKey = "tags"
+	}
+	if s.FieldsKey == "" {
+		s.FieldsKey = "fields"
+	}
+	if s.BatchKey == "" {
+		s.BatchKey = "metrics"
+	}
+
+	switch s.BatchFormat {
+	case "":
+		s.BatchFormat = WrappedBatch
+	case WrappedBatch, ArrayBatch, NDJSONBatch:
+	default:
+		return nil, fmt.Errorf("unknown batch format %q", s.BatchFormat)
 	}
 	return s, nil
 }
@@ -31,14 +118,29 @@ func (s *serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 }
 
 func (s *serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	if s.BatchFormat == NDJSONBatch {
+		var buf bytes.Buffer
+		for _, metric := range metrics {
+			serialized, err := s.Serialize(metric)
+			if err != nil {
+				return []byte{}, err
+			}
+			buf.Write(serialized)
+		}
+		return buf.Bytes(), nil
+	}
+
 	objects := make([]interface{}, 0, len(metrics))
 	for _, metric := range metrics {
 		m := s.createObject(metric)
 		objects = append(objects, m)
 	}
 
-	obj := map[string]interface{}{
-		"metrics": objects,
+	var obj interface{} = objects
+	if s.BatchFormat == WrappedBatch {
+		obj = map[string]interface{}{
+			s.BatchKey: objects,
+		}
 	}
 
 	serialized, err := json.Marshal(obj)
@@ -48,16 +150,27 @@ func (s *serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 	return serialized, nil
 }
 
+// createObject builds the JSON object for a metric.  Objects are maps so the
+// keys are always encoded in sorted order.  When flattening, fields take
+// precedence over tags with the same key and the name and timestamp keys take
+// precedence over both.
 func (s *serializer) createObject(metric telegraf.Metric) map[string]interface{} {
 	m := make(map[string]interface{}, 4)
 
-	tags := make(map[string]string, len(metric.TagList()))
+	tags := m
+	if !s.FlattenTags {
+		tags = make(map[string]interface{}, len(metric.TagList()))
+		m[s.TagsKey] = tags
+	}
 	for _, tag := range metric.TagList() {
 		tags[tag.Key] = tag.Value
 	}
-	m["tags"] = tags
 
-	fields := make(map[string]interface{}, len(metric.FieldList()))
+	fields := m
+	if !s.FlattenFields {
+		fields = make(map[string]interface{}, len(metric.FieldList()))
+		m[s.FieldsKey] = fields
+	}
 	for _, field := range metric.FieldList() {
 		switch fv := field.Value.(type) {
 		case float64:
@@ -68,13 +181,29 @@ func (s *serializer) createObject(metric telegraf.Metric) map[string]interface{}
 		}
 		fields[field.Key] = field.Value
 	}
-	m["fields"] = fields
 
-	m["name"] = metric.Name()
-	m["timestamp"] = metric.Time().UnixNano() / int64(s.TimestampUnits)
+	m[s.NameKey] = metric.Name()
+	m[s.TimestampKey] = s.timestamp(metric.Time())
 	return m
 }
 
+func (s *serializer) timestamp(t time.Time) interface{} {
+	switch s.TimestampFormat {
+	case "":
+		return t.UnixNano() / int64(s.TimestampUnits)
+	case "unix":
+		return t.Unix()
+	case "unix_ms":
+		return t.UnixNano() / int64(time.Millisecond)
+	case "unix_us":
+		return t.UnixNano() / int64(time.Microsecond)
+	case "unix_ns":
+		return t.UnixNano()
+	default:
+		return t.UTC().Format(s.TimestampFormat)
+	}
+}
+
 func truncateDuration(units time.Duration) time.Duration {
 	// Default precision is 1s
 	if units <= 0 {
@@ -233,3 +233,152 @@ func TestSerializeBatchSkipInfAllFields(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []byte(`{"metrics":[{"fields":{},"name":"cpu","tags":{},"timestamp":0}]}`), buf)
 }
+
+func TestSerializeLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   FormatConfig
+		expected string
+	}{
+		{
+			name:     "defaults",
+			expected: `{"fields":{"value":42},"name":"cpu","tags":{"host":"a"},"timestamp":1525478795}`,
+		},
+		{
+			name: "renamed keys",
+			config: FormatConfig{
+				NameKey:      "measurement",
+				TimestampKey: "time",
+				TagsKey:      "labels",
+				FieldsKey:    "values",
+			},
+			expected: `{"labels":{"host":"a"},"measurement":"cpu","time":1525478795,"values":{"value":42}}`,
+		},
+		{
+			name: "flatten tags",
+			config: FormatConfig{
+				FlattenTags: true,
+			},
+			expected: `{"fields":{"value":42},"host":"a","name":"cpu","timestamp":1525478795}`,
+		},
+		{
+			name: "flatten tags and fields",
+			config: FormatConfig{
+				FlattenTags:   true,
+				FlattenFields: true,
+			},
+			expected: `{"host":"a","name":"cpu","timestamp":1525478795,"value":42}`,
+		},
+		{
+			name: "unix_ms format",
+			config: FormatConfig{
+				TimestampFormat: "unix_ms",
+			},
+			expected: `{"fields":{"value":42},"name":"cpu","tags":{"host":"a"},"timestamp":1525478795123}`,
+		},
+		{
+			name: "layout format",
+			config: FormatConfig{
+				TimestampFormat: "2006-01-02T15:04:05.000Z07:00",
+			},
+			expected: `{"fields":{"value":42},"name":"cpu","tags":{"host":"a"},"timestamp":"2018-05-05T00:06:35.123Z"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testutil.MustMetric(
+				"cpu",
+				map[string]string{
+					"host": "a",
+				},
+				map[string]interface{}{
+					"value": 42.0,
+				},
+				time.Unix(1525478795, 123456789),
+			)
+			s, err := NewSerializerConfig(tt.config)
+			require.NoError(t, err)
+			actual, err := s.Serialize(m)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected+"\n", string(actual))
+		})
+	}
+}
+
+func TestSerializeFlattenPrecedence(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{
+			"name":  "tag",
+			"value": "tag",
+		},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+	s, err := NewSerializerConfig(FormatConfig{FlattenTags: true, FlattenFields: true})
+	require.NoError(t, err)
+	actual, err := s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"cpu","timestamp":0,"value":42}`+"\n", string(actual))
+}
+
+func TestSerializeBatchFormat(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+	metrics := []telegraf.Metric{m, m}
+
+	tests := []struct {
+		name     string
+		config   FormatConfig
+		expected string
+	}{
+		{
+			name:     "wrapped",
+			expected: `{"metrics":[{"fields":{"value":42},"name":"cpu","tags":{},"timestamp":0},{"fields":{"value":42},"name":"cpu","tags":{},"timestamp":0}]}`,
+		},
+		{
+			name: "wrapped with key",
+			config: FormatConfig{
+				BatchKey: "data",
+			},
+			expected: `{"data":[{"fields":{"value":42},"name":"cpu","tags":{},"timestamp":0},{"fields":{"value":42},"name":"cpu","tags":{},"timestamp":0}]}`,
+		},
+		{
+			name: "array",
+			config: FormatConfig{
+				BatchFormat: ArrayBatch,
+			},
+			expected: `[{"fields":{"value":42},"name":"cpu","tags":{},"timestamp":0},{"fields":{"value":42},"name":"cpu","tags":{},"timestamp":0}]`,
+		},
+		{
+			name: "ndjson",
+			config: FormatConfig{
+				BatchFormat: NDJSONBatch,
+			},
+			expected: `{"fields":{"value":42},"name":"cpu","tags":{},"timestamp":0}` + "\n" +
+				`{"fields":{"value":42},"name":"cpu","tags":{},"timestamp":0}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSerializerConfig(tt.config)
+			require.NoError(t, err)
+			buf, err := s.SerializeBatch(metrics)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(buf))
+		})
+	}
+}
+
+func TestInvalidBatchFormat(t *testing.T) {
+	_, err := NewSerializerConfig(FormatConfig{BatchFormat: "xml"})
+	require.Error(t, err)
+}
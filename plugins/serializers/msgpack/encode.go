@@ -0,0 +1,137 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// encoder appends MessagePack encoded values to a byte slice.  Only the
+// subset of the specification required to represent a telegraf metric is
+// implemented.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) writeNil() {
+	e.buf = append(e.buf, 0xc0)
+}
+
+func (e *encoder) writeBool(v bool) {
+	if v {
+		e.buf = append(e.buf, 0xc3)
+	} else {
+		e.buf = append(e.buf, 0xc2)
+	}
+}
+
+func (e *encoder) writeInt(v int64) {
+	switch {
+	case v >= 0:
+		e.writeUint(uint64(v))
+	case v >= -32:
+		e.buf = append(e.buf, byte(v))
+	case v >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		e.buf = append(e.buf, 0xd1)
+		e.buf = appendUint16(e.buf, uint16(v))
+	case v >= math.MinInt32:
+		e.buf = append(e.buf, 0xd2)
+		e.buf = appendUint32(e.buf, uint32(v))
+	default:
+		e.buf = append(e.buf, 0xd3)
+		e.buf = appendUint64(e.buf, uint64(v))
+	}
+}
+
+func (e *encoder) writeUint(v uint64) {
+	switch {
+	case v <= 0x7f:
+		e.buf = append(e.buf, byte(v))
+	case v <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		e.buf = append(e.buf, 0xcd)
+		e.buf = appendUint16(e.buf, uint16(v))
+	case v <= math.MaxUint32:
+		e.buf = append(e.buf, 0xce)
+		e.buf = appendUint32(e.buf, uint32(v))
+	default:
+		e.buf = append(e.buf, 0xcf)
+		e.buf = appendUint64(e.buf, v)
+	}
+}
+
+func (e *encoder) writeFloat(v float64) {
+	e.buf = append(e.buf, 0xcb)
+	e.buf = appendUint64(e.buf, math.Float64bits(v))
+}
+
+func (e *encoder) writeString(v string) {
+	n := len(v)
+	switch {
+	case n < 32:
+		e.buf = append(e.buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xda)
+		e.buf = appendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdb)
+		e.buf = appendUint32(e.buf, uint32(n))
+	}
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) writeMapHeader(n int) {
+	switch {
+	case n < 16:
+		e.buf = append(e.buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xde)
+		e.buf = appendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdf)
+		e.buf = appendUint32(e.buf, uint32(n))
+	}
+}
+
+// writeTime encodes t using the timestamp extension type (-1), choosing the
+// smallest of the 32, 64 and 96 bit representations that can hold the value.
+func (e *encoder) writeTime(t time.Time) {
+	sec := t.Unix()
+	nsec := int64(t.Nanosecond())
+
+	switch {
+	case sec>>34 == 0 && nsec == 0 && sec <= math.MaxUint32:
+		e.buf = append(e.buf, 0xd6, 0xff)
+		e.buf = appendUint32(e.buf, uint32(sec))
+	case sec>>34 == 0:
+		e.buf = append(e.buf, 0xd7, 0xff)
+		e.buf = appendUint64(e.buf, uint64(nsec)<<34|uint64(sec))
+	default:
+		e.buf = append(e.buf, 0xc7, 12, 0xff)
+		e.buf = appendUint32(e.buf, uint32(nsec))
+		e.buf = appendUint64(e.buf, uint64(sec))
+	}
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	var tmp [2]byte
+	binary.BigEndian.PutUint16(tmp[:], v)
+	return append(b, tmp[:]...)
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	var tmp [4]byte
+	binary.BigEndian.PutUint32(tmp[:], v)
+	return append(b, tmp[:]...)
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], v)
+	return append(b, tmp[:]...)
+}
@@ -0,0 +1,86 @@
+package msgpack
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/telegraf"
+)
+
+type serializer struct {
+}
+
+func NewSerializer() (*serializer, error) {
+	s := &serializer{}
+	return s, nil
+}
+
+// Serialize encodes a single metric as a MessagePack map with the keys
+// "name", "time", "tags" and "fields".
+func (s *serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	e := &encoder{}
+	if err := s.writeMetric(e, metric); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// SerializeBatch encodes each metric in turn; the result is a stream of
+// MessagePack maps that can be read back one object at a time.
+func (s *serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	e := &encoder{}
+	for _, metric := range metrics {
+		if err := s.writeMetric(e, metric); err != nil {
+			return nil, err
+		}
+	}
+	return e.buf, nil
+}
+
+func (s *serializer) writeMetric(e *encoder, metric telegraf.Metric) error {
+	e.writeMapHeader(4)
+
+	e.writeString("name")
+	e.writeString(metric.Name())
+
+	e.writeString("time")
+	e.writeTime(metric.Time())
+
+	e.writeString("tags")
+	tags := metric.TagList()
+	e.writeMapHeader(len(tags))
+	for _, tag := range tags {
+		e.writeString(tag.Key)
+		e.writeString(tag.Value)
+	}
+
+	// Sort the fields so the output is stable between calls.
+	fields := make([]*telegraf.Field, len(metric.FieldList()))
+	copy(fields, metric.FieldList())
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Key < fields[j].Key
+	})
+
+	e.writeString("fields")
+	e.writeMapHeader(len(fields))
+	for _, field := range fields {
+		e.writeString(field.Key)
+		switch v := field.Value.(type) {
+		case float64:
+			e.writeFloat(v)
+		case int64:
+			e.writeInt(v)
+		case uint64:
+			e.writeUint(v)
+		case string:
+			e.writeString(v)
+		case bool:
+			e.writeBool(v)
+		case nil:
+			e.writeNil()
+		default:
+			return fmt.Errorf("unsupported type %T for field %q", v, field.Key)
+		}
+	}
+	return nil
+}
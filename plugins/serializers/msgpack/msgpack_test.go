@@ -0,0 +1,163 @@
+package msgpack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialize(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{
+			"host": "a",
+		},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(1, 0),
+	)
+
+	s, err := NewSerializer()
+	require.NoError(t, err)
+
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	expected := []byte{
+		0x84,
+		0xa4, 'n', 'a', 'm', 'e', 0xa3, 'c', 'p', 'u',
+		0xa4, 't', 'i', 'm', 'e', 0xd6, 0xff, 0x00, 0x00, 0x00, 0x01,
+		0xa4, 't', 'a', 'g', 's', 0x81, 0xa4, 'h', 'o', 's', 't', 0xa1, 'a',
+		0xa6, 'f', 'i', 'e', 'l', 'd', 's', 0x81,
+		0xa5, 'v', 'a', 'l', 'u', 'e', 0xcb, 0x40, 0x45, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	require.Equal(t, expected, buf)
+}
+
+func TestSerializeBatch(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"value": int64(1),
+		},
+		time.Unix(0, 0),
+	)
+
+	s, err := NewSerializer()
+	require.NoError(t, err)
+
+	single, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	batch, err := s.SerializeBatch([]telegraf.Metric{m, m})
+	require.NoError(t, err)
+	require.Equal(t, append(single, single...), batch)
+}
+
+func TestEncodeFieldTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected []byte
+	}{
+		{
+			name:     "positive fixint",
+			value:    int64(7),
+			expected: []byte{0x07},
+		},
+		{
+			name:     "negative fixint",
+			value:    int64(-1),
+			expected: []byte{0xff},
+		},
+		{
+			name:     "int16",
+			value:    int64(-300),
+			expected: []byte{0xd1, 0xfe, 0xd4},
+		},
+		{
+			name:     "uint32",
+			value:    uint64(70000),
+			expected: []byte{0xce, 0x00, 0x01, 0x11, 0x70},
+		},
+		{
+			name:     "bool",
+			value:    true,
+			expected: []byte{0xc3},
+		},
+		{
+			name:     "string",
+			value:    "ok",
+			expected: []byte{0xa2, 'o', 'k'},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testutil.MustMetric(
+				"m",
+				map[string]string{},
+				map[string]interface{}{
+					"f": tt.value,
+				},
+				time.Unix(0, 0),
+			)
+
+			s, err := NewSerializer()
+			require.NoError(t, err)
+
+			buf, err := s.Serialize(m)
+			require.NoError(t, err)
+
+			// Skip the name, time, tags and field key preamble.
+			prefix := []byte{
+				0x84,
+				0xa4, 'n', 'a', 'm', 'e', 0xa1, 'm',
+				0xa4, 't', 'i', 'm', 'e', 0xd6, 0xff, 0x00, 0x00, 0x00, 0x00,
+				0xa4, 't', 'a', 'g', 's', 0x80,
+				0xa6, 'f', 'i', 'e', 'l', 'd', 's', 0x81, 0xa1, 'f',
+			}
+			require.Equal(t, prefix, buf[:len(prefix)])
+			require.Equal(t, tt.expected, buf[len(prefix):])
+		})
+	}
+}
+
+func TestEncodeTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		time     time.Time
+		expected []byte
+	}{
+		{
+			name:     "timestamp 32",
+			time:     time.Unix(1, 0),
+			expected: []byte{0xd6, 0xff, 0x00, 0x00, 0x00, 0x01},
+		},
+		{
+			name:     "timestamp 64",
+			time:     time.Unix(1, 1),
+			expected: []byte{0xd7, 0xff, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01},
+		},
+		{
+			name: "timestamp 96",
+			time: time.Unix(-1, 0),
+			expected: []byte{
+				0xc7, 0x0c, 0xff,
+				0x00, 0x00, 0x00, 0x00,
+				0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &encoder{}
+			e.writeTime(tt.time)
+			require.Equal(t, tt.expected, e.buf)
+		})
+	}
+}
@@ -2,16 +2,22 @@ package serializers
 
 import (
 	"fmt"
+	"io/ioutil"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	commonavro "github.com/influxdata/telegraf/plugins/common/avro"
+	"github.com/influxdata/telegraf/plugins/serializers/avro"
 	"github.com/influxdata/telegraf/plugins/serializers/carbon2"
+	"github.com/influxdata/telegraf/plugins/serializers/csv"
 	"github.com/influxdata/telegraf/plugins/serializers/graphite"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/plugins/serializers/json"
+	"github.com/influxdata/telegraf/plugins/serializers/msgpack"
 	"github.com/influxdata/telegraf/plugins/serializers/nowmetric"
 	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
 	"github.com/influxdata/telegraf/plugins/serializers/splunkmetric"
+	"github.com/influxdata/telegraf/plugins/serializers/template"
 	"github.com/influxdata/telegraf/plugins/serializers/wavefront"
 )
 
@@ -64,16 +70,39 @@ type Config struct {
 	// Prefix to add to all measurements, only supports Graphite
 	Prefix string `toml:"prefix"`
 
-	// Template for converting telegraf metrics into Graphite
-	// only supports Graphite
+	// Template for converting telegraf metrics into Graphite, or the Go
+	// template rendering each metric for the template format
 	Template string `toml:"template"`
 
+	// Go template rendering a whole batch; template format only
+	BatchTemplate string `toml:"batch_template"`
+
 	// Templates same Template, but multiple
 	Templates []string `toml:"templates"`
 
 	// Timestamp units to use for JSON formatted output
 	TimestampUnits time.Duration `toml:"timestamp_units"`
 
+	// Timestamp format for JSON output; "unix", "unix_ms", "unix_us",
+	// "unix_ns" or a Go time layout.  Overrides TimestampUnits when set.
+	JSONTimestampFormat string `toml:"json_timestamp_format"`
+
+	// Key names used in JSON output objects
+	JSONNameKey      string `toml:"json_name_key"`
+	JSONTimestampKey string `toml:"json_timestamp_key"`
+	JSONTagsKey      string `toml:"json_tags_key"`
+	JSONFieldsKey    string `toml:"json_fields_key"`
+
+	// Place the tags and fields at the top level of JSON output objects
+	JSONFlattenTags   bool `toml:"json_flatten_tags"`
+	JSONFlattenFields bool `toml:"json_flatten_fields"`
+
+	// Layout of JSON batches; "wrapped", "array" or "ndjson"
+	JSONBatchFormat string `toml:"json_batch_format"`
+
+	// Key holding the metrics in wrapped JSON batches
+	JSONBatchKey string `toml:"json_batch_key"`
+
 	// Include HEC routing fields for splunkmetric output
 	HecRouting bool `toml:"hec_routing"`
 
@@ -97,6 +126,38 @@ type Config struct {
 	// Output string fields as metric labels; when false string fields are
 	// discarded.
 	PrometheusStringAsLabel bool `toml:"prometheus_string_as_label"`
+
+	// Timestamp format for CSV output; "unix", "unix_ms", "unix_us",
+	// "unix_ns" or a Go time layout.
+	CSVTimestampFormat string `toml:"csv_timestamp_format"`
+
+	// Column separator for CSV output.
+	CSVSeparator string `toml:"csv_separator"`
+
+	// Write a header row ahead of the first CSV record.
+	CSVHeader bool `toml:"csv_header"`
+
+	// Prefix header columns with "tag_" and "field_".
+	CSVColumnPrefix bool `toml:"csv_column_prefix"`
+
+	// URL of the schema registry for Avro output
+	AvroSchemaRegistry string `toml:"avro_schema_registry"`
+
+	// Avro schema, inline or from a file, used to encode metrics
+	AvroSchema     string `toml:"avro_schema"`
+	AvroSchemaFile string `toml:"avro_schema_file"`
+
+	// Registry subject to register the schema under, or to fetch the latest
+	// schema from when no schema is given
+	AvroSubject string `toml:"avro_subject"`
+
+	// Schema ID written to messages when no registry is used
+	AvroSchemaID int `toml:"avro_schema_id"`
+
+	// Record fields holding the metric name and time
+	AvroMeasurementField string `toml:"avro_measurement_field"`
+	AvroTimestamp        string `toml:"avro_timestamp"`
+	AvroTimestampFormat  string `toml:"avro_timestamp_format"`
 }
 
 // NewSerializer a Serializer interface based on the given config.
@@ -109,7 +170,7 @@ func NewSerializer(config *Config) (Serializer, error) {
 	case "graphite":
 		serializer, err = NewGraphiteSerializer(config.Prefix, config.Template, config.GraphiteTagSupport, config.Templates)
 	case "json":
-		serializer, err = NewJsonSerializer(config.TimestampUnits)
+		serializer, err = NewJSONSerializerConfig(config)
 	case "splunkmetric":
 		serializer, err = NewSplunkmetricSerializer(config.HecRouting, config.SplunkmetricMultiMetric)
 	case "nowmetric":
@@ -120,6 +181,14 @@ func NewSerializer(config *Config) (Serializer, error) {
 		serializer, err = NewWavefrontSerializer(config.Prefix, config.WavefrontUseStrict, config.WavefrontSourceOverride)
 	case "prometheus":
 		serializer, err = NewPrometheusSerializer(config)
+	case "msgpack":
+		serializer, err = NewMsgpackSerializer()
+	case "csv":
+		serializer, err = NewCSVSerializer(config)
+	case "template":
+		serializer, err = NewTemplateSerializer(config.Template, config.BatchTemplate)
+	case "avro":
+		serializer, err = NewAvroSerializer(config)
 	default:
 		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
 	}
@@ -149,6 +218,52 @@ func NewPrometheusSerializer(config *Config) (Serializer, error) {
 	})
 }
 
+func NewMsgpackSerializer() (Serializer, error) {
+	return msgpack.NewSerializer()
+}
+
+func NewCSVSerializer(config *Config) (Serializer, error) {
+	return csv.NewSerializer(config.CSVTimestampFormat, config.CSVSeparator, config.CSVHeader, config.CSVColumnPrefix)
+}
+
+func NewTemplateSerializer(metricTemplate, batchTemplate string) (Serializer, error) {
+	return template.NewSerializer(metricTemplate, batchTemplate)
+}
+
+func NewAvroSerializer(config *Config) (Serializer, error) {
+	definition := config.AvroSchema
+	if config.AvroSchemaFile != "" {
+		octets, err := ioutil.ReadFile(config.AvroSchemaFile)
+		if err != nil {
+			return nil, err
+		}
+		definition = string(octets)
+	}
+
+	var schema *commonavro.Schema
+	if definition != "" {
+		var err error
+		schema, err = commonavro.NewSchema(config.AvroSchemaID, definition)
+		if err != nil {
+			return nil, fmt.Errorf("parsing avro schema: %v", err)
+		}
+	}
+
+	var registry *commonavro.SchemaRegistry
+	if config.AvroSchemaRegistry != "" {
+		registry = commonavro.NewSchemaRegistry(config.AvroSchemaRegistry, 10*time.Second)
+	}
+
+	return avro.NewSerializer(
+		schema,
+		registry,
+		config.AvroSubject,
+		config.AvroMeasurementField,
+		config.AvroTimestamp,
+		config.AvroTimestampFormat,
+	)
+}
+
 func NewWavefrontSerializer(prefix string, useStrict bool, sourceOverride []string) (Serializer, error) {
 	return wavefront.NewSerializer(prefix, useStrict, sourceOverride)
 }
@@ -157,6 +272,21 @@ func NewJsonSerializer(timestampUnits time.Duration) (Serializer, error) {
 	return json.NewSerializer(timestampUnits)
 }
 
+func NewJSONSerializerConfig(config *Config) (Serializer, error) {
+	return json.NewSerializerConfig(json.FormatConfig{
+		TimestampUnits:  config.TimestampUnits,
+		TimestampFormat: config.JSONTimestampFormat,
+		NameKey:         config.JSONNameKey,
+		TimestampKey:    config.JSONTimestampKey,
+		TagsKey:         config.JSONTagsKey,
+		FieldsKey:       config.JSONFieldsKey,
+		FlattenTags:     config.JSONFlattenTags,
+		FlattenFields:   config.JSONFlattenFields,
+		BatchFormat:     json.BatchFormat(config.JSONBatchFormat),
+		BatchKey:        config.JSONBatchKey,
+	})
+}
+
 func NewCarbon2Serializer() (Serializer, error) {
 	return carbon2.NewSerializer()
 }
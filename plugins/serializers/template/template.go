@@ -0,0 +1,114 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	common "github.com/influxdata/telegraf/plugins/common/template"
+)
+
+type serializer struct {
+	tmpl      *template.Template
+	batchTmpl *template.Template
+}
+
+// NewSerializer creates a serializer rendering each metric through the given
+// Go template.  If batchTemplate is set, SerializeBatch renders the whole batch
+// through it instead, with the dot set to the slice of metrics.
+func NewSerializer(metricTemplate, batchTemplate string) (*serializer, error) {
+	if metricTemplate == "" && batchTemplate == "" {
+		return nil, fmt.Errorf("template or batch_template must be set")
+	}
+
+	s := &serializer{}
+
+	var err error
+	if metricTemplate != "" {
+		s.tmpl, err = template.New("template").Funcs(funcMap).Parse(metricTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("parsing template: %v", err)
+		}
+	}
+
+	if batchTemplate != "" {
+		s.batchTmpl, err = template.New("batch_template").Funcs(funcMap).Parse(batchTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("parsing batch_template: %v", err)
+		}
+	}
+	return s, nil
+}
+
+func (s *serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	if s.tmpl == nil {
+		return s.SerializeBatch([]telegraf.Metric{metric})
+	}
+
+	var b bytes.Buffer
+	if err := s.tmpl.Execute(&b, common.NewTemplateMetric(metric)); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (s *serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var b bytes.Buffer
+	if s.batchTmpl == nil {
+		for _, metric := range metrics {
+			if err := s.tmpl.Execute(&b, common.NewTemplateMetric(metric)); err != nil {
+				return nil, err
+			}
+		}
+		return b.Bytes(), nil
+	}
+
+	batch := make([]*common.TemplateMetric, 0, len(metrics))
+	for _, metric := range metrics {
+		batch = append(batch, common.NewTemplateMetric(metric))
+	}
+	if err := s.batchTmpl.Execute(&b, batch); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+var funcMap = template.FuncMap{
+	"json":       toJSON,
+	"unix":       unix,
+	"formatTime": formatTime,
+}
+
+// toJSON encodes v as a JSON value, strings are quoted and escaped.
+func toJSON(v interface{}) (string, error) {
+	octets, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(octets), nil
+}
+
+// unix returns the timestamp as an integer count of the given unit; one of
+// "s", "ms", "us" or "ns".
+func unix(unit string, t time.Time) (int64, error) {
+	switch unit {
+	case "s":
+		return t.Unix(), nil
+	case "ms":
+		return t.UnixNano() / int64(time.Millisecond), nil
+	case "us":
+		return t.UnixNano() / int64(time.Microsecond), nil
+	case "ns":
+		return t.UnixNano(), nil
+	default:
+		return 0, fmt.Errorf("unknown unit %q", unit)
+	}
+}
+
+// formatTime formats the timestamp in UTC using a Go reference time layout.
+func formatTime(layout string, t time.Time) string {
+	return t.UTC().Format(layout)
+}
@@ -0,0 +1,123 @@
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialize(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "name tag and field",
+			template: `{{ .Name }} {{ .Tag "host" }} {{ .Field "value" }}` + "\n",
+			expected: "cpu local\"host 42\n",
+		},
+		{
+			name:     "json escaping",
+			template: `{"host":{{ json (.Tag "host") }},"fields":{{ json .Fields }}}`,
+			expected: `{"host":"local\"host","fields":{"state":"ok","value":42}}`,
+		},
+		{
+			name:     "range over tags",
+			template: `{{ range $k, $v := .Tags }}{{ $k }}={{ $v }};{{ end }}`,
+			expected: `env=prod;host=local"host;`,
+		},
+		{
+			name:     "unix milliseconds",
+			template: `{{ unix "ms" .Time }}`,
+			expected: "1525478795123",
+		},
+		{
+			name:     "unix seconds",
+			template: `{{ .Time | unix "s" }}`,
+			expected: "1525478795",
+		},
+		{
+			name:     "time layout",
+			template: `{{ .Time | formatTime "2006-01-02T15:04:05.000Z07:00" }}`,
+			expected: "2018-05-05T00:06:35.123Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testutil.MustMetric(
+				"cpu",
+				map[string]string{
+					"host": `local"host`,
+					"env":  "prod",
+				},
+				map[string]interface{}{
+					"value": int64(42),
+					"state": "ok",
+				},
+				time.Unix(1525478795, 123456789),
+			)
+
+			s, err := NewSerializer(tt.template, "")
+			require.NoError(t, err)
+
+			buf, err := s.Serialize(m)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(buf))
+		})
+	}
+}
+
+func TestSerializeBatch(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{
+				"value": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"mem",
+			map[string]string{},
+			map[string]interface{}{
+				"value": int64(2),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	s, err := NewSerializer(`{{ .Name }}={{ .Field "value" }}`+"\n", "")
+	require.NoError(t, err)
+	buf, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+	require.Equal(t, "cpu=1\nmem=2\n", string(buf))
+
+	s, err = NewSerializer("", `[{{ range $i, $m := . }}{{ if $i }},{{ end }}{{ json $m.Name }}{{ end }}]`)
+	require.NoError(t, err)
+	buf, err = s.SerializeBatch(metrics)
+	require.NoError(t, err)
+	require.Equal(t, `["cpu","mem"]`, string(buf))
+
+	// Without a metric template single metrics go through the batch template.
+	buf, err = s.Serialize(metrics[0])
+	require.NoError(t, err)
+	require.Equal(t, `["cpu"]`, string(buf))
+}
+
+func TestInvalidTemplate(t *testing.T) {
+	_, err := NewSerializer("", "")
+	require.Error(t, err)
+
+	_, err = NewSerializer("{{ .Name ", "")
+	require.Error(t, err)
+
+	s, err := NewSerializer(`{{ unix "h" .Time }}`, "")
+	require.NoError(t, err)
+	_, err = s.Serialize(testutil.TestMetric(1.0))
+	require.Error(t, err)
+}
@@ -0,0 +1,18 @@
+package telegraf
+
+// StatefulPlugin is an interface that plugins can optionally implement to
+// persist an internal state, such as file offsets or cursors, across
+// restarts of Telegraf.  The state is only persisted when the agent has a
+// statefile configured.
+type StatefulPlugin interface {
+	// GetState returns the current state of the plugin.  The state must be
+	// serializable to JSON.  GetState is called periodically while the plugin
+	// is running and once after it is stopped, so it must be safe to call
+	// concurrently with the other functions of the plugin.
+	GetState() interface{}
+
+	// SetState restores the state saved by a previous run.  It is called
+	// after Init and before the plugin is started, with a value of the same
+	// type as returned by GetState.
+	SetState(state interface{}) error
+}